@@ -0,0 +1,217 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+	"tasktracker/internal/app"
+	"tasktracker/internal/config"
+	"tasktracker/internal/domain/task"
+	"tasktracker/internal/logging"
+)
+
+// commandUsage — справка по командам обслуживания БД
+const commandUsage = `Использование:
+  server backup [флаги] [файл]   копия БД в файл (файл.gz — сжатая) или, без файла, в backup.dir
+  server restore [флаги] файл    замена файла БД копией; сервер должен быть остановлен
+  server export [флаги] [файл]   выгрузка задач и списков в JSON (без файла — в stdout)
+  server import [флаги] файл [-mode merge|replace|append] [-dry-run]
+                                 загрузка выгрузки JSON (файл "-" — stdin); -dry-run только
+                                 проверяет данные и показывает, что изменится
+  server import [флаги] файл -from todoist|taskwarrior|trello [-dry-run]
+                                 добавление задач из файла другого планировщика
+
+Флаги те же, что у сервера (-db, -config и т. д.)`
+
+// commands — команды обслуживания, которые main выполняет вместо запуска сервера
+var commands = map[string]bool{
+	"backup":  true,
+	"restore": true,
+	"export":  true,
+	"import":  true,
+}
+
+// runCommand выполняет команду обслуживания БД и возвращает код завершения процесса
+func runCommand(name string, args []string) int {
+	cfg, rest, err := config.Parse(args)
+	if errors.Is(err, flag.ErrHelp) {
+		fmt.Fprintln(os.Stderr, commandUsage)
+		return 0
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Ошибка загрузки настроек: %v\n", err)
+		return 2
+	}
+
+	// Флаги импорта идут после файла: флаги до него разбирает config.Parse
+	var (
+		opts   task.ImportOptions
+		source string
+	)
+	if name == "import" && len(rest) > 0 {
+		fs := flag.NewFlagSet("import", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		mode := fs.String("mode", string(task.ImportMerge), "")
+		fs.BoolVar(&opts.DryRun, "dry-run", false, "")
+		fs.StringVar(&source, "from", "", "")
+		if err := fs.Parse(rest[1:]); err != nil {
+			fmt.Fprintln(os.Stderr, commandUsage)
+			return 2
+		}
+		// Задачи других планировщиков всегда добавляются как новые: режим к ним не относится
+		modeSet := false
+		fs.Visit(func(f *flag.Flag) { modeSet = modeSet || f.Name == "mode" })
+		if source != "" && modeSet {
+			fmt.Fprintln(os.Stderr, commandUsage)
+			return 2
+		}
+		opts.Mode = task.ImportMode(*mode)
+		rest = append(rest[:1], fs.Args()...)
+	}
+
+	needsFile := name == "restore" || name == "import"
+	if len(rest) > 1 || needsFile && len(rest) == 0 {
+		fmt.Fprintln(os.Stderr, commandUsage)
+		return 2
+	}
+	var file string
+	if len(rest) == 1 {
+		file = rest[0]
+	}
+
+	logger := logging.New(os.Stderr, cfg.LogLevel, cfg.LogFormat)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	switch name {
+	case "backup":
+		path, err := app.Backup(ctx, cfg, logger, file)
+		if err != nil {
+			logger.Error("backup failed", "error", err)
+			return 1
+		}
+		logger.Info("backup created", "path", path)
+	case "restore":
+		if err := app.Restore(cfg, logger, file); err != nil {
+			logger.Error("restore failed", "error", err)
+			return 1
+		}
+	case "export":
+		if err := exportTo(ctx, cfg, logger, file); err != nil {
+			logger.Error("export failed", "error", err)
+			return 1
+		}
+	case "import":
+		if source != "" {
+			return importSource(ctx, cfg, logger, source, file, opts.DryRun)
+		}
+		return importFrom(ctx, cfg, logger, file, opts)
+	}
+	return 0
+}
+
+// exportTo записывает выгрузку в новый файл file или, если он не задан, в stdout
+func exportTo(ctx context.Context, cfg *config.Config, logger *slog.Logger, file string) error {
+	if file == "" {
+		return app.Export(ctx, cfg, logger, os.Stdout)
+	}
+	f, err := os.OpenFile(file, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return fmt.Errorf("не удалось создать файл выгрузки: %w", err)
+	}
+	err = app.Export(ctx, cfg, logger, f)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(file)
+		return err
+	}
+	logger.Info("data exported", "path", file)
+	return nil
+}
+
+// openInput открывает файл импорта; "-" — stdin
+func openInput(file string) (io.ReadCloser, error) {
+	if file == "-" {
+		return io.NopCloser(os.Stdin), nil
+	}
+	return os.Open(file)
+}
+
+// importFrom загружает выгрузку из file ("-" — stdin), пишет итог в лог и возвращает код завершения
+func importFrom(ctx context.Context, cfg *config.Config, logger *slog.Logger, file string, opts task.ImportOptions) int {
+	r, err := openInput(file)
+	if err != nil {
+		logger.Error("import failed", "error", err)
+		return 1
+	}
+	defer r.Close()
+
+	summary, err := app.Import(ctx, cfg, logger, r, opts)
+	if err != nil {
+		logger.Error("import failed", "error", err)
+		return 1
+	}
+	for _, e := range summary.Errors {
+		logger.Error("invalid import item", "item", e.Item, "index", e.Index, "id", e.ID, "error", e.Err)
+	}
+	logger.Info("import finished",
+		"mode", summary.Mode,
+		"dry_run", summary.DryRun,
+		"applied", summary.Applied,
+		"tasks_created", summary.Tasks.Created,
+		"tasks_updated", summary.Tasks.Updated,
+		"tasks_skipped", summary.Tasks.Skipped,
+		"tasks_deleted", summary.Tasks.Deleted,
+		"lists_created", summary.Lists.Created,
+		"lists_updated", summary.Lists.Updated,
+		"lists_skipped", summary.Lists.Skipped,
+		"lists_deleted", summary.Lists.Deleted,
+	)
+	if len(summary.Errors) > 0 {
+		return 1
+	}
+	return 0
+}
+
+// importSource добавляет задачи из файла другого планировщика, пишет в лог предупреждения
+// и итог и возвращает код завершения
+func importSource(ctx context.Context, cfg *config.Config, logger *slog.Logger, source, file string, dryRun bool) int {
+	r, err := openInput(file)
+	if err != nil {
+		logger.Error("import failed", "error", err)
+		return 1
+	}
+	defer r.Close()
+
+	report, err := app.ImportFrom(ctx, cfg, logger, source, r, dryRun)
+	if err != nil {
+		logger.Error("import failed", "error", err)
+		return 1
+	}
+	for _, w := range report.Warnings {
+		logger.Warn("import item not fully mapped", "item", w.Item, "title", w.Title, "error", w.Err)
+	}
+	for _, e := range report.Summary.Errors {
+		logger.Error("invalid import item", "item", report.Items[e.Index], "error", e.Err)
+	}
+	logger.Info("import finished",
+		"source", report.Source,
+		"dry_run", dryRun,
+		"applied", report.Summary.Applied,
+		"tasks_created", report.Summary.Tasks.Created,
+		"skipped", report.Skipped,
+		"warnings", len(report.Warnings),
+	)
+	if len(report.Summary.Errors) > 0 {
+		return 1
+	}
+	return 0
+}
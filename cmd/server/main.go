@@ -1,19 +1,52 @@
 package main
 
 import (
-	"log"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
 	"tasktracker/internal/app"
+	"tasktracker/internal/config"
+	"tasktracker/internal/logging"
 )
 
 func main() {
+	// Команды обслуживания БД выполняются вместо запуска сервера
+	if len(os.Args) > 1 && commands[os.Args[1]] {
+		os.Exit(runCommand(os.Args[1], os.Args[2:]))
+	}
+
+	// Загружаем настройки
+	cfg, err := config.Load(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Ошибка загрузки настроек: %v\n", err)
+		os.Exit(2)
+	}
+
+	logger := logging.New(os.Stderr, cfg.LogLevel, cfg.LogFormat)
+	slog.SetDefault(logger)
+
 	// Создаем приложение
-	application, err := app.New()
+	application, err := app.New(cfg, logger)
 	if err != nil {
-		log.Fatalf("Ошибка инициализации приложения: %v", err)
+		logger.Error("application init failed", "error", err)
+		os.Exit(1)
 	}
 
+	// SIGINT и SIGTERM запускают корректную остановку
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	// Запускаем сервер
-	if err := application.Start(); err != nil {
-		log.Fatal(err)
+	if err := application.Run(ctx); err != nil {
+		logger.Error("application stopped with error", "error", err)
+		os.Exit(1)
 	}
 }
@@ -1,66 +1,355 @@
 package app
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io/fs"
+	"log/slog"
+	"net"
 	"net/http"
 	"os"
-	"strconv"
 	"strings"
+	"sync"
+	"tasktracker"
+	"tasktracker/internal/backup"
+	"tasktracker/internal/config"
 	"tasktracker/internal/domain/task"
+	"tasktracker/internal/metrics"
+	"tasktracker/internal/storage/memory"
+	"tasktracker/internal/storage/postgres"
 	"tasktracker/internal/storage/sqlite"
+	"tasktracker/internal/tracing"
 	"tasktracker/internal/transport"
-	"tasktracker/tests" // Используем напрямую настройки из tests
+	"time"
 )
 
 // App представляет собой основное приложение
 type App struct {
+	cfg     *config.Config
+	logger  *slog.Logger
+	metrics *metrics.Metrics
+	tracing *tracing.Provider
 	server  *http.Server
-	db      *sqlite.DB
+	db      database
 	service *task.Service
 	handler *transport.Handler
+	auth    *transport.Auth
+	health  *transport.Health
+	backup  *backup.Manager // nil, если хранилище не поддерживает резервное копирование
+
+	mu       sync.Mutex // Защищает listener, который читается из других горутин через Addr
+	listener net.Listener
+	serveErr chan error
+
+	// Фоновые задачи останавливаются отменой workersCtx при остановке приложения
+	workersCtx  context.Context
+	stopWorkers context.CancelFunc
+	workers     sync.WaitGroup
+	workersMu   sync.Mutex
+	exited      []string // Фоновые задачи, завершившиеся раньше остановки приложения
+	stopOnce    sync.Once
+	stopErr     error
 }
 
-// New создает новый экземпляр приложения
-func New() (*App, error) {
-	dbFile := tests.DBFile
-	if envFile := os.Getenv("TODO_DBFILE"); strings.TrimSpace(envFile) != "" {
-		dbFile = envFile
+// database — общие для хранилищ SQLite и PostgreSQL операции с подключением
+type database interface {
+	SchemaVersion() (int, error)
+	Ready(ctx context.Context) error
+	Ping() error
+	Close() error
+}
+
+// ephemeralDatabase заменяет подключение к БД в режиме ephemeral: задачи хранятся в памяти процесса
+type ephemeralDatabase struct{}
+
+func (ephemeralDatabase) SchemaVersion() (int, error)   { return 0, nil }
+func (ephemeralDatabase) Ready(_ context.Context) error { return nil }
+func (ephemeralDatabase) Ping() error                   { return nil }
+func (ephemeralDatabase) Close() error                  { return nil }
+
+// openDatabase выбирает хранилище: память в режиме ephemeral, PostgreSQL, если задан db_dsn,
+// иначе файл SQLite
+func openDatabase(cfg *config.Config, logger *slog.Logger) (database, task.Repository, error) {
+	if cfg.Ephemeral {
+		logger.Warn("ephemeral mode: tasks are kept in memory and lost on exit")
+		return ephemeralDatabase{}, memory.NewRepository(), nil
 	}
-	database, err := sqlite.New(dbFile)
+	if cfg.DBDSN != "" {
+		db, err := postgres.New(cfg.DBDSN, logger)
+		if err != nil {
+			return nil, nil, err
+		}
+		return db, postgres.NewRepository(db), nil
+	}
+	db, err := sqlite.New(cfg.DBFile, sqliteOptions(cfg), logger)
+	if err != nil {
+		return nil, nil, err
+	}
+	return db, sqlite.NewRepository(db), nil
+}
+
+func sqliteOptions(cfg *config.Config) sqlite.Options {
+	return sqlite.Options{
+		JournalMode:  cfg.SQLite.JournalMode,
+		BusyTimeout:  cfg.SQLite.BusyTimeout,
+		ForeignKeys:  cfg.SQLite.ForeignKeys,
+		Synchronous:  cfg.SQLite.Synchronous,
+		MaxOpenConns: cfg.SQLite.MaxOpenConns,
+		MaxIdleConns: cfg.SQLite.MaxIdleConns,
+	}
+}
+
+// New создает новый экземпляр приложения
+func New(cfg *config.Config, logger *slog.Logger) (*App, error) {
+	db, storage, err := openDatabase(cfg, logger)
 	if err != nil {
 		return nil, fmt.Errorf("ошибка инициализации БД: %w", err)
 	}
-	repository := sqlite.NewRepository(database)
-	service := task.NewService(repository)
-	handler := transport.NewHandler(service)
+	tp, err := tracing.New(context.Background(), cfg.Tracing.Exporter, cfg.Tracing.Endpoint, os.Stdout)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("ошибка инициализации трассировки: %w", err)
+	}
+	m := metrics.New()
+	repository := m.InstrumentRepository(storage)
+	service := task.NewService(repository,
+		task.WithObserver(m),
+		task.WithAuditContext(transport.AuditContext),
+		task.WithOwnerContext(transport.UserFromContext))
+	m.RegisterTaskStats(func() (*task.Stats, error) {
+		return service.GetStats(context.Background(), time.Now())
+	}, logger)
+	static, err := newStatic(cfg.WebDir)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	auth := transport.NewAuth(cfg.AuthSecret)
+	var handlerOpts []transport.HandlerOption
+	var backups *backup.Manager
+	if source, ok := db.(backup.Source); ok {
+		backups = backup.NewManager(source, backup.Options{
+			Dir:      cfg.Backup.Dir,
+			Keep:     cfg.Backup.Keep,
+			Compress: cfg.Backup.Gzip,
+		}, logger)
+		handlerOpts = append(handlerOpts, transport.WithBackup(backups))
+	}
+	handler := transport.NewHandler(service, static, auth, logger, handlerOpts...)
 
-	return &App{
-		db:      database,
-		service: service,
-		handler: handler,
-	}, nil
+	workersCtx, stopWorkers := context.WithCancel(context.Background())
+
+	a := &App{
+		cfg:         cfg,
+		logger:      logger,
+		metrics:     m,
+		tracing:     tp,
+		db:          db,
+		service:     service,
+		handler:     handler,
+		auth:        auth,
+		backup:      backups,
+		serveErr:    make(chan error, 1),
+		workersCtx:  workersCtx,
+		stopWorkers: stopWorkers,
+	}
+	a.health = transport.NewHealth(db.SchemaVersion,
+		transport.HealthCheck{Name: "database", Check: db.Ready},
+		transport.HealthCheck{Name: "workers", Check: a.checkWorkers},
+	)
+	return a, nil
 }
 
-// Start запускает веб-сервер и начинает обработку запросов
-func (a *App) Start() error {
-	// Получаем порт из конфигурации или переменной окружения
-	port := tests.Port
-	if portStr := os.Getenv("TODO_PORT"); portStr != "" {
-		if eport, err := strconv.ParseInt(portStr, 10, 32); err == nil {
-			port = int(eport)
+// newStatic раздает встроенный фронтенд или, если задан webDir, файлы с диска без кэширования
+func newStatic(webDir string) (*transport.Static, error) {
+	if webDir != "" {
+		if info, err := os.Stat(webDir); err != nil || !info.IsDir() {
+			return nil, fmt.Errorf("каталог фронтенда %q не найден", webDir)
 		}
+		return transport.NewStatic(os.DirFS(webDir), false), nil
 	}
+	web, err := fs.Sub(tasktracker.Web, "web")
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения встроенного фронтенда: %w", err)
+	}
+	return transport.NewStatic(web, true), nil
+}
 
-	// Регистрируем маршруты
-	a.handler.RegisterRoutes()
-
+// Start открывает порт и начинает обработку запросов в фоне.
+// Ошибка занятого порта возвращается сразу; остановка выполняется через Stop.
+func (a *App) Start() error {
 	// Создаем HTTP-сервер с нужными настройками
 	a.server = &http.Server{
-		Addr: fmt.Sprintf(":%d", port),
+		Addr:         a.cfg.Addr,
+		Handler:      a.routes(),
+		ErrorLog:     slog.NewLogLogger(a.logger.Handler(), slog.LevelWarn),
+		ReadTimeout:  a.cfg.Timeouts.Read,
+		WriteTimeout: a.cfg.Timeouts.Write,
+		IdleTimeout:  a.cfg.Timeouts.Idle,
 	}
 
-	fmt.Printf("Сервер запущен на http://localhost:%d\n", port)
+	listener, err := net.Listen("tcp", a.cfg.Addr)
+	if err != nil {
+		return fmt.Errorf("не удалось открыть порт: %w", err)
+	}
+	a.mu.Lock()
+	a.listener = listener
+	a.mu.Unlock()
 
-	// Запускаем сервер
-	return a.server.ListenAndServe()
+	a.logger.Info("server started", "addr", listener.Addr().String())
+
+	if a.backup != nil && a.cfg.Backup.Interval > 0 {
+		a.goWorker("backup", func(ctx context.Context) {
+			a.backup.Run(ctx, a.cfg.Backup.Interval, func(err error) {
+				a.metrics.WorkerRun("backup", err)
+			})
+		})
+	}
+
+	go func() {
+		if err := a.server.Serve(listener); !errors.Is(err, http.ErrServerClosed) {
+			a.serveErr <- err
+		}
+		close(a.serveErr)
+	}()
+
+	return nil
+}
+
+// routes собирает обработчик всех запросов. Служебные эндпоинты (/healthz, /readyz,
+// /version, /metrics) не проходят аутентификацию, CORS и ограничение размера тела;
+// остальные middleware применяются ко всем запросам.
+func (a *App) routes() http.Handler {
+	root := http.NewServeMux()
+	a.health.RegisterRoutes(root)
+	root.Handle("/metrics", a.metrics.Handler())
+	root.Handle("/", transport.Chain(a.handler.RegisterRoutes(),
+		transport.CORS(a.cfg.CORSOrigins),
+		transport.BodyLimit(a.cfg.MaxBodyBytes),
+		a.auth.Middleware,
+	))
+
+	return transport.Chain(root,
+		transport.RequestID,
+		transport.AccessLog(a.logger, transport.HealthPaths...),
+		transport.Tracing,
+		a.metrics.Middleware(transport.Route),
+		transport.Recovery(a.logger),
+	)
+}
+
+// Addr возвращает фактический адрес сервера (полезно при запуске на порту 0)
+func (a *App) Addr() string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.listener == nil {
+		return ""
+	}
+	return a.listener.Addr().String()
+}
+
+// Run запускает приложение и блокируется до отмены ctx (например, по SIGINT/SIGTERM)
+// или до ошибки сервера, после чего корректно останавливает приложение.
+func (a *App) Run(ctx context.Context) error {
+	if err := a.Start(); err != nil {
+		return errors.Join(err, a.Stop(context.Background()))
+	}
+
+	var runErr error
+	select {
+	case <-ctx.Done():
+		a.logger.Info("shutdown signal received, draining requests", "timeout", a.cfg.Timeouts.Shutdown)
+	case err := <-a.serveErr:
+		runErr = fmt.Errorf("ошибка HTTP-сервера: %w", err)
+	}
+
+	stopCtx, cancel := context.WithTimeout(context.Background(), a.cfg.Timeouts.Shutdown)
+	defer cancel()
+
+	if err := a.Stop(stopCtx); err != nil {
+		return errors.Join(runErr, err)
+	}
+	return runErr
+}
+
+// Stop останавливает приложение: перестает принимать соединения и дожидается
+// завершения текущих запросов (не дольше, чем позволяет ctx), останавливает фоновые
+// задачи и закрывает БД. Повторные вызовы возвращают результат первого.
+func (a *App) Stop(ctx context.Context) error {
+	a.stopOnce.Do(func() {
+		var errs []error
+
+		if a.server != nil {
+			if err := a.server.Shutdown(ctx); err != nil {
+				errs = append(errs, fmt.Errorf("не удалось дождаться завершения запросов: %w", err))
+				// Принудительно закрываем оставшиеся соединения
+				a.server.Close()
+			}
+		}
+
+		a.stopWorkers()
+		done := make(chan struct{})
+		go func() {
+			a.workers.Wait()
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-ctx.Done():
+			errs = append(errs, fmt.Errorf("фоновые задачи не остановились вовремя: %w", ctx.Err()))
+		}
+
+		if err := a.closeDB(); err != nil {
+			errs = append(errs, err)
+		}
+
+		// Последними выгружаем span'ы, включая span'ы завершившихся запросов
+		if err := a.tracing.Shutdown(ctx); err != nil {
+			errs = append(errs, err)
+		}
+
+		a.stopErr = errors.Join(errs...)
+		a.logger.Info("application stopped")
+	})
+
+	return a.stopErr
+}
+
+// goWorker запускает фоновую задачу, которая должна завершиться после отмены ctx.
+// Состояние задачи отражается в метрике worker_up.
+func (a *App) goWorker(name string, fn func(ctx context.Context)) {
+	a.workers.Add(1)
+	a.metrics.WorkerStarted(name)
+	go func() {
+		defer a.workers.Done()
+		defer a.metrics.WorkerStopped(name)
+		fn(a.workersCtx)
+
+		if a.workersCtx.Err() == nil {
+			a.logger.Error("background worker exited unexpectedly", "worker", name)
+			a.workersMu.Lock()
+			a.exited = append(a.exited, name)
+			a.workersMu.Unlock()
+		}
+	}()
+}
+
+// checkWorkers проверяет, что ни одна фоновая задача не завершилась раньше времени
+func (a *App) checkWorkers(context.Context) error {
+	a.workersMu.Lock()
+	defer a.workersMu.Unlock()
+
+	if len(a.exited) > 0 {
+		return fmt.Errorf("фоновые задачи остановлены: %s", strings.Join(a.exited, ", "))
+	}
+	return nil
+}
+
+func (a *App) closeDB() error {
+	if err := a.db.Close(); err != nil {
+		return fmt.Errorf("ошибка закрытия БД: %w", err)
+	}
+	return nil
 }
@@ -0,0 +1,191 @@
+package app
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"tasktracker/internal/backup"
+	"tasktracker/internal/config"
+	"tasktracker/internal/logging"
+)
+
+func testConfig(t *testing.T) *config.Config {
+	cfg := config.Default()
+	cfg.Addr = "127.0.0.1:0"
+	cfg.DBFile = filepath.Join(t.TempDir(), "scheduler.db")
+	cfg.Timeouts.Shutdown = 5 * time.Second
+	return cfg
+}
+
+func TestStartStop(t *testing.T) {
+	a, err := New(testConfig(t), logging.Discard())
+	require.NoError(t, err)
+	require.NoError(t, a.Start())
+
+	resp, err := http.Get("http://" + a.Addr() + "/api/nextdate?now=20240126&date=20240126&repeat=d%201")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	// Фоновая задача должна получить сигнал остановки
+	stopped := make(chan struct{})
+	a.goWorker("test", func(ctx context.Context) {
+		<-ctx.Done()
+		close(stopped)
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	require.NoError(t, a.Stop(ctx))
+	assert.NoError(t, a.Stop(ctx), "повторная остановка не должна возвращать ошибку")
+
+	select {
+	case <-stopped:
+	default:
+		t.Error("фоновая задача не остановлена")
+	}
+
+	_, err = http.Get("http://" + a.Addr() + "/")
+	assert.Error(t, err, "сервер должен перестать принимать соединения")
+	assert.Error(t, a.db.Ping(), "БД должна быть закрыта")
+}
+
+func TestRunStopsOnContextCancel(t *testing.T) {
+	a, err := New(testConfig(t), logging.Discard())
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- a.Run(ctx)
+	}()
+
+	require.Eventually(t, func() bool { return a.Addr() != "" }, 5*time.Second, 10*time.Millisecond)
+	cancel()
+
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(10 * time.Second):
+		t.Fatal("Run не завершился после отмены контекста")
+	}
+}
+
+func TestReadyzReportsExitedWorker(t *testing.T) {
+	a, err := New(testConfig(t), logging.Discard())
+	require.NoError(t, err)
+	require.NoError(t, a.Start())
+	t.Cleanup(func() { a.Stop(context.Background()) })
+
+	readyz := func() int {
+		resp, err := http.Get("http://" + a.Addr() + "/readyz")
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	assert.Equal(t, http.StatusOK, readyz())
+
+	// Фоновая задача, завершившаяся до остановки приложения, делает его неготовым
+	a.goWorker("broken", func(ctx context.Context) {})
+	require.Eventually(t, func() bool { return readyz() == http.StatusServiceUnavailable }, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestTwoInstances(t *testing.T) {
+	var addrs []string
+	for i := 0; i < 2; i++ {
+		a, err := New(testConfig(t), logging.Discard())
+		require.NoError(t, err)
+		require.NoError(t, a.Start())
+		t.Cleanup(func() { a.Stop(context.Background()) })
+		addrs = append(addrs, a.Addr())
+	}
+
+	for _, addr := range addrs {
+		resp, err := http.Get("http://" + addr + "/api/tasks")
+		require.NoError(t, err)
+		resp.Body.Close()
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	}
+}
+
+func TestEphemeral(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.Ephemeral = true
+	a, err := New(cfg, logging.Discard())
+	require.NoError(t, err)
+	require.NoError(t, a.Start())
+	t.Cleanup(func() { a.Stop(context.Background()) })
+
+	resp, err := http.Post("http://"+a.Addr()+"/api/task", "application/json",
+		strings.NewReader(`{"date":"20240101","title":"В памяти","repeat":"d 1"}`))
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp, err = http.Get("http://" + a.Addr() + "/readyz")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	resp, err = http.Get("http://" + a.Addr() + "/api/admin/backup")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusNotImplemented, resp.StatusCode, "копировать нечего")
+
+	assert.NoFileExists(t, cfg.DBFile, "в режиме ephemeral файл БД не создается")
+}
+
+func TestScheduledBackups(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.Backup.Dir = t.TempDir()
+	cfg.Backup.Interval = 20 * time.Millisecond
+	cfg.Backup.Keep = 2
+	a, err := New(cfg, logging.Discard())
+	require.NoError(t, err)
+	require.NoError(t, a.Start())
+	t.Cleanup(func() { a.Stop(context.Background()) })
+
+	require.Eventually(t, func() bool {
+		backups, err := backup.List(cfg.Backup.Dir)
+		return err == nil && len(backups) == 2
+	}, 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, a.Stop(context.Background()))
+
+	backups, err := backup.List(cfg.Backup.Dir)
+	require.NoError(t, err)
+	assert.Len(t, backups, 2, "лишние копии удаляются")
+}
+
+// Запуски фоновых задач видны в метриках worker_runs_total и worker_last_success_timestamp_seconds
+func TestBackupWorkerMetrics(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.Backup.Dir = t.TempDir()
+	cfg.Backup.Interval = 20 * time.Millisecond
+	a, err := New(cfg, logging.Discard())
+	require.NoError(t, err)
+	require.NoError(t, a.Start())
+	t.Cleanup(func() { a.Stop(context.Background()) })
+
+	metrics := func() string {
+		resp, err := http.Get("http://" + a.Addr() + "/metrics")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return string(body)
+	}
+	require.Eventually(t, func() bool {
+		body := metrics()
+		return strings.Contains(body, `tasktracker_worker_runs_total{result="ok",worker="backup"}`) &&
+			strings.Contains(body, `tasktracker_worker_last_success_timestamp_seconds{worker="backup"}`)
+	}, 5*time.Second, 20*time.Millisecond)
+}
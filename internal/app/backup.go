@@ -0,0 +1,86 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"tasktracker/internal/backup"
+	"tasktracker/internal/config"
+	"tasktracker/internal/storage/sqlite"
+)
+
+// errBackupUnsupported — резервное копирование работает только с файлом SQLite
+var errBackupUnsupported = errors.New("резервное копирование поддерживается только для SQLite; для PostgreSQL используйте pg_dump")
+
+// Backup создает копию БД из cfg без остановки сервера: в файл file (сжатую gzip,
+// если имя оканчивается на .gz) или, если file пуст, в каталоге backup.dir с ротацией.
+// Возвращает путь к копии.
+func Backup(ctx context.Context, cfg *config.Config, logger *slog.Logger, file string) (string, error) {
+	if cfg.DBDSN != "" || cfg.Ephemeral {
+		return "", errBackupUnsupported
+	}
+	dbFile, err := sqlite.ResolvePath(cfg.DBFile)
+	if err != nil {
+		return "", err
+	}
+	// New создал бы пустую БД, а копия пустой БД вместо настоящей хуже ошибки
+	if _, err := os.Stat(dbFile); err != nil {
+		return "", fmt.Errorf("файл БД не найден: %w", err)
+	}
+	db, err := sqlite.New(dbFile, sqliteOptions(cfg), logger)
+	if err != nil {
+		return "", err
+	}
+	defer db.Close()
+
+	manager := backup.NewManager(db, backup.Options{
+		Dir:      cfg.Backup.Dir,
+		Keep:     cfg.Backup.Keep,
+		Compress: cfg.Backup.Gzip,
+	}, logger)
+	if file == "" {
+		return manager.Create(ctx)
+	}
+
+	f, err := os.OpenFile(file, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return "", fmt.Errorf("не удалось создать файл копии: %w", err)
+	}
+	err = manager.Write(ctx, f, strings.HasSuffix(file, ".gz"))
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(file)
+		return "", err
+	}
+	return file, nil
+}
+
+// Restore заменяет файл БД из cfg копией file после проверки ее целостности и версии схемы.
+// Сервер должен быть остановлен: открытые им соединения продолжили бы работать с прежним файлом.
+func Restore(cfg *config.Config, logger *slog.Logger, file string) error {
+	if cfg.DBDSN != "" || cfg.Ephemeral {
+		return errBackupUnsupported
+	}
+	dbFile, err := sqlite.ResolvePath(cfg.DBFile)
+	if err != nil {
+		return err
+	}
+	err = backup.Restore(file, dbFile, func(path string) error {
+		version, err := sqlite.CheckSnapshot(path)
+		if err != nil {
+			return err
+		}
+		logger.Info("backup verified", "schema_version", version, "latest_schema_version", sqlite.LatestSchemaVersion())
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	logger.Info("database restored", "path", dbFile, "backup", file)
+	return nil
+}
@@ -0,0 +1,99 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"tasktracker/internal/config"
+	"tasktracker/internal/domain/task"
+	"tasktracker/internal/importer"
+	"tasktracker/internal/storage/sqlite"
+	"time"
+)
+
+// errTransferEphemeral — в режиме ephemeral БД нет: выгружать нечего, а загруженное пропадет
+var errTransferEphemeral = errors.New("экспорт и импорт недоступны в режиме ephemeral")
+
+// Export записывает в w выгрузку задач и сохраненных списков из БД cfg (см. task.Archive)
+func Export(ctx context.Context, cfg *config.Config, logger *slog.Logger, w io.Writer) error {
+	if cfg.Ephemeral {
+		return errTransferEphemeral
+	}
+	if cfg.DBDSN == "" {
+		// Как и для копии: пустая выгрузка из только что созданной БД хуже ошибки
+		dbFile, err := sqlite.ResolvePath(cfg.DBFile)
+		if err != nil {
+			return err
+		}
+		if _, err := os.Stat(dbFile); err != nil {
+			return fmt.Errorf("файл БД не найден: %w", err)
+		}
+	}
+
+	db, repo, err := openDatabase(cfg, logger)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	archive, err := task.NewService(repo).Export(ctx, time.Now())
+	if err != nil {
+		return err
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(archive)
+}
+
+// Import загружает выгрузку из r в БД cfg. Ошибки в отдельных записях возвращаются
+// в итоге (ImportSummary.Errors), а не ошибкой: тогда ничего не сохранено.
+func Import(ctx context.Context, cfg *config.Config, logger *slog.Logger, r io.Reader, opts task.ImportOptions) (*task.ImportSummary, error) {
+	if cfg.Ephemeral {
+		return nil, errTransferEphemeral
+	}
+
+	var archive task.Archive
+	if err := json.NewDecoder(r).Decode(&archive); err != nil {
+		return nil, fmt.Errorf("некорректный файл выгрузки: %w", err)
+	}
+
+	db, repo, err := openDatabase(cfg, logger)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	return cliService(repo).Import(ctx, &archive, opts)
+}
+
+// ImportFrom добавляет в БД cfg задачи из файла другого планировщика source (см. importer.Run)
+func ImportFrom(ctx context.Context, cfg *config.Config, logger *slog.Logger, source string, r io.Reader, dryRun bool) (*importer.Report, error) {
+	if cfg.Ephemeral {
+		return nil, errTransferEphemeral
+	}
+	if _, err := importer.Lookup(source); err != nil {
+		return nil, err
+	}
+
+	db, repo, err := openDatabase(cfg, logger)
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+
+	return importer.Run(ctx, cliService(repo), source, r, time.Now(), dryRun)
+}
+
+// ActorCLI — автор изменений, внесенных командами сервера из командной строки
+const ActorCLI = "cli"
+
+// cliService создает сервис для команд командной строки: изменения попадают в журнал от имени cli
+func cliService(repo task.Repository) *task.Service {
+	return task.NewService(repo, task.WithAuditContext(func(context.Context) (string, string) {
+		return ActorCLI, ""
+	}))
+}
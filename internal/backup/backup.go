@@ -0,0 +1,209 @@
+// Package backup создает резервные копии БД на работающем сервере и восстанавливает их.
+//
+// Копию делает хранилище (Source), пакет отвечает за сжатие, имена файлов, хранение
+// не более заданного числа копий и периодический запуск. Копии пишутся во временный
+// файл и переименовываются только целиком, поэтому в каталоге копий не бывает
+// недописанных файлов.
+package backup
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// filePrefix и расширения отличают копии от других файлов в каталоге: ротация удаляет только их
+	filePrefix = "backup-"
+	fileExt    = ".db"
+	gzipExt    = ".gz"
+
+	// timeFormat — время создания копии в имени файла; имена сортируются в хронологическом порядке
+	timeFormat = "20060102T150405.000Z"
+)
+
+// ErrNoDir возвращается при создании копии в каталоге, если каталог копий не задан
+var ErrNoDir = errors.New("каталог резервных копий не задан")
+
+// Source — хранилище, умеющее записать согласованную копию БД в новый файл
+type Source interface {
+	Snapshot(ctx context.Context, path string) error
+}
+
+// Options задает, где хранить копии и сколько
+type Options struct {
+	// Dir — каталог копий; без него доступна только выгрузка копии через Write
+	Dir string
+	// Keep — сколько последних копий хранить; 0 — хранить все
+	Keep int
+	// Compress сжимает копии в каталоге gzip
+	Compress bool
+}
+
+// Manager создает копии БД
+type Manager struct {
+	source Source
+	opts   Options
+	logger *slog.Logger
+	now    func() time.Time
+}
+
+func NewManager(source Source, opts Options, logger *slog.Logger) *Manager {
+	return &Manager{
+		source: source,
+		opts:   opts,
+		logger: logger,
+		now:    time.Now,
+	}
+}
+
+// Write записывает копию БД в w, сжимая ее gzip, если compress. Копия сначала целиком
+// создается во временном файле: если ее не удалось сделать, в w ничего не записано.
+func (m *Manager) Write(ctx context.Context, w io.Writer, compress bool) error {
+	tmpDir, err := os.MkdirTemp("", "tasktracker-backup-")
+	if err != nil {
+		return fmt.Errorf("не удалось создать временный каталог: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	snapshot := filepath.Join(tmpDir, "snapshot"+fileExt)
+	if err := m.source.Snapshot(ctx, snapshot); err != nil {
+		return err
+	}
+	return copyFile(w, snapshot, compress)
+}
+
+// Create создает копию в каталоге копий, удаляет лишние старые копии и возвращает путь к новой
+func (m *Manager) Create(ctx context.Context) (string, error) {
+	if m.opts.Dir == "" {
+		return "", ErrNoDir
+	}
+	if err := os.MkdirAll(m.opts.Dir, 0o755); err != nil {
+		return "", fmt.Errorf("не удалось создать каталог копий: %w", err)
+	}
+
+	name := filePrefix + m.now().UTC().Format(timeFormat) + fileExt
+	if m.opts.Compress {
+		name += gzipExt
+	}
+	path := filepath.Join(m.opts.Dir, name)
+
+	// Временное имя не похоже на копию, поэтому ротация его не тронет
+	tmp, err := os.CreateTemp(m.opts.Dir, ".partial-*")
+	if err != nil {
+		return "", fmt.Errorf("не удалось создать файл копии: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	err = m.Write(ctx, tmp, m.opts.Compress)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", fmt.Errorf("не удалось записать копию: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return "", fmt.Errorf("не удалось сохранить копию: %w", err)
+	}
+
+	if err := m.Rotate(); err != nil {
+		m.logger.Warn("backup rotation failed", "error", err)
+	}
+	return path, nil
+}
+
+// Rotate удаляет самые старые копии в каталоге, оставляя Keep последних
+func (m *Manager) Rotate() error {
+	if m.opts.Dir == "" || m.opts.Keep <= 0 {
+		return nil
+	}
+	backups, err := List(m.opts.Dir)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for len(backups) > m.opts.Keep {
+		if err := os.Remove(backups[0]); err != nil {
+			errs = append(errs, err)
+		} else {
+			m.logger.Info("old backup removed", "path", backups[0])
+		}
+		backups = backups[1:]
+	}
+	return errors.Join(errs...)
+}
+
+// Run создает копию каждые interval, пока не отменен ctx. Ошибки пишутся в лог:
+// неудачная копия не должна останавливать следующие. Итог каждого запуска передается
+// в report (например, для метрик фоновых задач), если он задан.
+func (m *Manager) Run(ctx context.Context, interval time.Duration, report func(error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		started := time.Now()
+		path, err := m.Create(ctx)
+		if report != nil {
+			report(err)
+		}
+		if err != nil {
+			m.logger.Error("scheduled backup failed", "error", err)
+			continue
+		}
+		m.logger.Info("scheduled backup created", "path", path, "duration", time.Since(started))
+	}
+}
+
+// List возвращает пути к копиям в каталоге dir от старых к новым
+func List(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось прочитать каталог копий: %w", err)
+	}
+	var backups []string
+	for _, e := range entries {
+		name := e.Name()
+		if e.Type().IsRegular() && strings.HasPrefix(name, filePrefix) &&
+			(strings.HasSuffix(name, fileExt) || strings.HasSuffix(name, fileExt+gzipExt)) {
+			backups = append(backups, filepath.Join(dir, name))
+		}
+	}
+	sort.Strings(backups)
+	return backups, nil
+}
+
+// copyFile копирует файл path в w, при необходимости сжимая
+func copyFile(w io.Writer, path string, compress bool) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("не удалось открыть копию: %w", err)
+	}
+	defer f.Close()
+
+	if !compress {
+		_, err = io.Copy(w, f)
+		return err
+	}
+	zw := gzip.NewWriter(w)
+	if _, err := io.Copy(zw, f); err != nil {
+		return err
+	}
+	return zw.Close()
+}
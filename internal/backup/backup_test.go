@@ -0,0 +1,159 @@
+package backup
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"tasktracker/internal/logging"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSource «копирует» БД, записывая в файл заданное содержимое
+type fakeSource struct {
+	data []byte
+	err  error
+}
+
+func (s *fakeSource) Snapshot(_ context.Context, path string) error {
+	if s.err != nil {
+		return s.err
+	}
+	if _, err := os.Stat(path); err == nil {
+		return errors.New("файл копии уже существует")
+	}
+	return os.WriteFile(path, s.data, 0o600)
+}
+
+func gunzip(t *testing.T, data []byte) []byte {
+	t.Helper()
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	require.NoError(t, err)
+	out, err := io.ReadAll(zr)
+	require.NoError(t, err)
+	return out
+}
+
+func TestWrite(t *testing.T) {
+	ctx := context.Background()
+	src := &fakeSource{data: []byte("SQLite format 3")}
+	m := NewManager(src, Options{}, logging.Discard())
+
+	var plain, compressed bytes.Buffer
+	require.NoError(t, m.Write(ctx, &plain, false))
+	assert.Equal(t, src.data, plain.Bytes())
+	require.NoError(t, m.Write(ctx, &compressed, true))
+	assert.Equal(t, src.data, gunzip(t, compressed.Bytes()))
+
+	src.err = errors.New("диск заполнен")
+	var failed bytes.Buffer
+	assert.Error(t, m.Write(ctx, &failed, true))
+	assert.Zero(t, failed.Len(), "при ошибке копии ничего не записывается")
+
+	_, err := m.Create(ctx)
+	assert.ErrorIs(t, err, ErrNoDir)
+}
+
+func TestCreateRotates(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.txt"), nil, 0o600))
+
+	m := NewManager(&fakeSource{data: []byte("db")}, Options{Dir: dir, Keep: 2, Compress: true}, logging.Discard())
+	now := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	m.now = func() time.Time {
+		now = now.Add(time.Hour)
+		return now
+	}
+
+	var created []string
+	for i := 0; i < 3; i++ {
+		path, err := m.Create(ctx)
+		require.NoError(t, err)
+		created = append(created, path)
+	}
+	assert.Equal(t, filepath.Join(dir, "backup-20240115T130000.000Z.db.gz"), created[2])
+
+	backups, err := List(dir)
+	require.NoError(t, err)
+	assert.Equal(t, created[1:], backups, "остаются две последние копии")
+	assert.FileExists(t, filepath.Join(dir, "notes.txt"), "чужие файлы не удаляются")
+
+	data, err := os.ReadFile(created[2])
+	require.NoError(t, err)
+	assert.Equal(t, []byte("db"), gunzip(t, data))
+}
+
+func TestRunCreatesBackups(t *testing.T) {
+	dir := t.TempDir()
+	m := NewManager(&fakeSource{data: []byte("db")}, Options{Dir: dir}, logging.Discard())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	runs := make(chan error, 100)
+	go func() {
+		m.Run(ctx, 10*time.Millisecond, func(err error) { runs <- err })
+		close(done)
+	}()
+	require.Eventually(t, func() bool {
+		backups, _ := List(dir)
+		return len(backups) >= 2
+	}, time.Second, 5*time.Millisecond)
+	cancel()
+	<-done
+	close(runs)
+	require.NotEmpty(t, runs, "итог каждого запуска передается в report")
+	for err := range runs {
+		assert.NoError(t, err)
+	}
+}
+
+func TestRestore(t *testing.T) {
+	dir := t.TempDir()
+	dbFile := filepath.Join(dir, "scheduler.db")
+	require.NoError(t, os.WriteFile(dbFile, []byte("old"), 0o600))
+	require.NoError(t, os.WriteFile(dbFile+"-wal", []byte("old wal"), 0o600))
+
+	var compressed bytes.Buffer
+	zw := gzip.NewWriter(&compressed)
+	zw.Write([]byte("new"))
+	require.NoError(t, zw.Close())
+	backupFile := filepath.Join(dir, "backup.db.gz")
+	require.NoError(t, os.WriteFile(backupFile, compressed.Bytes(), 0o600))
+
+	// Копия, не прошедшая проверку, не трогает текущую БД
+	err := Restore(backupFile, dbFile, func(string) error { return errors.New("версия схемы 99") })
+	assert.ErrorContains(t, err, "версия схемы 99")
+	data, _ := os.ReadFile(dbFile)
+	assert.Equal(t, "old", string(data))
+
+	var checked string
+	require.NoError(t, Restore(backupFile, dbFile, func(path string) error {
+		data, err := os.ReadFile(path)
+		checked = string(data)
+		return err
+	}))
+	assert.Equal(t, "new", checked, "проверяется распакованная копия")
+	data, _ = os.ReadFile(dbFile)
+	assert.Equal(t, "new", string(data))
+	assert.NoFileExists(t, dbFile+"-wal", "журнал прежней БД не остается рядом с новой")
+	data, _ = os.ReadFile(dbFile + previousSuffix)
+	assert.Equal(t, "old", string(data))
+	data, _ = os.ReadFile(dbFile + previousSuffix + "-wal")
+	assert.Equal(t, "old wal", string(data))
+
+	// Несжатая копия; журнал от прошлого восстановления удаляется
+	plain := filepath.Join(dir, "plain.db")
+	require.NoError(t, os.WriteFile(plain, []byte("plain"), 0o600))
+	require.NoError(t, Restore(plain, dbFile, func(string) error { return nil }))
+	data, _ = os.ReadFile(dbFile)
+	assert.Equal(t, "plain", string(data))
+	assert.NoFileExists(t, dbFile+previousSuffix+"-wal")
+}
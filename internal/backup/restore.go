@@ -0,0 +1,83 @@
+package backup
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// gzipMagic — первые байты файла gzip: сжатая копия распознается по содержимому, а не по имени
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// previousSuffix добавляется к файлу БД, замененному при восстановлении
+const previousSuffix = ".before-restore"
+
+// Restore заменяет файл БД dbFile копией backupFile (сжатой gzip или нет).
+//
+// Копия распаковывается во временный файл рядом с dbFile и проверяется функцией check
+// (например, что версия схемы поддерживается); файл БД заменяется, только если проверка
+// прошла. Прежний файл БД вместе с журналами -wal и -shm сохраняется с суффиксом
+// .before-restore. Сервер на время восстановления должен быть остановлен.
+func Restore(backupFile, dbFile string, check func(path string) error) error {
+	src, err := os.Open(backupFile)
+	if err != nil {
+		return fmt.Errorf("не удалось открыть копию: %w", err)
+	}
+	defer src.Close()
+
+	var r io.Reader = bufio.NewReader(src)
+	if magic, _ := r.(*bufio.Reader).Peek(len(gzipMagic)); bytes.Equal(magic, gzipMagic) {
+		zr, err := gzip.NewReader(r)
+		if err != nil {
+			return fmt.Errorf("не удалось распаковать копию: %w", err)
+		}
+		defer zr.Close()
+		r = zr
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dbFile), 0o755); err != nil {
+		return fmt.Errorf("не удалось создать каталог БД: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(dbFile), ".restore-*")
+	if err != nil {
+		return fmt.Errorf("не удалось создать временный файл: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = io.Copy(tmp, r)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("не удалось распаковать копию: %w", err)
+	}
+
+	if err := check(tmp.Name()); err != nil {
+		return fmt.Errorf("копия не прошла проверку: %w", err)
+	}
+
+	// Журналы принадлежат прежнему файлу: оставленные рядом с новым, они испортили бы его.
+	// Файлы от предыдущего восстановления удаляются все, иначе старый -wal оказался бы рядом с прежним файлом.
+	for _, suffix := range []string{"", "-wal", "-shm"} {
+		if err := os.Remove(dbFile + previousSuffix + suffix); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("не удалось удалить прежнюю копию БД: %w", err)
+		}
+	}
+	for _, suffix := range []string{"", "-wal", "-shm"} {
+		err := os.Rename(dbFile+suffix, dbFile+previousSuffix+suffix)
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("не удалось сохранить прежний файл БД: %w", err)
+		}
+	}
+	if err := os.Rename(tmp.Name(), dbFile); err != nil {
+		return fmt.Errorf("не удалось заменить файл БД: %w", err)
+	}
+	return nil
+}
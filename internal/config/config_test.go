@@ -0,0 +1,179 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func envFrom(m map[string]string) func(string) (string, bool) {
+	return func(name string) (string, bool) {
+		v, ok := m[name]
+		return v, ok
+	}
+}
+
+func writeConfig(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoadDefaults(t *testing.T) {
+	cfg, err := load(nil, envFrom(nil))
+	require.NoError(t, err)
+	assert.Equal(t, Default(), cfg)
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	path := writeConfig(t, `
+addr: ":8000"
+db_file: /data/file.db
+web_dir: /srv/web
+log_level: warn
+timeouts:
+  read: 3s
+  shutdown: 30s
+cors_origins: ["https://app.example.com"]
+tracing:
+  exporter: otlp
+  endpoint: http://collector:4318
+sqlite:
+  journal_mode: delete
+  max_open_conns: 8
+`)
+
+	cfg, err := load([]string{"-config", path}, envFrom(nil))
+	require.NoError(t, err)
+	assert.Equal(t, ":8000", cfg.Addr)
+	assert.Equal(t, "/data/file.db", cfg.DBFile)
+	assert.Equal(t, "/srv/web", cfg.WebDir)
+	assert.Equal(t, "warn", cfg.LogLevel)
+	assert.Equal(t, 3*time.Second, cfg.Timeouts.Read)
+	assert.Equal(t, Default().Timeouts.Write, cfg.Timeouts.Write)
+	assert.Equal(t, 30*time.Second, cfg.Timeouts.Shutdown)
+	assert.Equal(t, Tracing{Exporter: "otlp", Endpoint: "http://collector:4318"}, cfg.Tracing)
+	assert.Equal(t, []string{"https://app.example.com"}, cfg.CORSOrigins)
+	assert.Equal(t, "delete", cfg.SQLite.JournalMode)
+	assert.Equal(t, 8, cfg.SQLite.MaxOpenConns)
+	assert.Equal(t, Default().SQLite.BusyTimeout, cfg.SQLite.BusyTimeout)
+
+	// Переменные окружения переопределяют файл
+	env := envFrom(map[string]string{
+		"TODO_CONFIG":       path,
+		"TODO_PORT":         "9000",
+		"TODO_DBFILE":       "/env/file.db",
+		"TODO_DB_DSN":       "postgres://env@db/tasks",
+		"TODO_EPHEMERAL":    "false",
+		"TODO_READ_TIMEOUT": "4s",
+
+		"TODO_TRACING_EXPORTER": "stdout",
+		"TODO_MAX_BODY_BYTES":   "2048",
+
+		"TODO_SQLITE_SYNCHRONOUS":    "full",
+		"TODO_SQLITE_BUSY_TIMEOUT":   "1s",
+		"TODO_SQLITE_FOREIGN_KEYS":   "false",
+		"TODO_SQLITE_MAX_IDLE_CONNS": "2",
+	})
+	cfg, err = load(nil, env)
+	require.NoError(t, err)
+	assert.Equal(t, ":9000", cfg.Addr)
+	assert.Equal(t, "/env/file.db", cfg.DBFile)
+	assert.Equal(t, "postgres://env@db/tasks", cfg.DBDSN)
+	assert.False(t, cfg.Ephemeral)
+	assert.Equal(t, "/srv/web", cfg.WebDir)
+	assert.Equal(t, 4*time.Second, cfg.Timeouts.Read)
+	assert.Equal(t, "stdout", cfg.Tracing.Exporter)
+	assert.Equal(t, int64(2048), cfg.MaxBodyBytes)
+	assert.Equal(t, []string{"https://app.example.com"}, cfg.CORSOrigins)
+	assert.Equal(t, SQLite{
+		JournalMode:  "delete",
+		BusyTimeout:  time.Second,
+		ForeignKeys:  false,
+		Synchronous:  "full",
+		MaxOpenConns: 8,
+		MaxIdleConns: 2,
+	}, cfg.SQLite)
+
+	// Флаги переопределяют переменные окружения
+	cfg, err = load([]string{"-addr", "127.0.0.1:9100", "-db-dsn", "postgresql://flag@db/tasks", "--ephemeral", "-read-timeout", "5s", "-log-level", "debug", "-cors-origins", "http://a.test, http://b.test",
+		"-sqlite-journal-mode", "wal", "-sqlite-max-idle-conns", "8"}, env)
+	require.NoError(t, err)
+	assert.Equal(t, "127.0.0.1:9100", cfg.Addr)
+	assert.Equal(t, "/env/file.db", cfg.DBFile)
+	assert.Equal(t, "postgresql://flag@db/tasks", cfg.DBDSN)
+	assert.True(t, cfg.Ephemeral)
+	assert.Equal(t, 5*time.Second, cfg.Timeouts.Read)
+	assert.Equal(t, "debug", cfg.LogLevel)
+	assert.Equal(t, []string{"http://a.test", "http://b.test"}, cfg.CORSOrigins)
+	assert.Equal(t, "wal", cfg.SQLite.JournalMode)
+	assert.Equal(t, 8, cfg.SQLite.MaxIdleConns)
+	assert.Equal(t, "full", cfg.SQLite.Synchronous)
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		env  map[string]string
+		file string
+	}{
+		{name: "неизвестный флаг", args: []string{"-port", "1"}},
+		{name: "лишний аргумент", args: []string{"serve"}},
+		{name: "некорректный порт", env: map[string]string{"TODO_PORT": "abc"}},
+		{name: "некорректный таймаут", env: map[string]string{"TODO_IDLE_TIMEOUT": "10"}},
+		{name: "некорректный адрес", args: []string{"-addr", "localhost"}},
+		{name: "неизвестный уровень", args: []string{"-log-level", "trace"}},
+		{name: "неизвестный формат", env: map[string]string{"TODO_LOG_FORMAT": "xml"}},
+		{name: "неизвестный экспортер", args: []string{"-tracing-exporter", "jaeger"}},
+		{name: "некорректный источник CORS", env: map[string]string{"TODO_CORS_ORIGINS": "example.com"}},
+		{name: "некорректный флаг ephemeral", env: map[string]string{"TODO_EPHEMERAL": "maybe"}},
+		{name: "некорректный DSN", env: map[string]string{"TODO_DB_DSN": "mysql://db/tasks"}},
+		{name: "неизвестный режим журнала", args: []string{"-sqlite-journal-mode", "wal2"}},
+		{name: "неизвестный уровень синхронизации", env: map[string]string{"TODO_SQLITE_SYNCHRONOUS": "fast"}},
+		{name: "некорректный размер пула", env: map[string]string{"TODO_SQLITE_MAX_OPEN_CONNS": "four"}},
+		{name: "пул без соединений", args: []string{"-sqlite-max-open-conns", "0"}},
+		{name: "простаивающих больше открытых", args: []string{"-sqlite-max-open-conns", "2", "-sqlite-max-idle-conns", "3"}},
+		{name: "отрицательное ожидание блокировки", args: []string{"-sqlite-busy-timeout", "-1s"}},
+		{name: "копирование без каталога", args: []string{"-backup-interval", "1h"}},
+		{name: "копирование PostgreSQL", args: []string{"-backup-interval", "1h", "-backup-dir", "/backups", "-db-dsn", "postgres://db/tasks"}},
+		{name: "отрицательное число копий", env: map[string]string{"TODO_BACKUP_KEEP": "-1"}},
+		{name: "нулевой лимит тела", args: []string{"-max-body-bytes", "0"}},
+		{name: "нулевой таймаут", args: []string{"-write-timeout", "0s"}},
+		{name: "неизвестный ключ", file: "adress: \":1\"\n"},
+		{name: "файл не найден", args: []string{"-config", "/nonexistent/config.yaml"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := tt.args
+			if tt.file != "" {
+				args = append(args, "-config", writeConfig(t, tt.file))
+			}
+			_, err := load(args, envFrom(tt.env))
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestLoadBackup(t *testing.T) {
+	env := envFrom(map[string]string{
+		"TODO_BACKUP_DIR":      "/backups",
+		"TODO_BACKUP_INTERVAL": "6h",
+		"TODO_BACKUP_GZIP":     "false",
+	})
+	cfg, err := load([]string{"-backup-keep", "30"}, env)
+	require.NoError(t, err)
+	assert.Equal(t, Backup{Dir: "/backups", Interval: 6 * time.Hour, Keep: 30, Gzip: false}, cfg.Backup)
+}
+
+func TestParseReturnsArgs(t *testing.T) {
+	cfg, args, err := parse([]string{"-db", "/data/tasks.db", "backup.db.gz"}, envFrom(nil))
+	require.NoError(t, err)
+	assert.Equal(t, "/data/tasks.db", cfg.DBFile)
+	assert.Equal(t, []string{"backup.db.gz"}, args)
+}
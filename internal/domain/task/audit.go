@@ -0,0 +1,224 @@
+package task
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"tasktracker/internal/i18n"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// AuditAction — вид изменения в журнале
+type AuditAction string
+
+const (
+	AuditCreate AuditAction = "create"
+	AuditUpdate AuditAction = "update"
+	// AuditComplete — выполнение задачи: перенос повторяющейся на следующую дату
+	// или удаление разовой
+	AuditComplete AuditAction = "complete"
+	AuditDelete   AuditAction = "delete"
+)
+
+// ParseAuditAction разбирает вид изменения; пустая строка — любое изменение
+func ParseAuditAction(s string) (AuditAction, error) {
+	switch action := AuditAction(s); action {
+	case "", AuditCreate, AuditUpdate, AuditComplete, AuditDelete:
+		return action, nil
+	}
+	return "", Invalid("action", i18n.AuditActionUnknown, i18n.Params{"action": s})
+}
+
+// ActorSystem — автор изменений, для которых AuditContext не назвал автора
+const ActorSystem = "system"
+
+// AuditChange — значение поля до и после изменения; nil — поля нет (задача создана или удалена)
+type AuditChange struct {
+	Before *string `json:"before"`
+	After  *string `json:"after"`
+}
+
+// AuditEntry — запись журнала изменений. Журнал только пополняется: записи не изменяются
+// и не удаляются, в том числе вместе с задачей.
+type AuditEntry struct {
+	ID        int64                  `json:"id,string"`
+	Time      time.Time              `json:"time"`
+	Actor     string                 `json:"actor"`
+	Action    AuditAction            `json:"action"`
+	TaskID    int64                  `json:"task_id,omitempty,string"` // Измененная задача; 0 — изменен список
+	ListID    int64                  `json:"list_id,omitempty,string"` // Измененный сохраненный список
+	RequestID string                 `json:"request_id,omitempty"`
+	Changes   map[string]AuditChange `json:"changes"` // Только изменившиеся поля
+}
+
+// AuditQuery отбирает записи журнала; пустые поля не ограничивают выборку
+type AuditQuery struct {
+	Actor     string
+	Action    AuditAction
+	TaskID    int64
+	ListID    int64
+	RequestID string
+	Since     time.Time // Записи не раньше Since
+	Until     time.Time // Записи раньше Until
+	Before    int64     // Записи с id меньше Before (курсор следующей страницы)
+	Limit     int
+}
+
+// AuditPage — страница журнала, от новых записей к старым
+type AuditPage struct {
+	Entries    []AuditEntry
+	NextCursor string // Пустой, если страница последняя
+}
+
+// ParseAuditCursor разбирает курсор страницы журнала, полученный от клиента
+func ParseAuditCursor(s string) (int64, error) {
+	id, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || id <= 0 {
+		return 0, Invalid("cursor", i18n.CursorInvalid, nil)
+	}
+	return id, nil
+}
+
+// AuditContext возвращает автора изменения и идентификатор запроса из контекста вызова
+type AuditContext func(ctx context.Context) (actor, requestID string)
+
+// WithAuditContext задает, откуда журнал изменений берет автора и идентификатор запроса
+func WithAuditContext(f AuditContext) Option {
+	return func(s *Service) {
+		s.auditContext = f
+	}
+}
+
+// GetAudit возвращает страницу журнала изменений, от новых записей к старым
+func (s *Service) GetAudit(ctx context.Context, query AuditQuery) (_ *AuditPage, err error) {
+	ctx, span := startSpan(ctx, "GetAudit")
+	defer endSpan(span, &err)
+
+	limit, err := normalizeLimit(query.Limit)
+	if err != nil {
+		return nil, err
+	}
+	query.Limit = limit + 1
+
+	entries, err := s.repository.GetAudit(ctx, &query)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения журнала изменений: %w", err)
+	}
+
+	page := &AuditPage{Entries: entries}
+	if page.Entries == nil {
+		page.Entries = []AuditEntry{}
+	}
+	if len(page.Entries) > limit {
+		page.Entries = page.Entries[:limit]
+		page.NextCursor = strconv.FormatInt(page.Entries[limit-1].ID, 10)
+	}
+	span.SetAttributes(attribute.Int("audit.count", len(page.Entries)))
+	return page, nil
+}
+
+// GetTaskHistory возвращает страницу изменений одной задачи, в том числе уже удаленной
+func (s *Service) GetTaskHistory(ctx context.Context, id int64, query AuditQuery) (*AuditPage, error) {
+	if id <= 0 {
+		return nil, Invalid("id", i18n.TaskIDInvalid, nil)
+	}
+	query.TaskID, query.ListID = id, 0
+	return s.GetAudit(ctx, query)
+}
+
+// auditor пишет записи журнала от имени одного вызова сервиса
+type auditor struct {
+	actor     string
+	requestID string
+}
+
+func (s *Service) auditor(ctx context.Context) auditor {
+	a := auditor{actor: ActorSystem}
+	if s.auditContext != nil {
+		actor, requestID := s.auditContext(ctx)
+		if actor != "" {
+			a.actor = actor
+		}
+		a.requestID = requestID
+	}
+	return a
+}
+
+// task записывает изменение задачи; before — задача до изменения, after — после (nil, если ее нет)
+func (a auditor) task(ctx context.Context, tx Repository, action AuditAction, before, after *Task) error {
+	entry := &AuditEntry{Action: action, Changes: diffFields(taskFields(before), taskFields(after))}
+	if after != nil {
+		entry.TaskID = after.ID
+	} else {
+		entry.TaskID = before.ID
+	}
+	return a.append(ctx, tx, entry)
+}
+
+// list записывает изменение сохраненного списка
+func (a auditor) list(ctx context.Context, tx Repository, action AuditAction, before, after *SavedList) error {
+	entry := &AuditEntry{Action: action, Changes: diffFields(listFields(before), listFields(after))}
+	if after != nil {
+		entry.ListID = after.ID
+	} else {
+		entry.ListID = before.ID
+	}
+	return a.append(ctx, tx, entry)
+}
+
+func (a auditor) append(ctx context.Context, tx Repository, entry *AuditEntry) error {
+	// Время с точностью до микросекунд хранят все репозитории
+	entry.Time = time.Now().UTC().Truncate(time.Microsecond)
+	entry.Actor = a.actor
+	entry.RequestID = a.requestID
+	if err := tx.AppendAudit(ctx, entry); err != nil {
+		return fmt.Errorf("ошибка записи в журнал изменений: %w", err)
+	}
+	return nil
+}
+
+// taskFields возвращает хранимые поля задачи по их названиям в API
+func taskFields(t *Task) map[string]string {
+	if t == nil {
+		return nil
+	}
+	return map[string]string{
+		"date":           t.Date,
+		"title":          t.Title,
+		"comment":        t.Comment,
+		"repeat":         t.Repeat,
+		"overdue_policy": t.OverduePolicy,
+	}
+}
+
+func listFields(l *SavedList) map[string]string {
+	if l == nil {
+		return nil
+	}
+	return map[string]string{"name": l.Name, "query": l.Query}
+}
+
+// diffFields возвращает изменившиеся поля. При создании и удалении пустые поля
+// не перечисляются: они ничего не добавляют к записи.
+func diffFields(before, after map[string]string) map[string]AuditChange {
+	changes := make(map[string]AuditChange)
+	for name, b := range before {
+		a, ok := after[name]
+		switch {
+		case !ok && b != "":
+			changes[name] = AuditChange{Before: &b}
+		case ok && a != b:
+			changes[name] = AuditChange{Before: &b, After: &a}
+		}
+	}
+	if before == nil {
+		for name, a := range after {
+			if a != "" {
+				changes[name] = AuditChange{After: &a}
+			}
+		}
+	}
+	return changes
+}
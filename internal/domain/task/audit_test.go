@@ -0,0 +1,122 @@
+package task_test
+
+import (
+	"context"
+	"tasktracker/internal/domain/task"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// requestAuditContext — автор и запрос, от имени которых в тестах меняются задачи
+func requestAuditContext(context.Context) (string, string) {
+	return "owner", "req-1"
+}
+
+func ptr(s string) *string {
+	return &s
+}
+
+func TestAuditTaskChanges(t *testing.T) {
+	ctx := context.Background()
+	service, _ := newService(t, task.WithAuditContext(requestAuditContext))
+	now := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+	future := task.FormatDate(time.Now().AddDate(0, 0, 5))
+
+	tk := task.Task{Date: future, Title: "Отчет", Repeat: "d 7"}
+	require.NoError(t, service.CreateTask(ctx, &tk))
+	tk.Title, tk.Comment = "Квартальный отчет", "для бухгалтерии"
+	require.NoError(t, service.UpdateTask(ctx, &tk, task.UpdateOptions{}))
+	require.NoError(t, service.MarkTaskDone(ctx, tk.ID, now))
+	require.NoError(t, service.DeleteTask(ctx, tk.ID))
+
+	// Неудачные вызовы в журнал не попадают
+	assert.ErrorIs(t, service.DeleteTask(ctx, tk.ID), task.ErrNotFound)
+	assert.ErrorIs(t, service.UpdateTask(ctx, &tk, task.UpdateOptions{}), task.ErrNotFound)
+
+	page, err := service.GetTaskHistory(ctx, tk.ID, task.AuditQuery{})
+	require.NoError(t, err)
+	require.Len(t, page.Entries, 4)
+	assert.Empty(t, page.NextCursor)
+
+	deleted, done, updated, created := page.Entries[0], page.Entries[1], page.Entries[2], page.Entries[3]
+	assert.Equal(t, task.AuditCreate, created.Action)
+	assert.Equal(t, map[string]task.AuditChange{
+		"date":   {After: ptr(future)},
+		"title":  {After: ptr("Отчет")},
+		"repeat": {After: ptr("d 7")},
+	}, created.Changes)
+
+	assert.Equal(t, task.AuditUpdate, updated.Action)
+	assert.Equal(t, map[string]task.AuditChange{
+		"title":   {Before: ptr("Отчет"), After: ptr("Квартальный отчет")},
+		"comment": {Before: ptr(""), After: ptr("для бухгалтерии")},
+	}, updated.Changes)
+
+	nextDate, err := task.NextDate(now, future, "d 7")
+	require.NoError(t, err)
+	assert.Equal(t, task.AuditComplete, done.Action)
+	assert.Equal(t, map[string]task.AuditChange{"date": {Before: ptr(future), After: ptr(nextDate)}}, done.Changes)
+
+	assert.Equal(t, task.AuditDelete, deleted.Action)
+	assert.Equal(t, ptr("Квартальный отчет"), deleted.Changes["title"].Before)
+	assert.Nil(t, deleted.Changes["title"].After)
+
+	for _, e := range page.Entries {
+		assert.Equal(t, tk.ID, e.TaskID)
+		assert.Equal(t, "owner", e.Actor)
+		assert.Equal(t, "req-1", e.RequestID)
+		assert.False(t, e.Time.IsZero())
+	}
+
+	_, err = service.GetTaskHistory(ctx, 0, task.AuditQuery{})
+	assert.Equal(t, "id", fieldOf(t, err))
+}
+
+func TestAuditListsAndImport(t *testing.T) {
+	ctx := context.Background()
+	service, _ := newService(t)
+
+	list := task.SavedList{Name: "Работа", Query: "title:отчет"}
+	require.NoError(t, service.CreateSavedList(ctx, &list))
+	require.NoError(t, service.DeleteSavedList(ctx, list.ID))
+
+	archive := &task.Archive{
+		Format:  task.ArchiveFormat,
+		Version: task.ArchiveVersion,
+		Tasks:   []task.Task{{Date: "20240110", Title: "Первая"}, {Date: "20240111", Title: "Вторая"}},
+	}
+	_, err := service.Import(ctx, archive, task.ImportOptions{Mode: task.ImportAppend, DryRun: true})
+	require.NoError(t, err)
+	_, err = service.Import(ctx, archive, task.ImportOptions{Mode: task.ImportAppend})
+	require.NoError(t, err)
+
+	page, err := service.GetAudit(ctx, task.AuditQuery{})
+	require.NoError(t, err)
+	require.Len(t, page.Entries, 4, "пробный импорт откатывается вместе с журналом")
+	for _, e := range page.Entries {
+		assert.Equal(t, task.ActorSystem, e.Actor, "без AuditContext автор — system")
+	}
+	assert.Equal(t, list.ID, page.Entries[2].ListID)
+	assert.Equal(t, task.AuditDelete, page.Entries[2].Action)
+
+	page, err = service.GetAudit(ctx, task.AuditQuery{Action: task.AuditCreate, Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, page.Entries, 2)
+	require.NotEmpty(t, page.NextCursor)
+	before, err := task.ParseAuditCursor(page.NextCursor)
+	require.NoError(t, err)
+
+	page, err = service.GetAudit(ctx, task.AuditQuery{Action: task.AuditCreate, Before: before, Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, page.Entries, 1)
+	assert.Equal(t, list.ID, page.Entries[0].ListID)
+	assert.Empty(t, page.NextCursor)
+
+	_, err = task.ParseAuditAction("rename")
+	assert.Equal(t, "action", fieldOf(t, err))
+	_, err = task.ParseAuditCursor("abc")
+	assert.Equal(t, "cursor", fieldOf(t, err))
+}
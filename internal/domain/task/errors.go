@@ -0,0 +1,88 @@
+package task
+
+import (
+	"errors"
+	"tasktracker/internal/i18n"
+)
+
+// Виды ошибок предметной области. Проверяются через errors.Is и определяют
+// HTTP-статус ответа: 404, 400 и 409 соответственно.
+var (
+	ErrNotFound   = errors.New("не найдено")
+	ErrValidation = errors.New("ошибка валидации")
+	ErrConflict   = errors.New("конфликт")
+)
+
+// FieldError описывает ошибку в одном поле запроса
+type FieldError struct {
+	Field   string    `json:"field"`
+	Code    i18n.Code `json:"code"`
+	Message string    `json:"message"`
+}
+
+// Error — ошибка предметной области: вид (Kind), стабильный код с параметрами
+// для текста сообщения и, для ошибок валидации, поле запроса с ошибкой
+type Error struct {
+	Kind   error
+	Code   i18n.Code
+	Params i18n.Params
+	Field  string
+	Err    error // Исходная ошибка, если есть
+}
+
+// Error возвращает текст на языке по умолчанию (для логов)
+func (e *Error) Error() string {
+	return e.Localize(i18n.Default)
+}
+
+// Localize возвращает текст ошибки на языке lang
+func (e *Error) Localize(lang i18n.Lang) string {
+	return i18n.Message(lang, e.Code, e.Params)
+}
+
+// Is позволяет проверять вид ошибки: errors.Is(err, ErrNotFound)
+func (e *Error) Is(target error) bool {
+	return target == e.Kind
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Innermost возвращает самую вложенную ошибку с кодом: для «некорректное правило
+// повторения: день недели должен быть от 1 до 7» это ошибка repeat.week.day_out_of_range
+func (e *Error) Innermost() *Error {
+	for {
+		var inner *Error
+		if e.Err == nil || !errors.As(e.Err, &inner) {
+			return e
+		}
+		e = inner
+	}
+}
+
+// NotFound создает ошибку отсутствующего объекта
+func NotFound(code i18n.Code) error {
+	return &Error{Kind: ErrNotFound, Code: code}
+}
+
+// Conflict создает ошибку конфликта с текущим состоянием данных
+func Conflict(code i18n.Code, params i18n.Params) error {
+	return &Error{Kind: ErrConflict, Code: code, Params: params, Err: params.Cause()}
+}
+
+// Invalid создает ошибку валидации поля field. Ошибка среди параметров (обычно {cause})
+// сохраняется как исходная и доступна через errors.Is и errors.As.
+func Invalid(field string, code i18n.Code, params i18n.Params) error {
+	return &Error{Kind: ErrValidation, Code: code, Params: params, Field: field, Err: params.Cause()}
+}
+
+// invalid создает ошибку валидации, не привязанную к полю: ее поле определит вызывающий код
+func invalid(code i18n.Code, params i18n.Params) error {
+	return &Error{Kind: ErrValidation, Code: code, Params: params}
+}
+
+// Is относит ошибки разбора запроса к ошибкам валидации
+func (e *FilterError) Is(target error) bool {
+	return target == ErrValidation
+}
@@ -0,0 +1,326 @@
+package task
+
+import (
+	"strings"
+	"tasktracker/internal/i18n"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// FilterExpr представляет узел дерева фильтра списка задач.
+// Дерево строится функцией ParseFilter и транслируется в запрос хранилищем.
+type FilterExpr interface {
+	filterExpr()
+}
+
+// AndExpr истинен, если истинны все вложенные выражения
+type AndExpr struct {
+	Terms []FilterExpr
+}
+
+// NotExpr инвертирует вложенное выражение (префикс "-" в запросе)
+type NotExpr struct {
+	Expr FilterExpr
+}
+
+// TextField определяет текстовое поле, по которому выполняется поиск
+type TextField string
+
+const (
+	TextFieldAny     TextField = "any" // Заголовок или комментарий
+	TextFieldTitle   TextField = "title"
+	TextFieldComment TextField = "comment"
+)
+
+// TextMatch ищет подстроку в текстовом поле
+type TextMatch struct {
+	Field TextField
+	Value string
+}
+
+// CompareOp определяет оператор сравнения дат
+type CompareOp string
+
+const (
+	OpEq CompareOp = "="
+	OpLt CompareOp = "<"
+	OpLe CompareOp = "<="
+	OpGt CompareOp = ">"
+	OpGe CompareOp = ">="
+)
+
+// DateCompare сравнивает дату задачи с датой в формате YYYYMMDD
+type DateCompare struct {
+	Op   CompareOp
+	Date string
+}
+
+// RepeatKind определяет, какие правила повторения подходят под фильтр
+type RepeatKind string
+
+const (
+	RepeatAny     RepeatKind = "any"  // Любое правило повторения
+	RepeatNone    RepeatKind = "none" // Задача без повторения
+	RepeatDaily   RepeatKind = "d"
+	RepeatWeekly  RepeatKind = "w"
+	RepeatMonthly RepeatKind = "m"
+	RepeatYearly  RepeatKind = "y"
+)
+
+// RepeatMatch фильтрует задачи по типу правила повторения
+type RepeatMatch struct {
+	Kind RepeatKind
+}
+
+func (AndExpr) filterExpr()     {}
+func (NotExpr) filterExpr()     {}
+func (TextMatch) filterExpr()   {}
+func (DateCompare) filterExpr() {}
+func (RepeatMatch) filterExpr() {}
+
+// FilterError описывает ошибку разбора запроса с указанием проблемного токена
+type FilterError struct {
+	Pos    int    // Позиция токена в запросе (в символах, начиная с 1)
+	Token  string // Текст токена
+	Code   i18n.Code
+	Params i18n.Params
+}
+
+func (e *FilterError) Error() string {
+	return e.Localize(i18n.Default)
+}
+
+// Localize возвращает текст ошибки на языке lang вместе с позицией и токеном
+func (e *FilterError) Localize(lang i18n.Lang) string {
+	return i18n.Message(lang, i18n.FilterSyntax, i18n.Params{
+		"position": e.Pos,
+		"token":    e.Token,
+		"reason":   i18n.Message(lang, e.Code, e.Params),
+	})
+}
+
+// filterToken — лексема запроса вместе с позицией в исходной строке
+type filterToken struct {
+	text string
+	pos  int
+}
+
+// ParseFilter разбирает строку поискового запроса в дерево фильтра.
+//
+// Поддерживаемый синтаксис (условия объединяются через И):
+//
+//	слово, "фраза"            — подстрока в заголовке или комментарии
+//	title:слово               — подстрока в заголовке
+//	comment:"фраза"           — подстрока в комментарии
+//	date:20250101, 01.01.2025 — точная дата
+//	date>=20250101, date<...  — сравнение дат (=, <, <=, >, >=)
+//	repeat:any|none|d|w|m|y   — тип правила повторения
+//	is:overdue|today|recurring
+//	-условие                  — отрицание
+//
+// Пустой запрос возвращает nil. Значение now используется для is:overdue и is:today.
+func ParseFilter(input string, now time.Time) (FilterExpr, error) {
+	tokens, err := tokenizeFilter(input)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, nil
+	}
+
+	and := AndExpr{Terms: make([]FilterExpr, 0, len(tokens))}
+	for _, tok := range tokens {
+		term, err := parseFilterTerm(tok, now)
+		if err != nil {
+			return nil, err
+		}
+		and.Terms = append(and.Terms, term)
+	}
+
+	if len(and.Terms) == 1 {
+		return and.Terms[0], nil
+	}
+	return and, nil
+}
+
+// tokenizeFilter делит запрос на лексемы по пробелам с учетом кавычек
+func tokenizeFilter(input string) ([]filterToken, error) {
+	var tokens []filterToken
+	var current strings.Builder
+	start := 0
+	inQuotes := false
+	quotePos := 0
+	pos := 0
+
+	flush := func() {
+		if current.Len() > 0 {
+			tokens = append(tokens, filterToken{text: current.String(), pos: start})
+			current.Reset()
+		}
+	}
+
+	for _, r := range input {
+		pos++
+		switch {
+		case r == '"':
+			if !inQuotes {
+				quotePos = pos
+			}
+			inQuotes = !inQuotes
+			if current.Len() == 0 {
+				start = pos
+			}
+			current.WriteRune(r)
+		case unicode.IsSpace(r) && !inQuotes:
+			flush()
+		default:
+			if current.Len() == 0 {
+				start = pos
+			}
+			current.WriteRune(r)
+		}
+	}
+
+	if inQuotes {
+		return nil, &FilterError{Pos: quotePos, Token: current.String(), Code: i18n.FilterUnclosedQuote}
+	}
+	flush()
+
+	return tokens, nil
+}
+
+func parseFilterTerm(tok filterToken, now time.Time) (FilterExpr, error) {
+	text := tok.text
+	negate := false
+	if strings.HasPrefix(text, "-") && len(text) > 1 {
+		negate = true
+		text = text[1:]
+	}
+
+	term, err := parseFilterCondition(tok, text, now)
+	if err != nil {
+		return nil, err
+	}
+
+	if negate {
+		return NotExpr{Expr: term}, nil
+	}
+	return term, nil
+}
+
+func parseFilterCondition(tok filterToken, text string, now time.Time) (FilterExpr, error) {
+	field, op, value, ok := splitFilterCondition(text)
+	if !ok {
+		// Отдельное слово или фраза: дата в формате DD.MM.YYYY или текст
+		value := unquote(text)
+		if date, err := time.Parse("02.01.2006", value); err == nil {
+			return DateCompare{Op: OpEq, Date: FormatDate(date)}, nil
+		}
+		if value == "" {
+			return nil, &FilterError{Pos: tok.pos, Token: tok.text, Code: i18n.FilterEmptyValue}
+		}
+		return TextMatch{Field: TextFieldAny, Value: value}, nil
+	}
+
+	value = unquote(value)
+	fail := func(code i18n.Code, params i18n.Params) error {
+		return &FilterError{Pos: tok.pos, Token: tok.text, Code: code, Params: params}
+	}
+
+	if value == "" {
+		return nil, fail(i18n.FilterMissingValue, i18n.Params{"field": field})
+	}
+
+	switch field {
+	case "title", "comment", "text":
+		if op != ":" {
+			return nil, fail(i18n.FilterOperatorUnsupported, i18n.Params{"field": field})
+		}
+		textField := TextFieldAny
+		if field != "text" {
+			textField = TextField(field)
+		}
+		return TextMatch{Field: textField, Value: value}, nil
+
+	case "date":
+		date, err := NormalizeDate(value)
+		if err != nil {
+			return nil, fail(i18n.FilterDateInvalid, i18n.Params{"value": value})
+		}
+		cmp := CompareOp(op)
+		if op == ":" {
+			cmp = OpEq
+		}
+		return DateCompare{Op: cmp, Date: date}, nil
+
+	case "repeat":
+		if op != ":" {
+			return nil, fail(i18n.FilterOperatorUnsupported, i18n.Params{"field": field})
+		}
+		switch kind := RepeatKind(value); kind {
+		case RepeatAny, RepeatNone, RepeatDaily, RepeatWeekly, RepeatMonthly, RepeatYearly:
+			return RepeatMatch{Kind: kind}, nil
+		default:
+			return nil, fail(i18n.FilterRepeatUnknown, i18n.Params{"value": value})
+		}
+	}
+
+	// Остается is:состояние
+	if op != ":" {
+		return nil, fail(i18n.FilterOperatorUnsupported, i18n.Params{"field": field})
+	}
+	today := FormatDate(now)
+	switch value {
+	case "overdue":
+		return DateCompare{Op: OpLt, Date: today}, nil
+	case "today":
+		return DateCompare{Op: OpEq, Date: today}, nil
+	case "recurring":
+		return RepeatMatch{Kind: RepeatAny}, nil
+	default:
+		return nil, fail(i18n.FilterStateUnknown, i18n.Params{"value": value})
+	}
+}
+
+// filterFields — поля, которые можно указать в условии. Токен с другим префиксом
+// (например, http://example.com или a:b) ищется как обычный текст.
+var filterFields = map[string]bool{
+	"title": true, "comment": true, "text": true, "date": true, "repeat": true, "is": true,
+}
+
+// splitFilterCondition выделяет поле, оператор и значение из условия вида field:value или field>=value.
+// Условием считается только токен, начинающийся с имени одного из полей filterFields.
+func splitFilterCondition(text string) (field, op, value string, ok bool) {
+	i := 0
+	for i < len(text) {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		if r > unicode.MaxASCII || !unicode.IsLetter(r) {
+			break
+		}
+		i += size
+	}
+	if i == 0 || i == len(text) {
+		return "", "", "", false
+	}
+
+	field = strings.ToLower(text[:i])
+	if !filterFields[field] {
+		return "", "", "", false
+	}
+
+	rest := text[i:]
+	for _, candidate := range []string{"<=", ">=", ":", "=", "<", ">"} {
+		if strings.HasPrefix(rest, candidate) {
+			return field, candidate, rest[len(candidate):], true
+		}
+	}
+	return "", "", "", false
+}
+
+func unquote(s string) string {
+	if len(s) >= 2 && strings.HasPrefix(s, `"`) && strings.HasSuffix(s, `"`) {
+		return s[1 : len(s)-1]
+	}
+	return strings.ReplaceAll(s, `"`, "")
+}
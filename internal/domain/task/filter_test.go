@@ -0,0 +1,30 @@
+package task_test
+
+import (
+	"tasktracker/internal/domain/task"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFilterUnknownPrefixIsText(t *testing.T) {
+	now := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+
+	expr, err := task.ParseFilter("http://example.com a:b -Tag:work title:отчет", now)
+	require.NoError(t, err)
+	assert.Equal(t, task.AndExpr{Terms: []task.FilterExpr{
+		task.TextMatch{Field: task.TextFieldAny, Value: "http://example.com"},
+		task.TextMatch{Field: task.TextFieldAny, Value: "a:b"},
+		task.NotExpr{Expr: task.TextMatch{Field: task.TextFieldAny, Value: "Tag:work"}},
+		task.TextMatch{Field: task.TextFieldTitle, Value: "отчет"},
+	}}, expr)
+
+	// Ошибки в известных полях по-прежнему сообщаются
+	for _, bad := range []string{"title>x", "date:вчера", "repeat:hourly", "is:work", "comment:"} {
+		_, err := task.ParseFilter(bad, now)
+		var fe *task.FilterError
+		assert.ErrorAs(t, err, &fe, bad)
+	}
+}
@@ -0,0 +1,201 @@
+package task
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"tasktracker/internal/i18n"
+	"time"
+)
+
+// SavedList — именованный поисковый запрос, сохраненный пользователем
+type SavedList struct {
+	ID    int64  `db:"id" json:"id,string"`
+	Name  string `db:"name" json:"name"`
+	Query string `db:"query" json:"query"`           // Запрос на языке фильтров (см. ParseFilter)
+	Owner string `db:"owner" json:"owner,omitempty"` // Владелец (см. WithOwnerContext); пусто без аутентификации
+}
+
+// OwnerContext возвращает пользователя, от имени которого выполняется вызов
+type OwnerContext func(ctx context.Context) string
+
+// WithOwnerContext задает, откуда сервис берет владельца сохраненных списков.
+// Без него все списки принадлежат пустому владельцу.
+func WithOwnerContext(f OwnerContext) Option {
+	return func(s *Service) {
+		s.ownerContext = f
+	}
+}
+
+func (s *Service) owner(ctx context.Context) string {
+	if s.ownerContext == nil {
+		return ""
+	}
+	return s.ownerContext(ctx)
+}
+
+// SmartList описывает список задач, доступный через /api/lists: встроенный или сохраненный
+type SmartList struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Query   string `json:"query,omitempty"`
+	BuiltIn bool   `json:"builtin"`
+}
+
+// builtinList — встроенный умный список, фильтр которого зависит от текущей даты
+type builtinList struct {
+	id     string
+	name   i18n.Code
+	filter func(now time.Time) FilterExpr
+}
+
+const upcomingDays = 7
+
+var builtinLists = []builtinList{
+	{
+		id:   "today",
+		name: i18n.ListBuiltinToday,
+		filter: func(now time.Time) FilterExpr {
+			return DateCompare{Op: OpEq, Date: FormatDate(now)}
+		},
+	},
+	{
+		id:     "overdue",
+		name:   i18n.ListBuiltinOverdue,
+		filter: overdueFilter,
+	},
+	{
+		id:   "upcoming",
+		name: i18n.ListBuiltinUpcoming,
+		filter: func(now time.Time) FilterExpr {
+			return AndExpr{Terms: []FilterExpr{
+				DateCompare{Op: OpGe, Date: FormatDate(now)},
+				DateCompare{Op: OpLt, Date: FormatDate(now.AddDate(0, 0, upcomingDays))},
+			}}
+		},
+	},
+	{
+		id:   "norepeat",
+		name: i18n.ListBuiltinNoRepeat,
+		filter: func(now time.Time) FilterExpr {
+			return RepeatMatch{Kind: RepeatNone}
+		},
+	},
+}
+
+// GetSmartLists возвращает встроенные списки с названиями на языке lang,
+// за которыми следуют списки, сохраненные текущим пользователем
+func (s *Service) GetSmartLists(ctx context.Context, lang i18n.Lang) (_ []SmartList, err error) {
+	ctx, span := startSpan(ctx, "GetSmartLists")
+	defer endSpan(span, &err)
+
+	saved, err := s.repository.GetSavedLists(ctx, s.owner(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения списков: %w", err)
+	}
+
+	lists := make([]SmartList, 0, len(builtinLists)+len(saved))
+	for _, b := range builtinLists {
+		name := i18n.Message(lang, b.name, i18n.Params{"days": upcomingDays})
+		lists = append(lists, SmartList{ID: b.id, Name: name, BuiltIn: true})
+	}
+	for _, l := range saved {
+		lists = append(lists, SmartList{
+			ID:    strconv.FormatInt(l.ID, 10),
+			Name:  l.Name,
+			Query: l.Query,
+		})
+	}
+
+	return lists, nil
+}
+
+// CreateSavedList проверяет и сохраняет именованный запрос от имени текущего пользователя
+func (s *Service) CreateSavedList(ctx context.Context, list *SavedList) (err error) {
+	ctx, span := startSpan(ctx, "CreateSavedList")
+	defer endSpan(span, &err)
+
+	if err := validateSavedList(list, time.Now()); err != nil {
+		return err
+	}
+
+	list.Owner = s.owner(ctx)
+	audit := s.auditor(ctx)
+	return s.repository.WithTx(ctx, func(tx Repository) error {
+		if err := tx.CreateSavedList(ctx, list); err != nil {
+			return err
+		}
+		return audit.list(ctx, tx, AuditCreate, nil, list)
+	})
+}
+
+// validateSavedList убирает пробелы вокруг названия списка и проверяет название и запрос
+func validateSavedList(list *SavedList, now time.Time) error {
+	list.Name = strings.TrimSpace(list.Name)
+	if list.Name == "" {
+		return Invalid("name", i18n.ListNameEmpty, nil)
+	}
+	if strings.TrimSpace(list.Query) == "" {
+		return Invalid("query", i18n.ListQueryEmpty, nil)
+	}
+	_, err := ParseFilter(list.Query, now)
+	return err
+}
+
+// DeleteSavedList удаляет сохраненный список текущего пользователя.
+// Встроенные списки и списки других пользователей удалить нельзя.
+func (s *Service) DeleteSavedList(ctx context.Context, id int64) (err error) {
+	ctx, span := startSpan(ctx, "DeleteSavedList")
+	defer endSpan(span, &err)
+
+	if id <= 0 {
+		return Invalid("id", i18n.ListIDInvalid, nil)
+	}
+	owner := s.owner(ctx)
+	audit := s.auditor(ctx)
+	return s.repository.WithTx(ctx, func(tx Repository) error {
+		before, err := tx.GetSavedListByID(ctx, id, owner)
+		if err != nil {
+			return err
+		}
+		if err := tx.DeleteSavedList(ctx, id); err != nil {
+			return err
+		}
+		return audit.list(ctx, tx, AuditDelete, before, nil)
+	})
+}
+
+// GetListTasks возвращает страницу задач встроенного или сохраненного списка
+func (s *Service) GetListTasks(ctx context.Context, listID string, page ListQuery, now time.Time) (_ *TaskPage, err error) {
+	ctx, span := startSpan(ctx, "GetListTasks")
+	defer endSpan(span, &err)
+
+	filter, err := s.listFilter(ctx, listID, now)
+	if err != nil {
+		return nil, err
+	}
+
+	page.Filter = filter
+	return s.GetNearestTasks(ctx, page)
+}
+
+func (s *Service) listFilter(ctx context.Context, listID string, now time.Time) (FilterExpr, error) {
+	for _, b := range builtinLists {
+		if b.id == listID {
+			return b.filter(now), nil
+		}
+	}
+
+	id, err := strconv.ParseInt(listID, 10, 64)
+	if err != nil || id <= 0 {
+		return nil, NotFound(i18n.ListNotFound)
+	}
+
+	list, err := s.repository.GetSavedListByID(ctx, id, s.owner(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseFilter(list.Query, now)
+}
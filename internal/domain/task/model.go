@@ -1,7 +1,7 @@
 package task
 
 import (
-	"fmt"
+	"tasktracker/internal/i18n"
 	"time"
 )
 
@@ -31,6 +31,59 @@ type Task struct {
 	// - "w N,M,..." - повтор в указанные дни недели (1-7)
 	// - "m N[,M,...] [X,Y,...]" - повтор в указанные дни и месяцы
 	Repeat string `db:"repeat" json:"repeat"`
+
+	// OverduePolicy определяет, что делать с прошедшей датой задачи (см. OverduePolicy*)
+	// Пустое значение сохраняет поведение по умолчанию
+	OverduePolicy string `db:"overdue_policy" json:"overdue_policy,omitempty"`
+
+	// Overdue и DaysOverdue вычисляются при выдаче задачи и не хранятся в базе данных.
+	// В JSON они строки, как и остальные поля задачи: клиенты читают задачу как map[string]string
+	Overdue     bool `db:"-" json:"overdue,string,omitempty"`
+	DaysOverdue int  `db:"-" json:"days_overdue,string,omitempty"`
+}
+
+// Политики обработки прошедших дат
+const (
+	// OverduePolicyAuto — поведение по умолчанию: задача без повторения переносится на сегодня,
+	// задача с повторением — на следующую дату по правилу
+	OverduePolicyAuto = ""
+	// OverduePolicyKeep сохраняет прошедшую дату, задача считается просроченной
+	OverduePolicyKeep = "keep"
+	// OverduePolicyToday переносит прошедшую дату на сегодня
+	OverduePolicyToday = "today"
+	// OverduePolicyNext переносит прошедшую дату на следующую дату по правилу повторения
+	OverduePolicyNext = "next"
+)
+
+// ValidateOverduePolicy проверяет политику обработки прошедших дат
+func ValidateOverduePolicy(policy, repeat string) error {
+	switch policy {
+	case OverduePolicyAuto, OverduePolicyKeep, OverduePolicyToday:
+		return nil
+	case OverduePolicyNext:
+		if repeat == "" {
+			return Invalid("overdue_policy", i18n.OverduePolicyNextRequiresRepeat, nil)
+		}
+		return nil
+	default:
+		return Invalid("overdue_policy", i18n.OverduePolicyUnknown, i18n.Params{"policy": policy})
+	}
+}
+
+// markOverdue вычисляет признак просрочки относительно текущей даты
+func (t *Task) markOverdue(now time.Time) {
+	t.Overdue = false
+	t.DaysOverdue = 0
+
+	date, err := ParseDate(t.Date)
+	if err != nil {
+		return
+	}
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if date.Before(today) {
+		t.Overdue = true
+		t.DaysOverdue = int(today.Sub(date).Hours() / 24)
+	}
 }
 
 // DateFormat определяет формат даты, используемый во всем приложении
@@ -39,17 +92,28 @@ const DateFormat = "20060102"
 // ValidateDate проверяет корректность даты в формате YYYYMMDD
 func ValidateDate(date string) error {
 	if len(date) != 8 {
-		return fmt.Errorf("некорректная длина даты, ожидается 8 символов")
+		return invalid(i18n.DateLength, nil)
 	}
 
 	_, err := time.Parse(DateFormat, date)
 	if err != nil {
-		return fmt.Errorf("некорректный формат даты: %w", err)
+		return invalid(i18n.DateInvalid, i18n.Params{"value": date})
 	}
 
 	return nil
 }
 
+// NormalizeDate приводит дату в формате DD.MM.YYYY или YYYYMMDD к формату YYYYMMDD
+func NormalizeDate(value string) (string, error) {
+	if date, err := time.Parse("02.01.2006", value); err == nil {
+		return FormatDate(date), nil
+	}
+	if err := ValidateDate(value); err != nil {
+		return "", err
+	}
+	return value, nil
+}
+
 // ParseDate преобразует строковое представление даты в time.Time
 func ParseDate(date string) (time.Time, error) {
 	return time.Parse(DateFormat, date)
@@ -1,10 +1,10 @@
 package task
 
 import (
-	"fmt"
 	"sort"
 	"strconv"
 	"strings"
+	"tasktracker/internal/i18n"
 	"time"
 )
 
@@ -20,7 +20,7 @@ func NextDate(now time.Time, date string, repeat string) (string, error) {
 	}
 
 	if repeat == "" {
-		return "", fmt.Errorf("пустое правило повторения")
+		return "", invalid(i18n.RepeatEmpty, nil)
 	}
 
 	baseDate, err := ParseDate(date)
@@ -52,7 +52,7 @@ type RepeatRule struct {
 func ParseRepeatRule(rule string) (*RepeatRule, error) {
 	parts := strings.Fields(rule)
 	if len(parts) < 1 {
-		return nil, fmt.Errorf("некорректный формат правила")
+		return nil, invalid(i18n.RepeatFormat, nil)
 	}
 
 	r := &RepeatRule{Type: parts[0]}
@@ -64,7 +64,7 @@ func ParseRepeatRule(rule string) (*RepeatRule, error) {
 		}
 	case "y":
 		if len(parts) != 1 {
-			return nil, fmt.Errorf("правило y не требует дополнительных параметров")
+			return nil, invalid(i18n.RepeatYearExtraParams, nil)
 		}
 	case "w":
 		if err := r.parseWeekRule(parts); err != nil {
@@ -75,7 +75,7 @@ func ParseRepeatRule(rule string) (*RepeatRule, error) {
 			return nil, err
 		}
 	default:
-		return nil, fmt.Errorf("неподдерживаемый тип правила: %s", r.Type)
+		return nil, invalid(i18n.RepeatUnsupportedType, i18n.Params{"type": r.Type})
 	}
 
 	return r, nil
@@ -83,16 +83,16 @@ func ParseRepeatRule(rule string) (*RepeatRule, error) {
 
 func (r *RepeatRule) parseDayRule(parts []string) error {
 	if len(parts) != 2 {
-		return fmt.Errorf("правило d требует указания количества дней")
+		return invalid(i18n.RepeatDayMissing, nil)
 	}
 
 	days, err := strconv.Atoi(parts[1])
 	if err != nil {
-		return fmt.Errorf("некорректное количество дней: %w", err)
+		return invalid(i18n.RepeatDayInvalid, i18n.Params{"value": parts[1]})
 	}
 
 	if days <= 0 || days > maxDaysInterval {
-		return fmt.Errorf("количество дней должно быть в диапазоне от 1 до %d", maxDaysInterval)
+		return invalid(i18n.RepeatDayOutOfRange, i18n.Params{"max": maxDaysInterval})
 	}
 
 	r.Days = days
@@ -101,7 +101,7 @@ func (r *RepeatRule) parseDayRule(parts []string) error {
 
 func (r *RepeatRule) parseWeekRule(parts []string) error {
 	if len(parts) != 2 {
-		return fmt.Errorf("правило w требует указания дней недели")
+		return invalid(i18n.RepeatWeekMissing, nil)
 	}
 
 	daysStr := strings.Split(parts[1], ",")
@@ -110,10 +110,10 @@ func (r *RepeatRule) parseWeekRule(parts []string) error {
 	for _, dayStr := range daysStr {
 		day, err := strconv.Atoi(dayStr)
 		if err != nil {
-			return fmt.Errorf("некорректный день недели: %s", dayStr)
+			return invalid(i18n.RepeatWeekDayInvalid, i18n.Params{"value": dayStr})
 		}
 		if day < 1 || day > daysInWeek {
-			return fmt.Errorf("день недели должен быть от 1 до 7: %d", day)
+			return invalid(i18n.RepeatWeekDayOutOfRange, i18n.Params{"day": day})
 		}
 		r.DaysWeek = append(r.DaysWeek, day)
 	}
@@ -123,7 +123,7 @@ func (r *RepeatRule) parseWeekRule(parts []string) error {
 
 func (r *RepeatRule) parseMonthRule(parts []string) error {
 	if len(parts) < 2 || len(parts) > 3 {
-		return fmt.Errorf("правило m требует указания дней и опционально месяцев")
+		return invalid(i18n.RepeatMonthMissing, nil)
 	}
 
 	daysStr := strings.Split(parts[1], ",")
@@ -132,15 +132,15 @@ func (r *RepeatRule) parseMonthRule(parts []string) error {
 	for _, dayStr := range daysStr {
 		day, err := strconv.Atoi(dayStr)
 		if err != nil {
-			return fmt.Errorf("некорректный день месяца: %s", dayStr)
+			return invalid(i18n.RepeatMonthDayInvalid, i18n.Params{"value": dayStr})
 		}
 
 		// Проверяем допустимые значения
 		if day > 0 && day > 31 {
-			return fmt.Errorf("день месяца должен быть от 1 до 31 или -1, -2: %d", day)
+			return invalid(i18n.RepeatMonthDayOutOfRange, i18n.Params{"day": day})
 		}
 		if day < 0 && day < -2 {
-			return fmt.Errorf("отрицательный день месяца может быть только -1 или -2: %d", day)
+			return invalid(i18n.RepeatMonthNegativeDay, i18n.Params{"day": day})
 		}
 
 		r.MonthDays = append(r.MonthDays, day)
@@ -153,10 +153,10 @@ func (r *RepeatRule) parseMonthRule(parts []string) error {
 		for _, monthStr := range monthsStr {
 			month, err := strconv.Atoi(monthStr)
 			if err != nil {
-				return fmt.Errorf("некорректный месяц: %s", monthStr)
+				return invalid(i18n.RepeatMonthInvalid, i18n.Params{"value": monthStr})
 			}
 			if month < 1 || month > monthsInYear {
-				return fmt.Errorf("месяц должен быть от 1 до 12: %d", month)
+				return invalid(i18n.RepeatMonthOutOfRange, i18n.Params{"month": month})
 			}
 			r.Months = append(r.Months, month)
 		}
@@ -176,7 +176,7 @@ func (r *RepeatRule) calculateNextDate(now, base time.Time) (time.Time, error) {
 	case "m":
 		return r.calculateMonthRule(now, base)
 	default:
-		return time.Time{}, fmt.Errorf("неподдерживаемый тип правила: %s", r.Type)
+		return time.Time{}, invalid(i18n.RepeatUnsupportedType, i18n.Params{"type": r.Type})
 	}
 }
 
@@ -303,5 +303,5 @@ func (r *RepeatRule) calculateMonthRule(now, base time.Time) (time.Time, error)
 		next = next.AddDate(0, 0, 1)
 	}
 
-	return time.Time{}, fmt.Errorf("не удалось найти подходящую дату")
+	return time.Time{}, invalid(i18n.RepeatNoDate, nil)
 }
@@ -0,0 +1,107 @@
+package task
+
+import (
+	"html"
+	"strings"
+	"tasktracker/internal/i18n"
+	"unicode"
+)
+
+const (
+	// HighlightStart и HighlightEnd обрамляют найденные фрагменты в результатах поиска.
+	// Остальной текст подсветки экранируется как HTML.
+	HighlightStart = "<mark>"
+	HighlightEnd   = "</mark>"
+)
+
+// SearchTerm — слово или фраза полнотекстового поиска. Термин ищется целиком:
+// слова фразы в кавычках или слова, разделенные знаками (a-b), — подряд.
+type SearchTerm struct {
+	Text   string
+	Prefix bool // Слово с "*" на конце ищется как префикс
+}
+
+// SearchQuery содержит параметры полнотекстового поиска
+type SearchQuery struct {
+	Terms []SearchTerm // Все термины должны встречаться в заголовке или комментарии
+	Limit int
+}
+
+// SearchResult — задача, найденная полнотекстовым поиском
+type SearchResult struct {
+	Task
+
+	// TitleHighlight и CommentHighlight содержат текст с выделенными совпадениями
+	TitleHighlight   string `db:"title_highlight" json:"title_highlight"`
+	CommentHighlight string `db:"comment_highlight" json:"comment_highlight"`
+
+	// Rank — релевантность по bm25, чем меньше, тем лучше (0, если FTS5 недоступен)
+	Rank float64 `db:"rank" json:"rank"`
+}
+
+// ParseSearchTerms разбирает поисковую строку: слова, "фразы в кавычках" и префиксы вида слово*
+func ParseSearchTerms(input string) ([]SearchTerm, error) {
+	tokens, err := tokenizeFilter(input)
+	if err != nil {
+		return nil, err
+	}
+
+	terms := make([]SearchTerm, 0, len(tokens))
+	for _, tok := range tokens {
+		text := tok.text
+		term := SearchTerm{}
+
+		if strings.HasSuffix(text, "*") {
+			term.Prefix = true
+			text = strings.TrimSuffix(text, "*")
+		}
+		term.Text = strings.TrimSpace(unquote(text))
+
+		if !strings.ContainsFunc(term.Text, func(r rune) bool {
+			return unicode.IsLetter(r) || unicode.IsDigit(r)
+		}) {
+			return nil, &FilterError{Pos: tok.pos, Token: tok.text, Code: i18n.SearchTermEmpty}
+		}
+		terms = append(terms, term)
+	}
+
+	return terms, nil
+}
+
+// Highlight выделяет в тексте все вхождения терминов без учета регистра и экранирует
+// текст как HTML. Используется, когда хранилище не умеет подсвечивать совпадения само.
+func Highlight(text string, terms []SearchTerm) string {
+	runes := []rune(text)
+	lower := []rune(strings.ToLower(text))
+	if len(lower) != len(runes) {
+		// Нижний регистр изменил длину строки — подсветка по позициям невозможна
+		return html.EscapeString(text)
+	}
+
+	marked := make([]bool, len(runes))
+	for _, term := range terms {
+		needle := []rune(strings.ToLower(term.Text))
+		if len(needle) == 0 {
+			continue
+		}
+		for i := 0; i+len(needle) <= len(lower); i++ {
+			if string(lower[i:i+len(needle)]) == string(needle) {
+				for j := i; j < i+len(needle); j++ {
+					marked[j] = true
+				}
+			}
+		}
+	}
+
+	var b strings.Builder
+	for i, r := range runes {
+		if marked[i] && (i == 0 || !marked[i-1]) {
+			b.WriteString(HighlightStart)
+		}
+		b.WriteString(html.EscapeString(string(r)))
+		if marked[i] && (i == len(runes)-1 || !marked[i+1]) {
+			b.WriteString(HighlightEnd)
+		}
+	}
+	return b.String()
+}
@@ -1,32 +1,94 @@
 package task
 
 import (
+	"context"
 	"fmt"
+	"tasktracker/internal/i18n"
 	"time"
+
+	"go.opentelemetry.io/otel/attribute"
 )
 
 type Repository interface {
-	Create(*Task) error
-	GetTasks(*ListQuery) ([]Task, error)
-	GetTaskByID(int64) (*Task, error)
-	UpdateTask(*Task) error
-	DeleteTask(int64) error
-	UpdateTaskDate(int64, string) error
+	// Create сохраняет задачу и записывает ее идентификатор в ID. Если ID уже задан (импорт),
+	// задача сохраняется с этим идентификатором; занятый идентификатор — ErrConflict.
+	Create(context.Context, *Task) error
+	GetTasks(context.Context, *ListQuery) ([]Task, error)
+	Search(context.Context, *SearchQuery) ([]SearchResult, error)
+	GetTaskByID(context.Context, int64) (*Task, error)
+	UpdateTask(context.Context, *Task) error
+	DeleteTask(context.Context, int64) error
+	UpdateTaskDate(context.Context, int64, string) error
+
+	// CreateSavedList, как и Create, сохраняет список с заданным ID, если он указан
+	CreateSavedList(context.Context, *SavedList) error
+	// GetSavedLists и GetSavedListByID видят только списки владельца owner (SavedList.Owner)
+	// и общие списки без владельца
+	GetSavedLists(ctx context.Context, owner string) ([]SavedList, error)
+	GetSavedListByID(ctx context.Context, id int64, owner string) (*SavedList, error)
+	DeleteSavedList(context.Context, int64) error
+
+	GetStats(ctx context.Context, today string) (*Stats, error)
+
+	// AppendAudit добавляет запись в журнал изменений и записывает ее идентификатор в ID.
+	// Записи журнала не изменяются и не удаляются.
+	AppendAudit(context.Context, *AuditEntry) error
+	// GetAudit возвращает записи журнала, отобранные запросом, от новых к старым
+	GetAudit(context.Context, *AuditQuery) ([]AuditEntry, error)
+
+	// WithTx выполняет fn как единицу работы: все вызовы репозитория tx внутри fn
+	// выполняются в одной транзакции. Если fn вернула ошибку, изменения откатываются,
+	// иначе фиксируются. Одновременные единицы работы над одними и теми же задачами
+	// выполняются по очереди, поэтому чтение с последующей записью не теряет изменений.
+	// Вызов WithTx у tx выполняет fn в уже открытой транзакции.
+	WithTx(ctx context.Context, fn func(tx Repository) error) error
+}
+
+// Stats содержит количество задач по состояниям
+type Stats struct {
+	Upcoming  int `db:"upcoming" json:"upcoming"`   // Дата сегодня или позже
+	Overdue   int `db:"overdue" json:"overdue"`     // Дата уже прошла
+	Recurring int `db:"recurring" json:"recurring"` // Есть правило повторения
+}
+
+// Observer получает уведомления о событиях сервиса (например, для метрик)
+type Observer interface {
+	TaskCompleted(task Task)
+}
+
+// Option настраивает Service при создании
+type Option func(*Service)
+
+// WithObserver подключает наблюдателя за событиями сервиса
+func WithObserver(o Observer) Option {
+	return func(s *Service) {
+		s.observers = append(s.observers, o)
+	}
 }
 
 type Service struct {
-	repository Repository
+	repository   Repository
+	observers    []Observer
+	auditContext AuditContext
+	ownerContext OwnerContext
 }
 
-func NewService(repository Repository) *Service {
-	return &Service{
+func NewService(repository Repository, opts ...Option) *Service {
+	s := &Service{
 		repository: repository,
 	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
-func (s *Service) CreateTask(task *Task) error {
+func (s *Service) CreateTask(ctx context.Context, task *Task) (err error) {
+	ctx, span := startSpan(ctx, "CreateTask")
+	defer endSpan(span, &err)
+
 	if task.Title == "" {
-		return fmt.Errorf("заголовок задачи не может быть пустым")
+		return Invalid("title", i18n.TaskTitleEmpty, nil)
 	}
 
 	now := time.Now()
@@ -40,72 +102,214 @@ func (s *Service) CreateTask(task *Task) error {
 		task.Date = today
 	}
 
-	if err := ValidateDate(task.Date); err != nil {
-		return fmt.Errorf("некорректная дата: %w", err)
+	if err := validateTask(task); err != nil {
+		return err
 	}
 
-	if task.Date < today && task.Repeat != "" {
-		if task.Repeat == "d 1" {
-			task.Date = today
-		} else {
-			nextDate, err := NextDate(now, task.Date, task.Repeat)
-			if err != nil {
-				return fmt.Errorf("ошибка вычисления следующей даты: %w", err)
-			}
-			task.Date = nextDate
-		}
-	} else if task.Date < today {
-		task.Date = today
+	if err := applyOverduePolicy(task, now, false); err != nil {
+		return err
 	}
 
-	return s.repository.Create(task)
+	audit := s.auditor(ctx)
+	return s.repository.WithTx(ctx, func(tx Repository) error {
+		if err := tx.Create(ctx, task); err != nil {
+			return err
+		}
+		return audit.task(ctx, tx, AuditCreate, nil, task)
+	})
 }
 
-func (s *Service) GetNearestTasks(dateFilter, commentFilter string) ([]Task, error) {
-	query := &ListQuery{
-		Date:    dateFilter,
-		Comment: commentFilter,
-		Limit:   50,
+// GetNearestTasks возвращает страницу ближайших задач.
+// Из репозитория запрашивается на одну задачу больше, чтобы понять, есть ли следующая страница.
+func (s *Service) GetNearestTasks(ctx context.Context, query ListQuery) (_ *TaskPage, err error) {
+	ctx, span := startSpan(ctx, "GetNearestTasks")
+	defer endSpan(span, &err)
+
+	limit, err := normalizeLimit(query.Limit)
+	if err != nil {
+		return nil, err
 	}
+	query.Limit = limit + 1
 
-	tasks, err := s.repository.GetTasks(query)
+	tasks, err := s.repository.GetTasks(ctx, &query)
 	if err != nil {
 		return nil, fmt.Errorf("ошибка получения списка задач: %w", err)
 	}
 
-	if tasks == nil {
-		return []Task{}, nil
+	page := &TaskPage{Tasks: tasks}
+	if page.Tasks == nil {
+		page.Tasks = []Task{}
+	}
+
+	now := time.Now()
+	for i := range page.Tasks {
+		page.Tasks[i].markOverdue(now)
+	}
+
+	if len(page.Tasks) > limit {
+		page.Tasks = page.Tasks[:limit]
+		last := page.Tasks[limit-1]
+		page.NextCursor = Cursor{Date: last.Date, ID: last.ID}.Encode()
+	}
+	span.SetAttributes(attribute.Int("tasks.count", len(page.Tasks)))
+
+	return page, nil
+}
+
+// Search выполняет полнотекстовый поиск по заголовку и комментарию с ранжированием по релевантности
+func (s *Service) Search(ctx context.Context, text string, limit int) (_ []SearchResult, err error) {
+	ctx, span := startSpan(ctx, "Search")
+	defer endSpan(span, &err)
+
+	terms, err := ParseSearchTerms(text)
+	if err != nil {
+		return nil, err
+	}
+	if len(terms) == 0 {
+		return nil, Invalid("q", i18n.SearchQueryEmpty, nil)
+	}
+
+	limit, err = normalizeLimit(limit)
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := s.repository.Search(ctx, &SearchQuery{Terms: terms, Limit: limit})
+	if err != nil {
+		return nil, fmt.Errorf("ошибка полнотекстового поиска: %w", err)
+	}
+
+	if results == nil {
+		return []SearchResult{}, nil
 	}
 
-	return tasks, nil
+	now := time.Now()
+	for i := range results {
+		results[i].markOverdue(now)
+	}
+	span.SetAttributes(attribute.Int("tasks.count", len(results)))
+	return results, nil
+}
+
+// GetOverdueTasks возвращает страницу задач, дата которых уже прошла
+func (s *Service) GetOverdueTasks(ctx context.Context, query ListQuery, now time.Time) (_ *TaskPage, err error) {
+	ctx, span := startSpan(ctx, "GetOverdueTasks")
+	defer endSpan(span, &err)
+
+	query.Filter = overdueFilter(now)
+	return s.GetNearestTasks(ctx, query)
+}
+
+// overdueFilter отбирает задачи с датой раньше сегодняшней
+func overdueFilter(now time.Time) FilterExpr {
+	return DateCompare{Op: OpLt, Date: FormatDate(now)}
 }
 
-func (s *Service) GetTask(id int64) (*Task, error) {
+func (s *Service) GetTask(ctx context.Context, id int64) (_ *Task, err error) {
+	ctx, span := startSpan(ctx, "GetTask")
+	defer endSpan(span, &err)
+	span.SetAttributes(attribute.Int64("task.id", id))
+
 	if id <= 0 {
-		return nil, fmt.Errorf("некорректный идентификатор задачи")
+		return nil, Invalid("id", i18n.TaskIDInvalid, nil)
+	}
+
+	task, err := s.repository.GetTaskByID(ctx, id)
+	if err != nil {
+		return nil, err
 	}
-	return s.repository.GetTaskByID(id)
+
+	task.markOverdue(time.Now())
+	return task, nil
+}
+
+// UpdateOptions уточняет, какие поля задачи заменяет UpdateTask
+type UpdateOptions struct {
+	// KeepOverduePolicy оставляет сохраненную политику просрочки, не глядя на task.OverduePolicy.
+	// Нужна клиентам, которые не знают о политике и не передают ее при изменении задачи.
+	KeepOverduePolicy bool
 }
 
-func (s *Service) UpdateTask(task *Task) error {
+// UpdateTask заменяет задачу. Политика просрочки применяется к итоговой задаче,
+// прочитанной и измененной в одной транзакции.
+func (s *Service) UpdateTask(ctx context.Context, task *Task, opts UpdateOptions) (err error) {
+	ctx, span := startSpan(ctx, "UpdateTask")
+	defer endSpan(span, &err)
+	span.SetAttributes(attribute.Int64("task.id", task.ID))
+
 	if task.Title == "" {
-		return fmt.Errorf("заголовок задачи не может быть пустым")
+		return Invalid("title", i18n.TaskTitleEmpty, nil)
 	}
 
 	now := time.Now()
-	today := now.Format(DateFormat)
+	audit := s.auditor(ctx)
+	return s.repository.WithTx(ctx, func(tx Repository) error {
+		before, err := tx.GetTaskByID(ctx, task.ID)
+		if err != nil {
+			return err
+		}
+		if opts.KeepOverduePolicy {
+			task.OverduePolicy = before.OverduePolicy
+		}
+		if err := validateTask(task); err != nil {
+			return err
+		}
+		if err := applyOverduePolicy(task, now, true); err != nil {
+			return err
+		}
+		if err := tx.UpdateTask(ctx, task); err != nil {
+			return err
+		}
+		return audit.task(ctx, tx, AuditUpdate, before, task)
+	})
+}
 
+// validateTask проверяет дату, правило повторения и политику просрочки задачи
+func validateTask(task *Task) error {
 	if err := ValidateDate(task.Date); err != nil {
-		return fmt.Errorf("некорректная дата: %w", err)
+		return Invalid("date", i18n.TaskDateInvalid, i18n.Params{"cause": err})
+	}
+	if task.Repeat != "" {
+		if _, err := ParseRepeatRule(task.Repeat); err != nil {
+			return Invalid("repeat", i18n.TaskRepeatInvalid, i18n.Params{"cause": err})
+		}
 	}
+	return ValidateOverduePolicy(task.OverduePolicy, task.Repeat)
+}
 
-	if task.Date <= today && task.Repeat != "" {
+// applyOverduePolicy переносит прошедшую дату задачи в соответствии с ее политикой.
+// При repeatFromToday задача с повторением, назначенная на сегодня, тоже переносится
+// на следующую дату (так исторически работает редактирование задачи).
+func applyOverduePolicy(task *Task, now time.Time, repeatFromToday bool) error {
+	today := now.Format(DateFormat)
+
+	switch task.OverduePolicy {
+	case OverduePolicyKeep:
+		return nil
+	case OverduePolicyToday:
+		if task.Date < today {
+			task.Date = today
+		}
+		return nil
+	case OverduePolicyNext:
+		if task.Date < today {
+			nextDate, err := NextDate(now, task.Date, task.Repeat)
+			if err != nil {
+				return Invalid("repeat", i18n.TaskNextDateFailed, i18n.Params{"cause": err})
+			}
+			task.Date = nextDate
+		}
+		return nil
+	}
+
+	due := task.Date < today || (repeatFromToday && task.Date == today)
+	if due && task.Repeat != "" {
 		if task.Repeat == "d 1" {
 			task.Date = today
 		} else {
 			nextDate, err := NextDate(now, task.Date, task.Repeat)
 			if err != nil {
-				return fmt.Errorf("ошибка вычисления следующей даты: %w", err)
+				return Invalid("repeat", i18n.TaskNextDateFailed, i18n.Params{"cause": err})
 			}
 			task.Date = nextDate
 		}
@@ -113,30 +317,79 @@ func (s *Service) UpdateTask(task *Task) error {
 		task.Date = today
 	}
 
-	return s.repository.UpdateTask(task)
+	return nil
 }
-func (s *Service) MarkTaskDone(id int64, now time.Time) error {
-	task, err := s.repository.GetTaskByID(id)
+func (s *Service) MarkTaskDone(ctx context.Context, id int64, now time.Time) (err error) {
+	ctx, span := startSpan(ctx, "MarkTaskDone")
+	defer endSpan(span, &err)
+	span.SetAttributes(attribute.Int64("task.id", id))
+
+	// Чтение и перенос даты в одной транзакции: два одновременных «выполнено»
+	// переносят задачу на два повторения вперед, а не оба на одно и то же
+	var task *Task
+	audit := s.auditor(ctx)
+	err = s.repository.WithTx(ctx, func(tx Repository) error {
+		var err error
+		task, err = tx.GetTaskByID(ctx, id)
+		if err != nil {
+			return err
+		}
+
+		if task.Repeat == "" {
+			if err := tx.DeleteTask(ctx, id); err != nil {
+				return err
+			}
+			return audit.task(ctx, tx, AuditComplete, task, nil)
+		}
+		nextDate, err := NextDate(now, task.Date, task.Repeat)
+		if err != nil {
+			return Invalid("repeat", i18n.TaskNextDateFailed, i18n.Params{"cause": err})
+		}
+		if err := tx.UpdateTaskDate(ctx, id, nextDate); err != nil {
+			return err
+		}
+		after := *task
+		after.Date = nextDate
+		return audit.task(ctx, tx, AuditComplete, task, &after)
+	})
 	if err != nil {
 		return err
 	}
 
-	if task.Repeat == "" {
-		return s.repository.DeleteTask(id)
+	// Наблюдатели узнают о выполнении только после фиксации транзакции
+	for _, o := range s.observers {
+		o.TaskCompleted(*task)
 	}
+	return nil
+}
+
+// GetStats возвращает количество задач по состояниям относительно даты now
+func (s *Service) GetStats(ctx context.Context, now time.Time) (_ *Stats, err error) {
+	ctx, span := startSpan(ctx, "GetStats")
+	defer endSpan(span, &err)
 
-	nextDate, err := NextDate(now, task.Date, task.Repeat)
+	stats, err := s.repository.GetStats(ctx, FormatDate(now))
 	if err != nil {
-		return fmt.Errorf("ошибка вычисления следующей даты: %w", err)
+		return nil, fmt.Errorf("ошибка получения статистики задач: %w", err)
 	}
-
-	return s.repository.UpdateTaskDate(id, nextDate)
+	return stats, nil
 }
 
-func (s *Service) DeleteTask(id int64) error {
-	// Проверяем существование задачи перед удалением
-	if _, err := s.repository.GetTaskByID(id); err != nil {
-		return err
-	}
-	return s.repository.DeleteTask(id)
+func (s *Service) DeleteTask(ctx context.Context, id int64) (err error) {
+	ctx, span := startSpan(ctx, "DeleteTask")
+	defer endSpan(span, &err)
+	span.SetAttributes(attribute.Int64("task.id", id))
+
+	// Проверяем существование задачи перед удалением в той же транзакции
+	audit := s.auditor(ctx)
+	return s.repository.WithTx(ctx, func(tx Repository) error {
+		before, err := tx.GetTaskByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := tx.DeleteTask(ctx, id); err != nil {
+			return err
+		}
+		return audit.task(ctx, tx, AuditDelete, before, nil)
+	})
 }
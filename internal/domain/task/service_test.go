@@ -0,0 +1,311 @@
+package task_test
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"tasktracker/internal/domain/task"
+	"tasktracker/internal/i18n"
+	"tasktracker/internal/storage/memory"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// completions запоминает задачи, о выполнении которых сообщил сервис
+type completions []task.Task
+
+func (c *completions) TaskCompleted(t task.Task) {
+	*c = append(*c, t)
+}
+
+func newService(t *testing.T, opts ...task.Option) (*task.Service, *memory.Repository) {
+	t.Helper()
+	repo := memory.NewRepository()
+	return task.NewService(repo, opts...), repo
+}
+
+// fieldOf возвращает поле, к которому относится ошибка валидации
+func fieldOf(t *testing.T, err error) string {
+	t.Helper()
+	var e *task.Error
+	require.True(t, errors.As(err, &e), "ожидается *task.Error, получено %v", err)
+	return e.Field
+}
+
+func TestCreateTask(t *testing.T) {
+	ctx := context.Background()
+	service, repo := newService(t)
+	now := time.Now()
+	today := task.FormatDate(now)
+	past := task.FormatDate(now.AddDate(0, 0, -10))
+	future := task.FormatDate(now.AddDate(0, 0, 10))
+
+	tests := []struct {
+		name     string
+		in       task.Task
+		wantDate string
+	}{
+		{"без даты", task.Task{Title: "a"}, today},
+		{"today", task.Task{Title: "a", Date: "today"}, today},
+		{"будущая дата", task.Task{Title: "a", Date: future}, future},
+		{"прошедшая без повторения", task.Task{Title: "a", Date: past}, today},
+		{"прошедшая с повторением", task.Task{Title: "a", Date: past, Repeat: "d 20"}, task.FormatDate(now.AddDate(0, 0, 10))},
+		{"прошедшая, keep", task.Task{Title: "a", Date: past, OverduePolicy: task.OverduePolicyKeep}, past},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := tt.in
+			require.NoError(t, service.CreateTask(ctx, &in))
+			stored, err := repo.GetTaskByID(ctx, in.ID)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantDate, stored.Date)
+		})
+	}
+}
+
+func TestCreateTaskValidation(t *testing.T) {
+	ctx := context.Background()
+	service, repo := newService(t)
+
+	tests := []struct {
+		in    task.Task
+		field string
+	}{
+		{task.Task{Date: "20240101"}, "title"},
+		{task.Task{Title: "a", Date: "2024-01-01"}, "date"},
+		{task.Task{Title: "a", Date: "20240101", Repeat: "x 1"}, "repeat"},
+		{task.Task{Title: "a", Date: "20240101", OverduePolicy: task.OverduePolicyNext}, "overdue_policy"},
+	}
+	for _, tt := range tests {
+		err := service.CreateTask(ctx, &tt.in)
+		assert.ErrorIs(t, err, task.ErrValidation, "%+v", tt.in)
+		assert.Equal(t, tt.field, fieldOf(t, err), "%+v", tt.in)
+	}
+
+	stats, err := repo.GetStats(ctx, "20240101")
+	require.NoError(t, err)
+	assert.Equal(t, task.Stats{}, *stats, "некорректные задачи не должны сохраняться")
+}
+
+func TestGetNearestTasksPages(t *testing.T) {
+	ctx := context.Background()
+	service, repo := newService(t)
+	future := time.Now().AddDate(0, 0, 1)
+	for i := 0; i < 5; i++ {
+		require.NoError(t, repo.Create(ctx, &task.Task{Date: task.FormatDate(future.AddDate(0, 0, i%3)), Title: "t"}))
+	}
+
+	var ids []int64
+	query := task.ListQuery{Limit: 2}
+	for pages := 0; ; pages++ {
+		require.Less(t, pages, 5, "пагинация не завершилась")
+		page, err := service.GetNearestTasks(ctx, query)
+		require.NoError(t, err)
+		for _, tk := range page.Tasks {
+			ids = append(ids, tk.ID)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		query.After, err = task.DecodeCursor(page.NextCursor)
+		require.NoError(t, err)
+	}
+	// Порядок (date, id): дни 0, 1, 2 содержат задачи {1, 4}, {2, 5}, {3}
+	assert.Equal(t, []int64{1, 4, 2, 5, 3}, ids)
+
+	_, err := service.GetNearestTasks(ctx, task.ListQuery{Limit: -1})
+	assert.ErrorIs(t, err, task.ErrValidation)
+}
+
+func TestGetTaskMarksOverdue(t *testing.T) {
+	ctx := context.Background()
+	service, repo := newService(t)
+	past := task.FormatDate(time.Now().AddDate(0, 0, -3))
+	tk := task.Task{Date: past, Title: "Просрочена", OverduePolicy: task.OverduePolicyKeep}
+	require.NoError(t, repo.Create(ctx, &tk))
+
+	got, err := service.GetTask(ctx, tk.ID)
+	require.NoError(t, err)
+	assert.True(t, got.Overdue)
+	assert.Equal(t, 3, got.DaysOverdue)
+
+	_, err = service.GetTask(ctx, 0)
+	assert.ErrorIs(t, err, task.ErrValidation)
+	_, err = service.GetTask(ctx, 42)
+	assert.ErrorIs(t, err, task.ErrNotFound)
+}
+
+func TestMarkTaskDone(t *testing.T) {
+	ctx := context.Background()
+	var done completions
+	service, repo := newService(t, task.WithObserver(&done))
+	now := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+
+	once := task.Task{Date: "20240110", Title: "Разовая"}
+	weekly := task.Task{Date: "20240110", Title: "Еженедельная", Repeat: "d 7"}
+	require.NoError(t, repo.Create(ctx, &once))
+	require.NoError(t, repo.Create(ctx, &weekly))
+
+	require.NoError(t, service.MarkTaskDone(ctx, once.ID, now))
+	_, err := repo.GetTaskByID(ctx, once.ID)
+	assert.ErrorIs(t, err, task.ErrNotFound, "задача без повторения удаляется")
+
+	require.NoError(t, service.MarkTaskDone(ctx, weekly.ID, now))
+	got, err := repo.GetTaskByID(ctx, weekly.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "20240117", got.Date, "задача с повторением переносится на следующую дату")
+
+	assert.ErrorIs(t, service.MarkTaskDone(ctx, once.ID, now), task.ErrNotFound)
+	require.Len(t, done, 2)
+	assert.Equal(t, []string{"Разовая", "Еженедельная"}, []string{done[0].Title, done[1].Title})
+}
+
+func TestUpdateAndDeleteTask(t *testing.T) {
+	ctx := context.Background()
+	service, repo := newService(t)
+	future := task.FormatDate(time.Now().AddDate(0, 0, 5))
+
+	tk := task.Task{Date: future, Title: "Старое"}
+	require.NoError(t, repo.Create(ctx, &tk))
+
+	tk.Title = "Новое"
+	require.NoError(t, service.UpdateTask(ctx, &tk, task.UpdateOptions{}))
+	got, err := repo.GetTaskByID(ctx, tk.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Новое", got.Title)
+
+	tk.Title = ""
+	assert.ErrorIs(t, service.UpdateTask(ctx, &tk, task.UpdateOptions{}), task.ErrValidation)
+	assert.ErrorIs(t, service.UpdateTask(ctx, &task.Task{ID: 42, Date: future, Title: "Нет"}, task.UpdateOptions{}), task.ErrNotFound)
+
+	require.NoError(t, service.DeleteTask(ctx, tk.ID))
+	assert.ErrorIs(t, service.DeleteTask(ctx, tk.ID), task.ErrNotFound)
+}
+
+// Клиент, не знающий о политике просрочки, не должен сбрасывать ее при изменении задачи
+func TestUpdateTaskKeepsOverduePolicy(t *testing.T) {
+	ctx := context.Background()
+	service, repo := newService(t)
+	past := task.FormatDate(time.Now().AddDate(0, 0, -3))
+
+	tk := task.Task{Date: past, Title: "Отчет", OverduePolicy: task.OverduePolicyKeep}
+	require.NoError(t, repo.Create(ctx, &tk))
+
+	edit := task.Task{ID: tk.ID, Date: past, Title: "Квартальный отчет"}
+	require.NoError(t, service.UpdateTask(ctx, &edit, task.UpdateOptions{KeepOverduePolicy: true}))
+	got, err := repo.GetTaskByID(ctx, tk.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Квартальный отчет", got.Title)
+	assert.Equal(t, past, got.Date, "дата не переносится: политика keep сохранена")
+	assert.Equal(t, task.OverduePolicyKeep, got.OverduePolicy)
+
+	// Явно переданная пустая политика возвращает поведение по умолчанию
+	edit = task.Task{ID: tk.ID, Date: past, Title: "Квартальный отчет"}
+	require.NoError(t, service.UpdateTask(ctx, &edit, task.UpdateOptions{}))
+	got, err = repo.GetTaskByID(ctx, tk.ID)
+	require.NoError(t, err)
+	assert.Equal(t, task.OverduePolicyAuto, got.OverduePolicy)
+	assert.Equal(t, task.FormatDate(time.Now()), got.Date)
+}
+
+func TestListsAndStats(t *testing.T) {
+	ctx := context.Background()
+	service, repo := newService(t)
+	now := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+
+	for _, tk := range []task.Task{
+		{Date: "20240105", Title: "Просрочена"},
+		{Date: "20240110", Title: "Сегодня", Repeat: "d 1"},
+		{Date: "20240112", Title: "Отчет"},
+	} {
+		require.NoError(t, repo.Create(ctx, &tk))
+	}
+
+	page, err := service.GetListTasks(ctx, "overdue", task.ListQuery{}, now)
+	require.NoError(t, err)
+	require.Len(t, page.Tasks, 1)
+	assert.Equal(t, "Просрочена", page.Tasks[0].Title)
+
+	list := task.SavedList{Name: " Отчеты ", Query: "title:отчет"}
+	require.NoError(t, service.CreateSavedList(ctx, &list))
+	assert.Equal(t, "Отчеты", list.Name)
+	page, err = service.GetListTasks(ctx, "1", task.ListQuery{}, now)
+	require.NoError(t, err)
+	require.Len(t, page.Tasks, 1)
+	assert.Equal(t, "Отчет", page.Tasks[0].Title)
+
+	assert.ErrorIs(t, service.CreateSavedList(ctx, &task.SavedList{Name: "x", Query: "is:work"}), task.ErrValidation)
+	_, err = service.GetListTasks(ctx, "nope", task.ListQuery{}, now)
+	assert.ErrorIs(t, err, task.ErrNotFound)
+
+	stats, err := service.GetStats(ctx, now)
+	require.NoError(t, err)
+	assert.Equal(t, task.Stats{Upcoming: 2, Overdue: 1, Recurring: 1}, *stats)
+
+	results, err := service.Search(ctx, "отч*", 10)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	_, err = service.Search(ctx, "  ", 10)
+	assert.ErrorIs(t, err, task.ErrValidation)
+}
+
+// ownerKey хранит в контексте пользователя, от имени которого в тестах вызывается сервис
+type ownerKey struct{}
+
+func ownerFromContext(ctx context.Context) string {
+	owner, _ := ctx.Value(ownerKey{}).(string)
+	return owner
+}
+
+func TestSavedListsBelongToOwner(t *testing.T) {
+	service, _ := newService(t, task.WithOwnerContext(ownerFromContext))
+	alice := context.WithValue(context.Background(), ownerKey{}, "alice")
+	bob := context.WithValue(context.Background(), ownerKey{}, "bob")
+	now := time.Date(2024, 1, 10, 12, 0, 0, 0, time.UTC)
+
+	list := task.SavedList{Name: "Работа", Query: "title:отчет", Owner: "bob"}
+	require.NoError(t, service.CreateSavedList(alice, &list))
+	assert.Equal(t, "alice", list.Owner, "владелец берется из контекста, а не из запроса")
+	id := strconv.FormatInt(list.ID, 10)
+
+	savedIDs := func(ctx context.Context) []string {
+		lists, err := service.GetSmartLists(ctx, i18n.RU)
+		require.NoError(t, err)
+		var ids []string
+		for _, l := range lists {
+			if !l.BuiltIn {
+				ids = append(ids, l.ID)
+			}
+		}
+		return ids
+	}
+	assert.Equal(t, []string{id}, savedIDs(alice))
+	assert.Empty(t, savedIDs(bob))
+
+	_, err := service.GetListTasks(bob, id, task.ListQuery{}, now)
+	assert.ErrorIs(t, err, task.ErrNotFound)
+	assert.ErrorIs(t, service.DeleteSavedList(bob, list.ID), task.ErrNotFound)
+
+	_, err = service.GetListTasks(alice, id, task.ListQuery{}, now)
+	require.NoError(t, err)
+	require.NoError(t, service.DeleteSavedList(alice, list.ID))
+}
+
+func TestBuiltinListNames(t *testing.T) {
+	service, _ := newService(t)
+
+	names := func(lang i18n.Lang) []string {
+		lists, err := service.GetSmartLists(context.Background(), lang)
+		require.NoError(t, err)
+		var names []string
+		for _, l := range lists {
+			names = append(names, l.Name)
+		}
+		return names
+	}
+	assert.Equal(t, []string{"Сегодня", "Просроченные", "Ближайшие 7 дней", "Без повторения"}, names(i18n.RU))
+	assert.Equal(t, []string{"Today", "Overdue", "Next 7 days", "Not recurring"}, names(i18n.EN))
+}
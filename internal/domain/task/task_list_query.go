@@ -1,8 +1,75 @@
 package task
 
+import (
+	"encoding/base64"
+	"strconv"
+	"strings"
+	"tasktracker/internal/i18n"
+)
+
+const (
+	// DefaultListLimit используется, если клиент не указал размер страницы
+	DefaultListLimit = 50
+	// MaxListLimit ограничивает размер страницы на стороне сервера
+	MaxListLimit = 500
+)
+
 // ListQuery содержит параметры для фильтрации списка задач
 type ListQuery struct {
-	Date    string // Фильтр по дате
-	Comment string // Фильтр по комментарию
-	Limit   int    // Ограничение количества возвращаемых задач
+	Filter FilterExpr // Условия отбора, nil — без фильтрации
+	Limit  int        // Ограничение количества возвращаемых задач
+	After  *Cursor    // Позиция, после которой начинается страница (keyset-пагинация)
+}
+
+// Cursor задает позицию в списке задач, упорядоченном по (date, id)
+type Cursor struct {
+	Date string
+	ID   int64
+}
+
+// Encode возвращает непрозрачное строковое представление курсора для API
+func (c Cursor) Encode() string {
+	raw := c.Date + ":" + strconv.FormatInt(c.ID, 10)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// DecodeCursor разбирает курсор, полученный от клиента
+func DecodeCursor(s string) (*Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, Invalid("cursor", i18n.CursorInvalid, nil)
+	}
+
+	date, idStr, ok := strings.Cut(string(raw), ":")
+	if !ok {
+		return nil, Invalid("cursor", i18n.CursorInvalid, nil)
+	}
+	if err := ValidateDate(date); err != nil {
+		return nil, Invalid("cursor", i18n.CursorInvalid, nil)
+	}
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil || id <= 0 {
+		return nil, Invalid("cursor", i18n.CursorInvalid, nil)
+	}
+
+	return &Cursor{Date: date, ID: id}, nil
+}
+
+// TaskPage содержит одну страницу списка задач
+type TaskPage struct {
+	Tasks      []Task
+	NextCursor string // Пустой, если страница последняя
+}
+
+// normalizeLimit приводит запрошенный размер страницы к допустимому диапазону
+func normalizeLimit(limit int) (int, error) {
+	switch {
+	case limit < 0:
+		return 0, Invalid("limit", i18n.LimitInvalid, nil)
+	case limit == 0:
+		return DefaultListLimit, nil
+	case limit > MaxListLimit:
+		return MaxListLimit, nil
+	}
+	return limit, nil
 }
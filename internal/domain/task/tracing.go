@@ -0,0 +1,28 @@
+package task
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracer создает span'ы операций сервиса. Провайдер берется глобальный,
+// поэтому без настроенного экспортера трассировка ничего не стоит.
+var tracer = otel.Tracer("tasktracker/internal/domain/task")
+
+// startSpan открывает span операции сервиса с именем вида task.Service.CreateTask
+func startSpan(ctx context.Context, operation string) (context.Context, trace.Span) {
+	return tracer.Start(ctx, "task.Service."+operation)
+}
+
+// endSpan завершает span и отмечает его ошибкой, если операция не удалась.
+// Вызывается через defer с указателем на именованный результат err.
+func endSpan(span trace.Span, err *error) {
+	if *err != nil {
+		span.RecordError(*err)
+		span.SetStatus(codes.Error, (*err).Error())
+	}
+	span.End()
+}
@@ -0,0 +1,379 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"tasktracker/internal/i18n"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// Формат файла выгрузки. Версия увеличивается при несовместимых изменениях структуры,
+// импорт принимает файлы своей и более ранних версий.
+const (
+	ArchiveFormat  = "tasktracker"
+	ArchiveVersion = 1
+)
+
+// exportPageSize — сколько задач читается из репозитория за один запрос при выгрузке
+const exportPageSize = MaxListLimit
+
+// Archive — выгрузка всех данных планировщика: задачи и сохраненные списки
+type Archive struct {
+	Format     string      `json:"format"`
+	Version    int         `json:"version"`
+	ExportedAt time.Time   `json:"exported_at"`
+	Tasks      []Task      `json:"tasks"`
+	Lists      []SavedList `json:"lists"`
+}
+
+// ImportMode определяет, как импортируемые записи сочетаются с уже сохраненными
+type ImportMode string
+
+const (
+	// ImportMerge обновляет записи с совпадающим id, остальные добавляет с их id
+	ImportMerge ImportMode = "merge"
+	// ImportReplace удаляет все задачи и списки и сохраняет импортируемые с их id
+	ImportReplace ImportMode = "replace"
+	// ImportAppend добавляет все записи как новые, с новыми id
+	ImportAppend ImportMode = "append"
+)
+
+// ParseImportMode разбирает режим импорта; пустая строка — ImportMerge
+func ParseImportMode(s string) (ImportMode, error) {
+	switch mode := ImportMode(s); mode {
+	case "":
+		return ImportMerge, nil
+	case ImportMerge, ImportReplace, ImportAppend:
+		return mode, nil
+	}
+	return "", Invalid("mode", i18n.ImportModeUnknown, i18n.Params{"mode": s})
+}
+
+// ImportOptions задает режим импорта
+type ImportOptions struct {
+	Mode   ImportMode
+	DryRun bool // Только проверить данные и подсчитать изменения, ничего не сохраняя
+}
+
+// ImportCounts — сколько записей одного вида создано, изменено, пропущено и удалено
+type ImportCounts struct {
+	Created int `json:"created"`
+	Updated int `json:"updated"`
+	Skipped int `json:"skipped"` // Совпадают с уже сохраненными
+	Deleted int `json:"deleted"` // Удалены перед импортом в режиме replace
+}
+
+// Виды записей в ImportItemError
+const (
+	ImportItemTask = "task"
+	ImportItemList = "list"
+)
+
+// ImportItemError — ошибка в одной записи файла
+type ImportItemError struct {
+	Item  string // ImportItemTask или ImportItemList
+	Index int    // Позиция записи в своем массиве файла, с нуля
+	ID    int64
+	Err   error
+}
+
+// ImportSummary — итог импорта
+type ImportSummary struct {
+	Mode    ImportMode
+	DryRun  bool
+	Applied bool // Изменения сохранены: импорт не пробный и в данных нет ошибок
+	Tasks   ImportCounts
+	Lists   ImportCounts
+	Errors  []ImportItemError
+}
+
+// errDryRun откатывает транзакцию пробного импорта
+var errDryRun = errors.New("пробный импорт")
+
+// Export выгружает все задачи и сохраненные списки текущего пользователя. Данные читаются в одной
+// транзакции, поэтому выгрузка согласована даже при одновременных изменениях.
+func (s *Service) Export(ctx context.Context, now time.Time) (_ *Archive, err error) {
+	ctx, span := startSpan(ctx, "Export")
+	defer endSpan(span, &err)
+
+	archive := &Archive{
+		Format:     ArchiveFormat,
+		Version:    ArchiveVersion,
+		ExportedAt: now.UTC(),
+		Tasks:      []Task{},
+		Lists:      []SavedList{},
+	}
+	owner := s.owner(ctx)
+	err = s.repository.WithTx(ctx, func(tx Repository) error {
+		query := &ListQuery{Limit: exportPageSize}
+		for {
+			tasks, err := tx.GetTasks(ctx, query)
+			if err != nil {
+				return fmt.Errorf("ошибка получения списка задач: %w", err)
+			}
+			archive.Tasks = append(archive.Tasks, tasks...)
+			if len(tasks) < exportPageSize {
+				break
+			}
+			last := tasks[len(tasks)-1]
+			query.After = &Cursor{Date: last.Date, ID: last.ID}
+		}
+
+		lists, err := tx.GetSavedLists(ctx, owner)
+		if err != nil {
+			return fmt.Errorf("ошибка получения списков: %w", err)
+		}
+		archive.Lists = append(archive.Lists, lists...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	span.SetAttributes(attribute.Int("tasks.count", len(archive.Tasks)))
+	return archive, nil
+}
+
+// Import сохраняет задачи и списки из выгрузки в режиме opts.Mode.
+//
+// Сначала проверяются все записи по тем же правилам, что и при создании через API;
+// даты сохраняются как есть, без переноса прошедших. Если хотя бы одна запись
+// некорректна, ничего не сохраняется, а ошибки возвращаются в ImportSummary.Errors
+// (ошибка Import означает, что не подходит сам файл или импорт не удался).
+// Изменения выполняются в одной транзакции и попадают в журнал изменений по одной
+// записи на задачу или список; при opts.DryRun транзакция откатывается вместе с журналом,
+// и итог показывает, что изменил бы импорт.
+func (s *Service) Import(ctx context.Context, archive *Archive, opts ImportOptions) (_ *ImportSummary, err error) {
+	ctx, span := startSpan(ctx, "Import")
+	defer endSpan(span, &err)
+
+	if archive.Format != ArchiveFormat {
+		return nil, Invalid("format", i18n.ImportFormatUnsupported, i18n.Params{"format": archive.Format})
+	}
+	if archive.Version < 1 || archive.Version > ArchiveVersion {
+		return nil, Invalid("version", i18n.ImportVersionUnsupported, i18n.Params{"version": archive.Version, "max": ArchiveVersion})
+	}
+	mode, err := ParseImportMode(string(opts.Mode))
+	if err != nil {
+		return nil, err
+	}
+	span.SetAttributes(
+		attribute.String("import.mode", string(mode)),
+		attribute.Bool("import.dry_run", opts.DryRun),
+		attribute.Int("tasks.count", len(archive.Tasks)),
+	)
+
+	summary := &ImportSummary{Mode: mode, DryRun: opts.DryRun}
+	summary.Errors = validateArchive(archive, mode, time.Now())
+	if len(summary.Errors) > 0 {
+		return summary, nil
+	}
+
+	owner := s.owner(ctx)
+	audit := s.auditor(ctx)
+	err = s.repository.WithTx(ctx, func(tx Repository) error {
+		if err := importArchive(ctx, tx, audit, owner, archive, mode, summary); err != nil {
+			return err
+		}
+		if opts.DryRun {
+			return errDryRun
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errDryRun) {
+		return nil, err
+	}
+
+	summary.Applied = !opts.DryRun
+	return summary, nil
+}
+
+// validateArchive проверяет все записи выгрузки и возвращает ошибки по каждой.
+// Вне режима append id записей одного вида не должны повторяться.
+func validateArchive(archive *Archive, mode ImportMode, now time.Time) []ImportItemError {
+	var errs []ImportItemError
+
+	taskIDs := make(map[int64]bool)
+	for i, t := range archive.Tasks {
+		err := validateImportedTask(&t)
+		if err == nil && mode != ImportAppend && t.ID != 0 {
+			if taskIDs[t.ID] {
+				err = Invalid("id", i18n.ImportIDDuplicate, i18n.Params{"id": t.ID})
+			}
+			taskIDs[t.ID] = true
+		}
+		if err != nil {
+			errs = append(errs, ImportItemError{Item: ImportItemTask, Index: i, ID: t.ID, Err: err})
+		}
+	}
+
+	listIDs := make(map[int64]bool)
+	for i, l := range archive.Lists {
+		var err error
+		if l.ID < 0 {
+			err = Invalid("id", i18n.ListIDInvalid, nil)
+		} else {
+			err = validateSavedList(&l, now)
+		}
+		if err == nil && mode != ImportAppend && l.ID != 0 {
+			if listIDs[l.ID] {
+				err = Invalid("id", i18n.ImportIDDuplicate, i18n.Params{"id": l.ID})
+			}
+			listIDs[l.ID] = true
+		}
+		if err != nil {
+			errs = append(errs, ImportItemError{Item: ImportItemList, Index: i, ID: l.ID, Err: err})
+		}
+	}
+
+	return errs
+}
+
+// validateImportedTask проверяет задачу из выгрузки так же, как CreateTask, но без подстановки даты
+func validateImportedTask(t *Task) error {
+	if t.ID < 0 {
+		return Invalid("id", i18n.TaskIDInvalid, nil)
+	}
+	if t.Title == "" {
+		return Invalid("title", i18n.TaskTitleEmpty, nil)
+	}
+	return validateTask(t)
+}
+
+// importArchive сохраняет проверенные записи выгрузки в транзакции tx и подсчитывает изменения.
+// Списки сохраняются и заменяются от имени владельца owner; владелец из выгрузки не учитывается.
+func importArchive(ctx context.Context, tx Repository, audit auditor, owner string, archive *Archive, mode ImportMode, summary *ImportSummary) error {
+	if mode == ImportReplace {
+		if err := deleteAll(ctx, tx, audit, owner, summary); err != nil {
+			return err
+		}
+	}
+
+	for _, t := range archive.Tasks {
+		t.Overdue, t.DaysOverdue = false, 0
+		if mode == ImportAppend {
+			t.ID = 0
+		}
+		if err := importTask(ctx, tx, audit, &t, &summary.Tasks); err != nil {
+			return err
+		}
+	}
+
+	for _, l := range archive.Lists {
+		l.Name = strings.TrimSpace(l.Name)
+		l.Owner = owner
+		if mode == ImportAppend {
+			l.ID = 0
+		}
+		if err := importList(ctx, tx, audit, owner, &l, &summary.Lists); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// importTask создает задачу или, если задача с таким id уже есть, обновляет ее
+func importTask(ctx context.Context, tx Repository, audit auditor, t *Task, counts *ImportCounts) error {
+	if t.ID != 0 {
+		existing, err := tx.GetTaskByID(ctx, t.ID)
+		switch {
+		case err == nil:
+			if sameTask(existing, t) {
+				counts.Skipped++
+				return nil
+			}
+			if err := tx.UpdateTask(ctx, t); err != nil {
+				return fmt.Errorf("ошибка обновления задачи %d: %w", t.ID, err)
+			}
+			counts.Updated++
+			return audit.task(ctx, tx, AuditUpdate, existing, t)
+		case !errors.Is(err, ErrNotFound):
+			return err
+		}
+	}
+
+	if err := tx.Create(ctx, t); err != nil {
+		return fmt.Errorf("ошибка создания задачи: %w", err)
+	}
+	counts.Created++
+	return audit.task(ctx, tx, AuditCreate, nil, t)
+}
+
+// sameTask сообщает, что хранимые поля задач совпадают
+func sameTask(a, b *Task) bool {
+	return a.Date == b.Date && a.Title == b.Title && a.Comment == b.Comment &&
+		a.Repeat == b.Repeat && a.OverduePolicy == b.OverduePolicy
+}
+
+// importList создает список или заменяет список с тем же id. Изменять списки репозиторий
+// не умеет, поэтому измененный список удаляется и создается заново с прежним id.
+func importList(ctx context.Context, tx Repository, audit auditor, owner string, l *SavedList, counts *ImportCounts) error {
+	var replaced *SavedList
+	if l.ID != 0 {
+		existing, err := tx.GetSavedListByID(ctx, l.ID, owner)
+		switch {
+		case err == nil:
+			if existing.Name == l.Name && existing.Query == l.Query && existing.Owner == l.Owner {
+				counts.Skipped++
+				return nil
+			}
+			if err := tx.DeleteSavedList(ctx, l.ID); err != nil {
+				return fmt.Errorf("ошибка обновления списка %d: %w", l.ID, err)
+			}
+			replaced = existing
+		case !errors.Is(err, ErrNotFound):
+			return err
+		}
+	}
+
+	if err := tx.CreateSavedList(ctx, l); err != nil {
+		return fmt.Errorf("ошибка создания списка: %w", err)
+	}
+	if replaced != nil {
+		counts.Updated++
+		return audit.list(ctx, tx, AuditUpdate, replaced, l)
+	}
+	counts.Created++
+	return audit.list(ctx, tx, AuditCreate, nil, l)
+}
+
+// deleteAll удаляет все задачи и списки владельца owner перед импортом в режиме replace
+func deleteAll(ctx context.Context, tx Repository, audit auditor, owner string, summary *ImportSummary) error {
+	for {
+		tasks, err := tx.GetTasks(ctx, &ListQuery{Limit: exportPageSize})
+		if err != nil {
+			return fmt.Errorf("ошибка получения списка задач: %w", err)
+		}
+		if len(tasks) == 0 {
+			break
+		}
+		for _, t := range tasks {
+			if err := tx.DeleteTask(ctx, t.ID); err != nil {
+				return fmt.Errorf("ошибка удаления задачи %d: %w", t.ID, err)
+			}
+			if err := audit.task(ctx, tx, AuditDelete, &t, nil); err != nil {
+				return err
+			}
+		}
+		summary.Tasks.Deleted += len(tasks)
+	}
+
+	lists, err := tx.GetSavedLists(ctx, owner)
+	if err != nil {
+		return fmt.Errorf("ошибка получения списков: %w", err)
+	}
+	for _, l := range lists {
+		if err := tx.DeleteSavedList(ctx, l.ID); err != nil {
+			return fmt.Errorf("ошибка удаления списка %d: %w", l.ID, err)
+		}
+		if err := audit.list(ctx, tx, AuditDelete, &l, nil); err != nil {
+			return err
+		}
+	}
+	summary.Lists.Deleted = len(lists)
+	return nil
+}
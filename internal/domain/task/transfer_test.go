@@ -0,0 +1,287 @@
+package task_test
+
+import (
+	"context"
+	"errors"
+	"tasktracker/internal/domain/task"
+	"tasktracker/internal/i18n"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// seed сохраняет задачи и списки в обход сервиса, чтобы даты остались как есть
+func seed(t *testing.T, repo task.Repository, tasks []task.Task, lists []task.SavedList) {
+	t.Helper()
+	ctx := context.Background()
+	for i := range tasks {
+		require.NoError(t, repo.Create(ctx, &tasks[i]))
+	}
+	for i := range lists {
+		require.NoError(t, repo.CreateSavedList(ctx, &lists[i]))
+	}
+}
+
+func TestExportImportRoundTrip(t *testing.T) {
+	ctx := context.Background()
+	source, sourceRepo := newService(t)
+	seed(t, sourceRepo, []task.Task{
+		{Date: "20240110", Title: "Отчет", Comment: "квартальный", Repeat: "m 10"},
+		{Date: "20200101", Title: "Давняя", OverduePolicy: task.OverduePolicyKeep},
+	}, []task.SavedList{{Name: "Работа", Query: "title:отчет"}})
+
+	now := time.Date(2024, 1, 15, 12, 0, 0, 0, time.UTC)
+	archive, err := source.Export(ctx, now)
+	require.NoError(t, err)
+	assert.Equal(t, task.ArchiveFormat, archive.Format)
+	assert.Equal(t, task.ArchiveVersion, archive.Version)
+	assert.Equal(t, now, archive.ExportedAt)
+	require.Len(t, archive.Tasks, 2)
+	require.Len(t, archive.Lists, 1)
+
+	target, targetRepo := newService(t)
+	summary, err := target.Import(ctx, archive, task.ImportOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, task.ImportMerge, summary.Mode)
+	assert.True(t, summary.Applied)
+	assert.Empty(t, summary.Errors)
+	assert.Equal(t, task.ImportCounts{Created: 2}, summary.Tasks)
+	assert.Equal(t, task.ImportCounts{Created: 1}, summary.Lists)
+
+	// Задачи сохраняются с прежними id и датами, прошедшая дата не переносится
+	for _, want := range archive.Tasks {
+		got, err := targetRepo.GetTaskByID(ctx, want.ID)
+		require.NoError(t, err)
+		assert.Equal(t, want, *got)
+	}
+	exported, err := target.Export(ctx, now)
+	require.NoError(t, err)
+	assert.Equal(t, archive, exported)
+
+	// Повторный импорт того же файла ничего не меняет
+	summary, err = target.Import(ctx, archive, task.ImportOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, task.ImportCounts{Skipped: 2}, summary.Tasks)
+	assert.Equal(t, task.ImportCounts{Skipped: 1}, summary.Lists)
+}
+
+func TestExportPages(t *testing.T) {
+	ctx := context.Background()
+	service, repo := newService(t)
+
+	// Больше одной страницы репозитория, часть задач на одну дату
+	tasks := make([]task.Task, task.MaxListLimit+7)
+	for i := range tasks {
+		tasks[i] = task.Task{Date: task.FormatDate(time.Date(2024, 1, 1+i%40, 0, 0, 0, 0, time.UTC)), Title: "Задача"}
+	}
+	seed(t, repo, tasks, nil)
+
+	archive, err := service.Export(ctx, time.Now())
+	require.NoError(t, err)
+	require.Len(t, archive.Tasks, len(tasks))
+	seen := make(map[int64]bool)
+	for _, tk := range archive.Tasks {
+		assert.False(t, seen[tk.ID], "задача %d выгружена дважды", tk.ID)
+		seen[tk.ID] = true
+	}
+}
+
+func TestImportModes(t *testing.T) {
+	ctx := context.Background()
+	archive := &task.Archive{
+		Format:  task.ArchiveFormat,
+		Version: task.ArchiveVersion,
+		Tasks: []task.Task{
+			{ID: 1, Date: "20240101", Title: "Та же"},
+			{ID: 2, Date: "20240202", Title: "Изменена"},
+			{ID: 10, Date: "20240303", Title: "Новая"},
+		},
+		Lists: []task.SavedList{{ID: 1, Name: "Дом", Query: "repeat:none"}},
+	}
+	existing := func(t *testing.T) (*task.Service, task.Repository) {
+		service, repo := newService(t)
+		seed(t, repo, []task.Task{
+			{Date: "20240101", Title: "Та же"},
+			{Date: "20240101", Title: "Прежняя"},
+			{Date: "20240101", Title: "Лишняя"},
+		}, []task.SavedList{{Name: "Работа", Query: "title:отчет"}})
+		return service, repo
+	}
+	titles := func(t *testing.T, repo task.Repository) map[int64]string {
+		tasks, err := repo.GetTasks(ctx, &task.ListQuery{Limit: task.MaxListLimit})
+		require.NoError(t, err)
+		titles := make(map[int64]string)
+		for _, tk := range tasks {
+			titles[tk.ID] = tk.Title
+		}
+		return titles
+	}
+
+	t.Run("merge", func(t *testing.T) {
+		service, repo := existing(t)
+		summary, err := service.Import(ctx, archive, task.ImportOptions{Mode: task.ImportMerge})
+		require.NoError(t, err)
+		assert.Equal(t, task.ImportCounts{Created: 1, Updated: 1, Skipped: 1}, summary.Tasks)
+		assert.Equal(t, task.ImportCounts{Updated: 1}, summary.Lists)
+		assert.Equal(t, map[int64]string{1: "Та же", 2: "Изменена", 3: "Лишняя", 10: "Новая"}, titles(t, repo))
+
+		list, err := repo.GetSavedListByID(ctx, 1, "")
+		require.NoError(t, err)
+		assert.Equal(t, "Дом", list.Name)
+	})
+
+	t.Run("replace", func(t *testing.T) {
+		service, repo := existing(t)
+		summary, err := service.Import(ctx, archive, task.ImportOptions{Mode: task.ImportReplace})
+		require.NoError(t, err)
+		assert.Equal(t, task.ImportCounts{Created: 3, Deleted: 3}, summary.Tasks)
+		assert.Equal(t, task.ImportCounts{Created: 1, Deleted: 1}, summary.Lists)
+		assert.Equal(t, map[int64]string{1: "Та же", 2: "Изменена", 10: "Новая"}, titles(t, repo))
+	})
+
+	t.Run("append", func(t *testing.T) {
+		service, repo := existing(t)
+		summary, err := service.Import(ctx, archive, task.ImportOptions{Mode: task.ImportAppend})
+		require.NoError(t, err)
+		assert.Equal(t, task.ImportCounts{Created: 3}, summary.Tasks)
+		assert.Equal(t, task.ImportCounts{Created: 1}, summary.Lists)
+		assert.Len(t, titles(t, repo), 6)
+		assert.Equal(t, int64(1), archive.Tasks[0].ID, "выгрузка не изменяется")
+	})
+
+	t.Run("dry run", func(t *testing.T) {
+		service, repo := existing(t)
+		before := titles(t, repo)
+		summary, err := service.Import(ctx, archive, task.ImportOptions{Mode: task.ImportReplace, DryRun: true})
+		require.NoError(t, err)
+		assert.False(t, summary.Applied)
+		assert.Equal(t, task.ImportCounts{Created: 3, Deleted: 3}, summary.Tasks)
+		assert.Equal(t, before, titles(t, repo), "пробный импорт ничего не меняет")
+
+		// Идентификаторы, занятые в откаченной транзакции, снова свободны
+		created := task.Task{Date: "20240101", Title: "После"}
+		require.NoError(t, repo.Create(ctx, &created))
+		assert.Equal(t, int64(4), created.ID)
+	})
+}
+
+func TestImportListsBelongToCaller(t *testing.T) {
+	service, repo := newService(t, task.WithOwnerContext(ownerFromContext))
+	alice := context.WithValue(context.Background(), ownerKey{}, "alice")
+	archive := &task.Archive{
+		Format:  task.ArchiveFormat,
+		Version: task.ArchiveVersion,
+		Lists:   []task.SavedList{{ID: 1, Name: "Работа", Query: "title:отчет", Owner: "bob"}},
+	}
+
+	summary, err := service.Import(alice, archive, task.ImportOptions{Mode: task.ImportMerge})
+	require.NoError(t, err)
+	assert.Equal(t, task.ImportCounts{Created: 1}, summary.Lists)
+	list, err := repo.GetSavedListByID(context.Background(), 1, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, "alice", list.Owner, "владелец из файла не учитывается")
+	_, err = repo.GetSavedListByID(context.Background(), 1, "bob")
+	assert.ErrorIs(t, err, task.ErrNotFound)
+
+	// Повторный импорт того же файла ничего не меняет
+	summary, err = service.Import(alice, archive, task.ImportOptions{Mode: task.ImportMerge})
+	require.NoError(t, err)
+	assert.Equal(t, task.ImportCounts{Skipped: 1}, summary.Lists)
+
+	summary, err = service.Import(alice, archive, task.ImportOptions{Mode: task.ImportAppend})
+	require.NoError(t, err)
+	assert.Equal(t, task.ImportCounts{Created: 1}, summary.Lists)
+	lists, err := repo.GetSavedLists(context.Background(), "alice")
+	require.NoError(t, err)
+	require.Len(t, lists, 2)
+	assert.Equal(t, "alice", lists[1].Owner)
+}
+
+func TestImportRejectsInvalidItems(t *testing.T) {
+	ctx := context.Background()
+	service, repo := newService(t)
+	archive := &task.Archive{
+		Format:  task.ArchiveFormat,
+		Version: task.ArchiveVersion,
+		Tasks: []task.Task{
+			{ID: 1, Date: "20240101", Title: "Верная"},
+			{ID: 2, Date: "2024-01-01", Title: "Дата"},
+			{ID: 3, Date: "20240101"},
+			{ID: 1, Date: "20240101", Title: "Дубль"},
+			{Date: "20240101", Title: "Повтор", Repeat: "x 1"},
+		},
+		Lists: []task.SavedList{{ID: 1, Name: " ", Query: "repeat:none"}, {Name: "Запрос", Query: "date:вчера"}},
+	}
+
+	for _, dryRun := range []bool{true, false} {
+		summary, err := service.Import(ctx, archive, task.ImportOptions{DryRun: dryRun})
+		require.NoError(t, err)
+		assert.False(t, summary.Applied)
+		assert.Zero(t, summary.Tasks)
+
+		type item struct {
+			kind  string
+			index int
+			code  i18n.Code
+		}
+		var got []item
+		for _, e := range summary.Errors {
+			var domainErr *task.Error
+			var filterErr *task.FilterError
+			var code i18n.Code
+			switch {
+			case errors.As(e.Err, &domainErr):
+				code = domainErr.Code
+			case errors.As(e.Err, &filterErr):
+				code = filterErr.Code
+			}
+			got = append(got, item{e.Item, e.Index, code})
+		}
+		assert.Equal(t, []item{
+			{task.ImportItemTask, 1, i18n.TaskDateInvalid},
+			{task.ImportItemTask, 2, i18n.TaskTitleEmpty},
+			{task.ImportItemTask, 3, i18n.ImportIDDuplicate},
+			{task.ImportItemTask, 4, i18n.TaskRepeatInvalid},
+			{task.ImportItemList, 0, i18n.ListNameEmpty},
+			{task.ImportItemList, 1, i18n.FilterDateInvalid},
+		}, got)
+	}
+
+	// Ни одна запись не сохранена, даже верная
+	_, err := repo.GetTaskByID(ctx, 1)
+	assert.ErrorIs(t, err, task.ErrNotFound)
+
+	// В режиме append id не сравниваются: записи получат новые id
+	_, err = service.Import(ctx, &task.Archive{
+		Format:  task.ArchiveFormat,
+		Version: task.ArchiveVersion,
+		Tasks:   []task.Task{{ID: 1, Date: "20240101", Title: "a"}, {ID: 1, Date: "20240101", Title: "b"}},
+	}, task.ImportOptions{Mode: task.ImportAppend})
+	require.NoError(t, err)
+}
+
+func TestImportRejectsArchive(t *testing.T) {
+	ctx := context.Background()
+	service, _ := newService(t)
+
+	tests := []struct {
+		name    string
+		archive task.Archive
+		opts    task.ImportOptions
+		field   string
+	}{
+		{"чужой формат", task.Archive{Format: "todoist", Version: 1}, task.ImportOptions{}, "format"},
+		{"без версии", task.Archive{Format: task.ArchiveFormat}, task.ImportOptions{}, "version"},
+		{"новая версия", task.Archive{Format: task.ArchiveFormat, Version: task.ArchiveVersion + 1}, task.ImportOptions{}, "version"},
+		{"неизвестный режим", task.Archive{Format: task.ArchiveFormat, Version: 1}, task.ImportOptions{Mode: "upsert"}, "mode"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.Import(ctx, &tt.archive, tt.opts)
+			assert.ErrorIs(t, err, task.ErrValidation)
+			assert.Equal(t, tt.field, fieldOf(t, err))
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package i18n
+
+// Коды сообщений. Коды — часть API: их нельзя переименовывать, только добавлять новые.
+// Для каждого кода должен быть перевод во всех каталогах (проверяется тестом).
+
+// Общие ошибки запроса
+const (
+	Internal                 Code = "internal"
+	RequestMethodNotAllowed  Code = "request.method_not_allowed"
+	RequestTooLarge          Code = "request.too_large"
+	RequestInvalidJSON       Code = "request.invalid_json"
+	RequestIDMissing         Code = "request.id.missing"
+	RequestIDInvalid         Code = "request.id.invalid"
+	RequestLimitInvalid      Code = "request.limit.invalid"
+	RequestFormatInvalid     Code = "request.format.invalid"
+	RequestDelimiterInvalid  Code = "request.delimiter.invalid"
+	AuthRequired             Code = "auth.required"
+	AuthDisabled             Code = "auth.disabled"
+	AuthWrongPassword        Code = "auth.wrong_password"
+	SchemaVersionUnavailable Code = "schema.version_unavailable"
+)
+
+// Задачи
+const (
+	TaskNotFound                    Code = "task.not_found"
+	TaskIDInvalid                   Code = "task.id.invalid"
+	TaskIDTaken                     Code = "task.id.taken"
+	TaskTitleEmpty                  Code = "task.title.empty"
+	TaskDateInvalid                 Code = "task.date.invalid"
+	TaskRepeatInvalid               Code = "task.repeat.invalid"
+	TaskNextDateFailed              Code = "task.next_date.failed"
+	OverduePolicyNextRequiresRepeat Code = "task.overdue_policy.next_requires_repeat"
+	OverduePolicyUnknown            Code = "task.overdue_policy.unknown"
+)
+
+// Даты в формате YYYYMMDD
+const (
+	DateLength  Code = "date.length"
+	DateInvalid Code = "date.invalid"
+)
+
+// Правила повторения (ParseRepeatRule, NextDate)
+const (
+	RepeatEmpty              Code = "repeat.empty"
+	RepeatFormat             Code = "repeat.format"
+	RepeatUnsupportedType    Code = "repeat.unsupported_type"
+	RepeatYearExtraParams    Code = "repeat.year.extra_params"
+	RepeatDayMissing         Code = "repeat.day.missing"
+	RepeatDayInvalid         Code = "repeat.day.invalid"
+	RepeatDayOutOfRange      Code = "repeat.day.out_of_range"
+	RepeatWeekMissing        Code = "repeat.week.missing"
+	RepeatWeekDayInvalid     Code = "repeat.week.day_invalid"
+	RepeatWeekDayOutOfRange  Code = "repeat.week.day_out_of_range"
+	RepeatMonthMissing       Code = "repeat.month.missing"
+	RepeatMonthDayInvalid    Code = "repeat.month.day_invalid"
+	RepeatMonthDayOutOfRange Code = "repeat.month.day_out_of_range"
+	RepeatMonthNegativeDay   Code = "repeat.month.negative_day"
+	RepeatMonthInvalid       Code = "repeat.month.month_invalid"
+	RepeatMonthOutOfRange    Code = "repeat.month.month_out_of_range"
+	RepeatNoDate             Code = "repeat.no_date"
+)
+
+// Списки, постраничный вывод и поиск
+const (
+	ListNotFound     Code = "list.not_found"
+	ListIDInvalid    Code = "list.id.invalid"
+	ListIDTaken      Code = "list.id.taken"
+	ListNameEmpty    Code = "list.name.empty"
+	ListQueryEmpty   Code = "list.query.empty"
+	CursorInvalid    Code = "cursor.invalid"
+	LimitInvalid     Code = "limit.invalid"
+	SearchQueryEmpty Code = "search.query.empty"
+	SearchTermEmpty  Code = "search.term.empty"
+)
+
+// Названия встроенных списков
+const (
+	ListBuiltinToday    Code = "list.builtin.today"
+	ListBuiltinOverdue  Code = "list.builtin.overdue"
+	ListBuiltinUpcoming Code = "list.builtin.upcoming"
+	ListBuiltinNoRepeat Code = "list.builtin.norepeat"
+)
+
+// Резервные копии
+const (
+	BackupUnsupported  Code = "backup.unsupported"
+	BackupDirMissing   Code = "backup.dir_missing"
+	BackupAuthRequired Code = "backup.auth_required"
+)
+
+// Экспорт и импорт
+const (
+	ImportFormatUnsupported  Code = "import.format.unsupported"
+	ImportVersionUnsupported Code = "import.version.unsupported"
+	ImportModeUnknown        Code = "import.mode.unknown"
+	ImportIDDuplicate        Code = "import.id.duplicate"
+)
+
+// Импорт CSV
+const (
+	CSVEmpty         Code = "csv.empty"
+	CSVColumnUnknown Code = "csv.column.unknown"
+	CSVRowInvalid    Code = "csv.row.invalid"
+	CSVDateInvalid   Code = "csv.date.invalid"
+)
+
+// Импорт из других планировщиков
+const (
+	ImportSourceUnknown       Code = "import.source.unknown"
+	ImportFileInvalid         Code = "import.file.invalid"
+	ImportTitleMissing        Code = "import.title.missing"
+	ImportDateUnmapped        Code = "import.date.unmapped"
+	ImportRepeatUnmapped      Code = "import.repeat.unmapped"
+	ImportRepeatUntilUnmapped Code = "import.repeat.until_unmapped"
+)
+
+// Журнал изменений
+const (
+	AuditActionUnknown Code = "audit.action.unknown"
+	AuditTimeInvalid   Code = "audit.time.invalid"
+)
+
+// Язык фильтров (ParseFilter)
+const (
+	FilterSyntax              Code = "filter.syntax"
+	FilterUnclosedQuote       Code = "filter.unclosed_quote"
+	FilterEmptyValue          Code = "filter.empty_value"
+	FilterMissingValue        Code = "filter.missing_value"
+	FilterOperatorUnsupported Code = "filter.operator_unsupported"
+	FilterDateInvalid         Code = "filter.date_invalid"
+	FilterRepeatUnknown       Code = "filter.repeat_unknown"
+	FilterStateUnknown        Code = "filter.state_unknown"
+)
@@ -0,0 +1,96 @@
+package i18n
+
+var en = map[Code]string{
+	Internal:                 "internal server error",
+	RequestMethodNotAllowed:  "method not allowed",
+	RequestTooLarge:          "request body is too large",
+	RequestInvalidJSON:       "malformed request body",
+	RequestIDMissing:         "id is required",
+	RequestIDInvalid:         "invalid id",
+	RequestLimitInvalid:      "invalid limit value",
+	RequestFormatInvalid:     "invalid format value, expected json or csv",
+	RequestDelimiterInvalid:  `invalid delimiter value, expected ",", ";" or tab`,
+	AuthRequired:             "authentication required",
+	AuthDisabled:             "authentication is disabled",
+	AuthWrongPassword:        "wrong password",
+	SchemaVersionUnavailable: "cannot read schema version: {cause}",
+
+	TaskNotFound:                    "task not found",
+	TaskIDInvalid:                   "invalid task id",
+	TaskIDTaken:                     "task with id {id} already exists",
+	TaskTitleEmpty:                  "task title must not be empty",
+	TaskDateInvalid:                 "invalid date: {cause}",
+	TaskRepeatInvalid:               "invalid repeat rule: {cause}",
+	TaskNextDateFailed:              "cannot compute the next date: {cause}",
+	OverduePolicyNextRequiresRepeat: "overdue policy next requires a repeat rule",
+	OverduePolicyUnknown:            "unknown overdue policy: {policy}",
+
+	DateLength:  "invalid date length, expected 8 characters",
+	DateInvalid: "invalid date: {value}",
+
+	RepeatEmpty:              "empty repeat rule",
+	RepeatFormat:             "invalid rule format",
+	RepeatUnsupportedType:    "unsupported rule type: {type}",
+	RepeatYearExtraParams:    "rule y takes no parameters",
+	RepeatDayMissing:         "rule d requires a number of days",
+	RepeatDayInvalid:         "invalid number of days: {value}",
+	RepeatDayOutOfRange:      "number of days must be between 1 and {max}",
+	RepeatWeekMissing:        "rule w requires days of the week",
+	RepeatWeekDayInvalid:     "invalid day of the week: {value}",
+	RepeatWeekDayOutOfRange:  "day of the week must be between 1 and 7: {day}",
+	RepeatMonthMissing:       "rule m requires days and optionally months",
+	RepeatMonthDayInvalid:    "invalid day of the month: {value}",
+	RepeatMonthDayOutOfRange: "day of the month must be between 1 and 31, or -1, -2: {day}",
+	RepeatMonthNegativeDay:   "a negative day of the month can only be -1 or -2: {day}",
+	RepeatMonthInvalid:       "invalid month: {value}",
+	RepeatMonthOutOfRange:    "month must be between 1 and 12: {month}",
+	RepeatNoDate:             "no matching date found",
+
+	ListNotFound:     "list not found",
+	ListIDInvalid:    "invalid list id",
+	ListIDTaken:      "list with id {id} already exists",
+	ListNameEmpty:    "list name must not be empty",
+	ListQueryEmpty:   "list query must not be empty",
+	CursorInvalid:    "invalid cursor",
+	LimitInvalid:     "invalid number of tasks",
+	SearchQueryEmpty: "search query is required",
+	SearchTermEmpty:  "search term must contain letters or digits",
+
+	ListBuiltinToday:    "Today",
+	ListBuiltinOverdue:  "Overdue",
+	ListBuiltinUpcoming: "Next {days} days",
+	ListBuiltinNoRepeat: "Not recurring",
+
+	BackupUnsupported:  "backups are supported only for SQLite",
+	BackupDirMissing:   "backup directory is not configured (backup.dir)",
+	BackupAuthRequired: "backups over the API require authentication to be enabled (auth_secret)",
+
+	ImportFormatUnsupported:  `unknown file format "{format}", expected tasktracker`,
+	ImportVersionUnsupported: "unsupported format version {version}, versions up to {max} are supported",
+	ImportModeUnknown:        `unknown import mode "{mode}", expected merge, replace or append`,
+	ImportIDDuplicate:        "id {id} occurs in the file more than once",
+
+	CSVEmpty:         "CSV file is empty: no header row",
+	CSVColumnUnknown: `column "{column}" for field {field} is not in the header`,
+	CSVRowInvalid:    "row is not valid CSV: {cause}",
+	CSVDateInvalid:   `invalid date "{value}", expected YYYYMMDD or DD.MM.YYYY`,
+
+	ImportSourceUnknown:       `unknown import source "{source}", expected {sources}`,
+	ImportFileInvalid:         "file is not a valid {source} export: {cause}",
+	ImportTitleMissing:        "record has no title and was skipped",
+	ImportDateUnmapped:        `due date "{value}" was not recognized, the task is scheduled for today`,
+	ImportRepeatUnmapped:      `recurrence "{value}" has no matching repeat rule, the task was imported without repeating`,
+	ImportRepeatUntilUnmapped: "recurrence end {value} cannot be kept, the task will repeat indefinitely",
+
+	AuditActionUnknown: "unknown change action {action}, expected create, update, complete or delete",
+	AuditTimeInvalid:   "invalid time {value}: expected RFC 3339 or YYYYMMDD",
+
+	FilterSyntax:              `query error at position {position} ("{token}"): {reason}`,
+	FilterUnclosedQuote:       "unclosed quote",
+	FilterEmptyValue:          "empty value",
+	FilterMissingValue:        "no value for field {field}",
+	FilterOperatorUnsupported: "field {field} supports only the ':' operator",
+	FilterDateInvalid:         `invalid date "{value}", expected YYYYMMDD or DD.MM.YYYY`,
+	FilterRepeatUnknown:       `unknown repeat kind "{value}", expected any, none, d, w, m or y`,
+	FilterStateUnknown:        `unknown state "{value}", expected overdue, today or recurring`,
+}
@@ -0,0 +1,162 @@
+// Package i18n содержит коды ошибок и каталоги сообщений к ним.
+//
+// Код (например, repeat.week.day_out_of_range) стабилен и не зависит от языка:
+// по нему клиенты API обрабатывают ошибки. Текст сообщения берется из каталога
+// языка, выбранного по заголовку Accept-Language, и может содержать параметры
+// в фигурных скобках: "день недели должен быть от 1 до 7: {day}".
+package i18n
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Lang — язык каталога сообщений (базовый тег BCP 47)
+type Lang string
+
+const (
+	RU Lang = "ru"
+	EN Lang = "en"
+
+	// Default используется, когда клиент не указал поддерживаемый язык,
+	// и для текста ошибок в логах (error.Error())
+	Default = RU
+)
+
+// Code — стабильный код сообщения
+type Code string
+
+// Params — параметры сообщения, подставляются вместо {имя}
+type Params map[string]any
+
+// Localizer — значение, текст которого зависит от языка (например, ошибка с кодом)
+type Localizer interface {
+	Localize(lang Lang) string
+}
+
+var catalogs = map[Lang]map[Code]string{
+	RU: ru,
+	EN: en,
+}
+
+// Languages возвращает поддерживаемые языки по алфавиту
+func Languages() []Lang {
+	langs := make([]Lang, 0, len(catalogs))
+	for lang := range catalogs {
+		langs = append(langs, lang)
+	}
+	sort.Slice(langs, func(i, j int) bool { return langs[i] < langs[j] })
+	return langs
+}
+
+// Message возвращает текст сообщения code на языке lang с подставленными параметрами.
+// Если перевода нет, используется язык по умолчанию, а при отсутствии и его — сам код.
+func Message(lang Lang, code Code, params Params) string {
+	template, ok := catalogs[lang][code]
+	if !ok {
+		template, ok = catalogs[Default][code]
+	}
+	if !ok {
+		return string(code)
+	}
+	return render(template, lang, params)
+}
+
+// render подставляет параметры в шаблон; неизвестные {имена} остаются как есть
+func render(template string, lang Lang, params Params) string {
+	var b strings.Builder
+	for {
+		start := strings.IndexByte(template, '{')
+		if start < 0 {
+			break
+		}
+		end := strings.IndexByte(template[start:], '}')
+		if end < 0 {
+			break
+		}
+		end += start
+
+		b.WriteString(template[:start])
+		if v, ok := params[template[start+1:end]]; ok {
+			b.WriteString(Format(lang, v))
+		} else {
+			b.WriteString(template[start : end+1])
+		}
+		template = template[end+1:]
+	}
+	b.WriteString(template)
+	return b.String()
+}
+
+// Format переводит значение параметра в текст: Localizer — на языке lang,
+// ошибка — через Error(), остальное — через fmt
+func Format(lang Lang, v any) string {
+	switch v := v.(type) {
+	case Localizer:
+		return v.Localize(lang)
+	case error:
+		return v.Error()
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// Resolve возвращает параметры, пригодные для JSON: вложенные ошибки заменяются их текстом на языке lang
+func (p Params) Resolve(lang Lang) map[string]any {
+	if len(p) == 0 {
+		return nil
+	}
+	resolved := make(map[string]any, len(p))
+	for name, v := range p {
+		switch v.(type) {
+		case Localizer, error:
+			resolved[name] = Format(lang, v)
+		default:
+			resolved[name] = v
+		}
+	}
+	return resolved
+}
+
+// Cause возвращает первую ошибку среди параметров (обычно {cause})
+func (p Params) Cause() error {
+	names := make([]string, 0, len(p))
+	for name := range p {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err, ok := p[name].(error); ok {
+			return err
+		}
+	}
+	return nil
+}
+
+// Negotiate выбирает язык по заголовку Accept-Language с учетом весов q.
+// Регион не учитывается (en-US — это en); при отсутствии подходящего языка возвращается Default.
+func Negotiate(acceptLanguage string) Lang {
+	best, bestQ := Default, 0.0
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		base, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(tag)), "-")
+		if _, ok := catalogs[Lang(base)]; !ok {
+			continue
+		}
+
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if q > bestQ {
+			best, bestQ = Lang(base), q
+		}
+	}
+	return best
+}
@@ -0,0 +1,132 @@
+package i18n
+
+import (
+	"errors"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"regexp"
+	"sort"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// declaredCodes разбирает codes.go и возвращает все объявленные константы типа Code,
+// чтобы новый код без перевода не прошел тест, даже если его забыли добавить в каталог
+func declaredCodes(t *testing.T) []Code {
+	t.Helper()
+
+	file, err := parser.ParseFile(token.NewFileSet(), "codes.go", nil, 0)
+	require.NoError(t, err)
+
+	var codes []Code
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.CONST {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			vs := spec.(*ast.ValueSpec)
+			if ident, ok := vs.Type.(*ast.Ident); !ok || ident.Name != "Code" {
+				continue
+			}
+			for _, v := range vs.Values {
+				lit, ok := v.(*ast.BasicLit)
+				require.True(t, ok, "код должен быть строковым литералом")
+				value, err := strconv.Unquote(lit.Value)
+				require.NoError(t, err)
+				codes = append(codes, Code(value))
+			}
+		}
+	}
+	require.NotEmpty(t, codes)
+	return codes
+}
+
+var placeholder = regexp.MustCompile(`\{[a-z_]+\}`)
+
+func placeholders(template string) []string {
+	found := placeholder.FindAllString(template, -1)
+	sort.Strings(found)
+	return found
+}
+
+func TestEveryCodeHasTranslations(t *testing.T) {
+	codes := declaredCodes(t)
+
+	seen := make(map[Code]bool, len(codes))
+	for _, code := range codes {
+		assert.False(t, seen[code], "код %s объявлен дважды", code)
+		seen[code] = true
+	}
+
+	for _, lang := range Languages() {
+		catalog := catalogs[lang]
+		for _, code := range codes {
+			assert.NotEmpty(t, catalog[code], "нет перевода %s на %s", code, lang)
+			assert.Equal(t, placeholders(catalogs[Default][code]), placeholders(catalog[code]),
+				"параметры %s на %s не совпадают с языком по умолчанию", code, lang)
+		}
+		for code := range catalog {
+			assert.True(t, seen[code], "в каталоге %s есть необъявленный код %s", lang, code)
+		}
+	}
+}
+
+type localized string
+
+func (l localized) Localize(lang Lang) string {
+	return string(l) + "/" + string(lang)
+}
+
+func TestMessage(t *testing.T) {
+	assert.Equal(t, "день недели должен быть от 1 до 7: 9",
+		Message(RU, RepeatWeekDayOutOfRange, Params{"day": 9}))
+	assert.Equal(t, "day of the week must be between 1 and 7: 9",
+		Message(EN, RepeatWeekDayOutOfRange, Params{"day": 9}))
+
+	// Вложенные значения переводятся на тот же язык, ошибки — через Error()
+	assert.Equal(t, "invalid repeat rule: cause/en",
+		Message(EN, TaskRepeatInvalid, Params{"cause": localized("cause")}))
+	assert.Equal(t, "invalid date: boom",
+		Message(EN, TaskDateInvalid, Params{"cause": errors.New("boom")}))
+
+	// Без параметра подстановка остается как есть, неизвестный язык и код не теряют сообщения
+	assert.Equal(t, "invalid date: {cause}", Message(EN, TaskDateInvalid, nil))
+	assert.Equal(t, "задача не найдена", Message("de", TaskNotFound, nil))
+	assert.Equal(t, "no.such.code", Message(EN, "no.such.code", nil))
+}
+
+func TestParams(t *testing.T) {
+	cause := errors.New("boom")
+	params := Params{"day": 9, "cause": cause, "reason": localized("r")}
+
+	assert.Equal(t, cause, params.Cause())
+	assert.Nil(t, Params{"day": 9}.Cause())
+	assert.Equal(t, map[string]any{"day": 9, "cause": "boom", "reason": "r/en"}, params.Resolve(EN))
+	assert.Nil(t, Params(nil).Resolve(EN))
+}
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		header string
+		want   Lang
+	}{
+		{"", RU},
+		{"en", EN},
+		{"en-US,en;q=0.9", EN},
+		{"de-DE,de;q=0.9,en;q=0.8", EN},
+		{"en;q=0.5,ru;q=0.8", RU},
+		{"ru-RU, en-GB;q=0.7", RU},
+		{"EN-gb", EN},
+		{"fr, de", RU},
+		{"en;q=0", RU},
+		{"*", RU},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, Negotiate(tt.header), "Accept-Language: %q", tt.header)
+	}
+}
@@ -0,0 +1,96 @@
+package i18n
+
+var ru = map[Code]string{
+	Internal:                 "внутренняя ошибка сервера",
+	RequestMethodNotAllowed:  "метод не поддерживается",
+	RequestTooLarge:          "слишком большой запрос",
+	RequestInvalidJSON:       "неверный формат запроса",
+	RequestIDMissing:         "не указан идентификатор",
+	RequestIDInvalid:         "некорректный идентификатор",
+	RequestLimitInvalid:      "некорректное значение limit",
+	RequestFormatInvalid:     "некорректное значение format, ожидается json или csv",
+	RequestDelimiterInvalid:  `некорректное значение delimiter, ожидается ",", ";" или tab`,
+	AuthRequired:             "требуется аутентификация",
+	AuthDisabled:             "аутентификация отключена",
+	AuthWrongPassword:        "неверный пароль",
+	SchemaVersionUnavailable: "не удалось получить версию схемы: {cause}",
+
+	TaskNotFound:                    "задача не найдена",
+	TaskIDInvalid:                   "некорректный идентификатор задачи",
+	TaskIDTaken:                     "задача с идентификатором {id} уже существует",
+	TaskTitleEmpty:                  "заголовок задачи не может быть пустым",
+	TaskDateInvalid:                 "некорректная дата: {cause}",
+	TaskRepeatInvalid:               "некорректное правило повторения: {cause}",
+	TaskNextDateFailed:              "ошибка вычисления следующей даты: {cause}",
+	OverduePolicyNextRequiresRepeat: "политика next требует правила повторения",
+	OverduePolicyUnknown:            "неизвестная политика просрочки: {policy}",
+
+	DateLength:  "некорректная длина даты, ожидается 8 символов",
+	DateInvalid: "некорректный формат даты: {value}",
+
+	RepeatEmpty:              "пустое правило повторения",
+	RepeatFormat:             "некорректный формат правила",
+	RepeatUnsupportedType:    "неподдерживаемый тип правила: {type}",
+	RepeatYearExtraParams:    "правило y не требует дополнительных параметров",
+	RepeatDayMissing:         "правило d требует указания количества дней",
+	RepeatDayInvalid:         "некорректное количество дней: {value}",
+	RepeatDayOutOfRange:      "количество дней должно быть в диапазоне от 1 до {max}",
+	RepeatWeekMissing:        "правило w требует указания дней недели",
+	RepeatWeekDayInvalid:     "некорректный день недели: {value}",
+	RepeatWeekDayOutOfRange:  "день недели должен быть от 1 до 7: {day}",
+	RepeatMonthMissing:       "правило m требует указания дней и опционально месяцев",
+	RepeatMonthDayInvalid:    "некорректный день месяца: {value}",
+	RepeatMonthDayOutOfRange: "день месяца должен быть от 1 до 31 или -1, -2: {day}",
+	RepeatMonthNegativeDay:   "отрицательный день месяца может быть только -1 или -2: {day}",
+	RepeatMonthInvalid:       "некорректный месяц: {value}",
+	RepeatMonthOutOfRange:    "месяц должен быть от 1 до 12: {month}",
+	RepeatNoDate:             "не удалось найти подходящую дату",
+
+	ListNotFound:     "список не найден",
+	ListIDInvalid:    "некорректный идентификатор списка",
+	ListIDTaken:      "список с идентификатором {id} уже существует",
+	ListNameEmpty:    "название списка не может быть пустым",
+	ListQueryEmpty:   "запрос списка не может быть пустым",
+	CursorInvalid:    "некорректный курсор",
+	LimitInvalid:     "некорректное количество задач",
+	SearchQueryEmpty: "не указана строка поиска",
+	SearchTermEmpty:  "термин должен содержать буквы или цифры",
+
+	ListBuiltinToday:    "Сегодня",
+	ListBuiltinOverdue:  "Просроченные",
+	ListBuiltinUpcoming: "Ближайшие {days} дней",
+	ListBuiltinNoRepeat: "Без повторения",
+
+	BackupUnsupported:  "резервное копирование поддерживается только для SQLite",
+	BackupDirMissing:   "каталог резервных копий не задан (backup.dir)",
+	BackupAuthRequired: "резервные копии через API доступны только при включенной аутентификации (auth_secret)",
+
+	ImportFormatUnsupported:  `неизвестный формат файла "{format}", ожидается tasktracker`,
+	ImportVersionUnsupported: "неподдерживаемая версия формата {version}, поддерживаются версии до {max}",
+	ImportModeUnknown:        `неизвестный режим импорта "{mode}", ожидается merge, replace или append`,
+	ImportIDDuplicate:        "идентификатор {id} встречается в файле несколько раз",
+
+	CSVEmpty:         "файл CSV пуст: нет строки заголовка",
+	CSVColumnUnknown: `столбец "{column}" для поля {field} не найден в заголовке`,
+	CSVRowInvalid:    "строка не разбирается как CSV: {cause}",
+	CSVDateInvalid:   `некорректная дата "{value}", ожидается YYYYMMDD или DD.MM.YYYY`,
+
+	ImportSourceUnknown:       `неизвестный источник импорта "{source}", ожидается {sources}`,
+	ImportFileInvalid:         "файл не разбирается как выгрузка {source}: {cause}",
+	ImportTitleMissing:        "у записи нет названия, она пропущена",
+	ImportDateUnmapped:        `срок "{value}" не распознан, задача перенесена на сегодня`,
+	ImportRepeatUnmapped:      `повторение "{value}" не переводится в правило планировщика, задача перенесена без повторения`,
+	ImportRepeatUntilUnmapped: "окончание повторения {value} не переносится, задача будет повторяться без срока",
+
+	AuditActionUnknown: "вид изменения {action} неизвестен, допустимы create, update, complete, delete",
+	AuditTimeInvalid:   "некорректное время {value}: ожидается RFC 3339 или YYYYMMDD",
+
+	FilterSyntax:              `ошибка в запросе на позиции {position} ("{token}"): {reason}`,
+	FilterUnclosedQuote:       "незакрытая кавычка",
+	FilterEmptyValue:          "пустое значение",
+	FilterMissingValue:        "не указано значение для поля {field}",
+	FilterOperatorUnsupported: "поле {field} поддерживает только оператор ':'",
+	FilterDateInvalid:         `некорректная дата "{value}", ожидается YYYYMMDD или DD.MM.YYYY`,
+	FilterRepeatUnknown:       `неизвестный тип повторения "{value}", ожидается any, none, d, w, m или y`,
+	FilterStateUnknown:        `неизвестное состояние "{value}", ожидается overdue, today или recurring`,
+}
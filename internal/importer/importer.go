@@ -0,0 +1,216 @@
+// Package importer переносит задачи из других планировщиков: Todoist (JSON Sync/REST API
+// и CSV проекта), Taskwarrior (task export) и Trello (JSON доски).
+//
+// Адаптер источника переводит файл в выгрузку task.Archive, а Run загружает ее через
+// Service.Import в режиме append: задачи проверяются как при создании через API
+// и добавляются одной транзакцией. Все, что не удалось перенести как есть (правило
+// повторения без аналога, нераспознанный срок, запись без названия), попадает
+// в предупреждения результата. Проекты, разделы, метки и списки добавляются
+// в комментарий задачи как #метки, чтобы задачи можно было найти фильтром comment:.
+package importer
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sort"
+	"strings"
+	"tasktracker/internal/domain/task"
+	"tasktracker/internal/i18n"
+	"time"
+)
+
+// Adapter переводит файл одного источника в задачи планировщика
+type Adapter interface {
+	// Parse разбирает содержимое файла; now задает часовой пояс сроков и дату «сегодня».
+	// Ошибка означает, что файл целиком не подходит (task.ErrValidation с полем file).
+	Parse(data []byte, now time.Time) (*Result, error)
+}
+
+// adapters — поддерживаемые источники по названиям
+var adapters = map[string]Adapter{
+	"todoist":     todoist{},
+	"taskwarrior": taskwarrior{},
+	"trello":      trello{},
+}
+
+// Sources возвращает названия поддерживаемых источников по алфавиту
+func Sources() []string {
+	names := make([]string, 0, len(adapters))
+	for name := range adapters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Lookup возвращает адаптер источника по названию
+func Lookup(source string) (Adapter, error) {
+	adapter, ok := adapters[strings.ToLower(source)]
+	if !ok {
+		return nil, task.Invalid("source", i18n.ImportSourceUnknown, i18n.Params{
+			"source":  source,
+			"sources": strings.Join(Sources(), ", "),
+		})
+	}
+	return adapter, nil
+}
+
+// Warning — то, что не удалось перенести из записи источника
+type Warning struct {
+	Item  string // Идентификатор записи в источнике; для CSV — номер строки
+	Title string
+	Err   error // Ошибка валидации: поле задачи и код того, что не перенесено
+}
+
+// Result — задачи, переведенные из файла источника
+type Result struct {
+	Archive  *task.Archive
+	Items    []string // Идентификаторы записей источника для Archive.Tasks, по индексу
+	Skipped  int      // Выполненные, удаленные и архивные записи: они не переносятся
+	Warnings []Warning
+}
+
+// Report — итог импорта из источника
+type Report struct {
+	Source   string
+	Summary  *task.ImportSummary
+	Items    []string // См. Result.Items: по ним ошибки Summary.Errors сопоставляются записям источника
+	Skipped  int
+	Warnings []Warning
+}
+
+// Run разбирает файл источника source и добавляет задачи из него как новые.
+// При dryRun задачи только проверяются, а итог показывает, что было бы добавлено.
+// Ошибки в отдельных задачах возвращаются в Summary.Errors, и тогда ничего не сохраняется.
+func Run(ctx context.Context, service *task.Service, source string, r io.Reader, now time.Time, dryRun bool) (*Report, error) {
+	adapter, err := Lookup(source)
+	if err != nil {
+		return nil, err
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	result, err := adapter.Parse(data, now)
+	if err != nil {
+		return nil, err
+	}
+
+	summary, err := service.Import(ctx, result.Archive, task.ImportOptions{Mode: task.ImportAppend, DryRun: dryRun})
+	if err != nil {
+		return nil, err
+	}
+	return &Report{
+		Source:   strings.ToLower(source),
+		Summary:  summary,
+		Items:    result.Items,
+		Skipped:  result.Skipped,
+		Warnings: result.Warnings,
+	}, nil
+}
+
+// fileError сообщает, что файл не разбирается как выгрузка источника
+func fileError(source string, cause error) error {
+	return task.Invalid("file", i18n.ImportFileInvalid, i18n.Params{"source": source, "cause": cause})
+}
+
+// bom — метка порядка байтов UTF-8, с которой бывают сохранены выгрузки
+var bom = []byte{0xef, 0xbb, 0xbf}
+
+// firstByte возвращает первый значимый байт файла: по нему различаются JSON и CSV
+func firstByte(data []byte) byte {
+	data = bytes.TrimLeft(bytes.TrimPrefix(data, bom), " \t\r\n")
+	if len(data) == 0 {
+		return 0
+	}
+	return data[0]
+}
+
+// builder собирает задачи и предупреждения по мере разбора файла
+type builder struct {
+	now    time.Time
+	result *Result
+}
+
+func newBuilder(now time.Time) *builder {
+	return &builder{now: now, result: &Result{
+		Archive: &task.Archive{
+			Format:     task.ArchiveFormat,
+			Version:    task.ArchiveVersion,
+			ExportedAt: now.UTC(),
+			Tasks:      []task.Task{},
+			Lists:      []task.SavedList{},
+		},
+	}}
+}
+
+// today возвращает начало сегодняшнего дня в часовом поясе now
+func (b *builder) today() time.Time {
+	y, m, d := b.now.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, b.now.Location())
+}
+
+// add добавляет задачу записи item и предупреждения по ней. Задача без срока
+// переносится на сегодня, запись без названия пропускается с предупреждением.
+func (b *builder) add(item string, t task.Task, warnings ...error) {
+	t.Title = strings.TrimSpace(t.Title)
+	if t.Title == "" {
+		b.warn(item, "", task.Invalid("title", i18n.ImportTitleMissing, nil))
+		return
+	}
+	if t.Date == "" {
+		t.Date = task.FormatDate(b.today())
+	}
+	b.result.Archive.Tasks = append(b.result.Archive.Tasks, t)
+	b.result.Items = append(b.result.Items, item)
+	for _, err := range warnings {
+		b.warn(item, t.Title, err)
+	}
+}
+
+func (b *builder) warn(item, title string, err error) {
+	b.result.Warnings = append(b.result.Warnings, Warning{Item: item, Title: title, Err: err})
+}
+
+// skip учитывает запись, которая не переносится: выполненную, удаленную или архивную
+func (b *builder) skip() {
+	b.result.Skipped++
+}
+
+// date переводит срок в дату планировщика в часовом поясе now
+func (b *builder) date(t time.Time) string {
+	return task.FormatDate(t.In(b.now.Location()))
+}
+
+// repeatUnmapped — предупреждение о правиле повторения, которое не удалось перевести
+func repeatUnmapped(value string) error {
+	return task.Invalid("repeat", i18n.ImportRepeatUnmapped, i18n.Params{"value": value})
+}
+
+// dateUnmapped — предупреждение о нераспознанном сроке
+func dateUnmapped(value string) error {
+	return task.Invalid("date", i18n.ImportDateUnmapped, i18n.Params{"value": value})
+}
+
+// comment собирает комментарий из непустых частей, разделяя их пустой строкой
+func comment(parts ...string) string {
+	var kept []string
+	for _, p := range parts {
+		if p = strings.TrimSpace(p); p != "" {
+			kept = append(kept, p)
+		}
+	}
+	return strings.Join(kept, "\n\n")
+}
+
+// hashtags возвращает названия как #метки через пробел; пробелы в названии заменяются на _
+func hashtags(names ...string) string {
+	var tags []string
+	for _, name := range names {
+		if words := strings.Fields(name); len(words) > 0 {
+			tags = append(tags, "#"+strings.Join(words, "_"))
+		}
+	}
+	return strings.Join(tags, " ")
+}
@@ -0,0 +1,207 @@
+package importer
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"tasktracker/internal/domain/task"
+	"tasktracker/internal/i18n"
+	"tasktracker/internal/storage/memory"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// now — время импорта в тестах: среда, 17 января 2024
+var now = time.Date(2024, 1, 17, 12, 0, 0, 0, time.UTC)
+
+// parse разбирает файл источника и проверяет, что он подходит целиком
+func parse(t *testing.T, source, data string) *Result {
+	t.Helper()
+	adapter, err := Lookup(source)
+	require.NoError(t, err)
+	result, err := adapter.Parse([]byte(data), now)
+	require.NoError(t, err)
+	require.Len(t, result.Items, len(result.Archive.Tasks))
+	return result
+}
+
+// codes возвращает коды предупреждений по записям: item → коды
+func codes(warnings []Warning) map[string][]i18n.Code {
+	out := make(map[string][]i18n.Code)
+	for _, w := range warnings {
+		var e *task.Error
+		if errors.As(w.Err, &e) {
+			out[w.Item] = append(out[w.Item], e.Code)
+		}
+	}
+	return out
+}
+
+func TestTodoistSync(t *testing.T) {
+	result := parse(t, "todoist", `{
+		"projects": [{"id": "p1", "name": "Inbox", "inbox_project": true}, {"id": "p2", "name": "Работа"}],
+		"sections": [{"id": "s1", "name": "Отчеты по кварталу"}],
+		"items": [
+			{"id": "1", "content": "Отчет", "description": "для бухгалтерии", "project_id": "p2", "section_id": "s1",
+			 "labels": ["срочно"], "due": {"date": "2024-01-20", "string": "every 3 months", "is_recurring": true}},
+			{"id": "2", "content": "Позвонить", "project_id": "p1", "due": {"date": "2024-01-18T10:00:00"}},
+			{"id": "3", "content": "Созвон", "project_id": "p1",
+			 "due": {"date": "2024-01-19", "string": "every 3rd friday", "is_recurring": true}},
+			{"id": "4", "content": "Готово", "checked": true},
+			{"id": "5", "content": "Удалено", "is_deleted": true},
+			{"id": "6", "content": "Без срока"},
+			{"id": "7", "content": "  "}
+		]
+	}`)
+
+	assert.Equal(t, task.ArchiveFormat, result.Archive.Format)
+	assert.Equal(t, []task.Task{
+		{Date: "20240120", Title: "Отчет", Comment: "для бухгалтерии\n\n#Работа #Отчеты_по_кварталу #срочно", Repeat: "m 20 1,4,7,10"},
+		{Date: "20240118", Title: "Позвонить"},
+		{Date: "20240119", Title: "Созвон"},
+		{Date: "20240117", Title: "Без срока"},
+	}, result.Archive.Tasks)
+	assert.Equal(t, []string{"1", "2", "3", "6"}, result.Items)
+	assert.Equal(t, 2, result.Skipped)
+	assert.Equal(t, map[string][]i18n.Code{
+		"3": {i18n.ImportRepeatUnmapped},
+		"7": {i18n.ImportTitleMissing},
+	}, codes(result.Warnings))
+}
+
+func TestTodoistREST(t *testing.T) {
+	result := parse(t, "Todoist", `[
+		{"id": "1", "content": "Зарядка", "due": {"date": "2024-01-17", "string": "every day", "is_recurring": true}},
+		{"id": "2", "content": "Выполнена", "is_completed": true},
+		{"id": "3", "content": "Поздний вечер", "due": {"date": "2024-01-17T23:30:00Z"}}
+	]`)
+	assert.Equal(t, []task.Task{
+		{Date: "20240117", Title: "Зарядка", Repeat: "d 1"},
+		{Date: "20240117", Title: "Поздний вечер"},
+	}, result.Archive.Tasks)
+	assert.Equal(t, 1, result.Skipped)
+}
+
+func TestTodoistCSV(t *testing.T) {
+	result := parse(t, "todoist", "\ufeffTYPE,CONTENT,DESCRIPTION,PRIORITY,INDENT,AUTHOR,RESPONSIBLE,DATE,DATE_LANG,TIMEZONE\n"+
+		"task,Планерка,,4,1,,,every mon,en,Europe/Moscow\n"+
+		"note,Взять ноутбук,,,,,,,,\n"+
+		"note,И зарядку,,,,,,,,\n"+
+		",,,,,,,,,\n"+
+		"section,Дом,,,,,,,,\n"+
+		"task,Оплатить счета,квартира,4,1,,,2024-01-25,en,\n"+
+		"task,Полить цветы,,4,1,,,каждые 3 дня,ru,\n"+
+		"task,Спортзал,,4,1,,,every 3rd friday,en,\n"+
+		"task,Встреча,,4,1,,,next monday,en,\n")
+
+	assert.Equal(t, []task.Task{
+		{Date: "20240122", Title: "Планерка", Comment: "Взять ноутбук\n\nИ зарядку", Repeat: "w 1"},
+		{Date: "20240125", Title: "Оплатить счета", Comment: "квартира\n\n#Дом"},
+		{Date: "20240117", Title: "Полить цветы", Comment: "#Дом", Repeat: "d 3"},
+		{Date: "20240117", Title: "Спортзал", Comment: "#Дом"},
+		{Date: "20240117", Title: "Встреча", Comment: "#Дом"},
+	}, result.Archive.Tasks)
+	assert.Equal(t, []string{"2", "7", "8", "9", "10"}, result.Items)
+	assert.Equal(t, map[string][]i18n.Code{
+		"9":  {i18n.ImportRepeatUnmapped},
+		"10": {i18n.ImportDateUnmapped},
+	}, codes(result.Warnings))
+
+	_, err := todoist{}.Parse([]byte("title,date\nЗадача,20240101\n"), now)
+	assert.ErrorIs(t, err, task.ErrValidation)
+}
+
+func TestTaskwarrior(t *testing.T) {
+	result := parse(t, "taskwarrior", `[
+		{"uuid": "a", "description": "Отчет", "status": "recurring", "recur": "monthly", "due": "20240105T090000Z",
+		 "until": "20241231T000000Z", "project": "work.reports", "tags": ["office"]},
+		{"uuid": "a1", "description": "Отчет", "status": "completed", "parent": "a", "due": "20240105T090000Z"},
+		{"uuid": "a2", "description": "Отчет", "status": "pending", "parent": "a", "due": "20240205T090000Z"},
+		{"uuid": "b", "description": "Прочитать книгу", "status": "pending",
+		 "annotations": [{"entry": "20240101T100000Z", "description": "глава 3"}, {"description": "глава 4"}]},
+		{"uuid": "c", "description": "Полив", "status": "waiting", "recur": "3d", "due": "20240116T210000Z"},
+		{"uuid": "d", "description": "Раз в 5 месяцев", "status": "recurring", "recur": "5mo", "due": "20240120T000000Z"},
+		{"uuid": "e", "description": "Удалена", "status": "deleted"}
+	]`)
+
+	assert.Equal(t, []task.Task{
+		{Date: "20240205", Title: "Отчет", Comment: "#work.reports #office", Repeat: "m 5"},
+		{Date: "20240117", Title: "Прочитать книгу", Comment: "глава 3\nглава 4"},
+		{Date: "20240116", Title: "Полив", Repeat: "d 3"},
+		{Date: "20240120", Title: "Раз в 5 месяцев"},
+	}, result.Archive.Tasks)
+	assert.Equal(t, []string{"a", "b", "c", "d"}, result.Items)
+	assert.Equal(t, 2, result.Skipped)
+	assert.Equal(t, map[string][]i18n.Code{
+		"a": {i18n.ImportRepeatUntilUnmapped},
+		"d": {i18n.ImportRepeatUnmapped},
+	}, codes(result.Warnings))
+
+	// Старые версии выводят задачи по одной в строке
+	result = parse(t, "taskwarrior", `{"uuid": "x", "description": "Первая", "status": "pending"}
+{"uuid": "y", "description": "Вторая", "status": "pending"}`)
+	assert.Len(t, result.Archive.Tasks, 2)
+}
+
+func TestTrello(t *testing.T) {
+	result := parse(t, "trello", `{
+		"name": "Ремонт",
+		"lists": [{"id": "l1", "name": "To Do"}, {"id": "l2", "name": "Архив", "closed": true}],
+		"cards": [
+			{"id": "c1", "name": "Купить краску", "desc": "белая", "idList": "l1", "due": "2024-01-20T21:30:00.000Z",
+			 "labels": [{"name": "Магазин", "color": "green"}, {"name": "", "color": "red"}]},
+			{"id": "c2", "name": "Снять обои", "idList": "l1", "dueComplete": true},
+			{"id": "c3", "name": "Старое", "idList": "l2"},
+			{"id": "c4", "name": "Закрытая", "idList": "l1", "closed": true},
+			{"id": "c5", "name": "Выбрать плитку", "idList": "l1"}
+		],
+		"checklists": [
+			{"idCard": "c5", "name": "Варианты", "pos": 2, "checkItems": [
+				{"name": "матовая", "state": "incomplete", "pos": 2},
+				{"name": "глянцевая", "state": "complete", "pos": 1}
+			]}
+		]
+	}`)
+
+	assert.Equal(t, []task.Task{
+		{Date: "20240120", Title: "Купить краску", Comment: "белая\n\n#To_Do #Магазин #red"},
+		{Date: "20240117", Title: "Выбрать плитку", Comment: "Варианты\n- [x] глянцевая\n- [ ] матовая\n\n#To_Do"},
+	}, result.Archive.Tasks)
+	assert.Equal(t, 3, result.Skipped)
+	assert.Empty(t, result.Warnings)
+
+	_, err := trello{}.Parse([]byte(`[{"name": "не доска"}]`), now)
+	assert.ErrorIs(t, err, task.ErrValidation)
+}
+
+func TestRun(t *testing.T) {
+	ctx := context.Background()
+	repo := memory.NewRepository()
+	service := task.NewService(repo)
+
+	_, err := Run(ctx, service, "asana", strings.NewReader("{}"), now, false)
+	var domainErr *task.Error
+	require.ErrorAs(t, err, &domainErr)
+	assert.Equal(t, i18n.ImportSourceUnknown, domainErr.Code)
+
+	file := `[{"id": "1", "content": "Зарядка", "due": {"date": "2024-01-17", "string": "every day", "is_recurring": true}}]`
+	report, err := Run(ctx, service, "todoist", strings.NewReader(file), now, true)
+	require.NoError(t, err)
+	assert.False(t, report.Summary.Applied)
+	assert.Equal(t, 1, report.Summary.Tasks.Created)
+	tasks, err := repo.GetTasks(ctx, &task.ListQuery{Limit: 10})
+	require.NoError(t, err)
+	assert.Empty(t, tasks)
+
+	report, err = Run(ctx, service, "todoist", strings.NewReader(file), now, false)
+	require.NoError(t, err)
+	assert.True(t, report.Summary.Applied)
+	assert.Equal(t, "todoist", report.Source)
+	tasks, err = repo.GetTasks(ctx, &task.ListQuery{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+	assert.Equal(t, "d 1", tasks[0].Repeat)
+}
@@ -0,0 +1,362 @@
+package importer
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"tasktracker/internal/domain/task"
+	"time"
+	"unicode"
+)
+
+// Правила повторения других планировщиков переводятся в правила Repeat (d, w, m, y),
+// только если расписание совпадает полностью. Остальные правила не переводятся:
+// задача переносится без повторения, а адаптер сообщает об этом предупреждением.
+
+// unit — единица интервала повторения
+type unit int
+
+const (
+	unitDay unit = iota + 1
+	unitWeek
+	unitMonth
+	unitYear
+)
+
+// interval — n единиц u
+type interval struct {
+	u unit
+	n int
+}
+
+// maxDays — наибольший интервал правила d (см. task.ParseRepeatRule)
+const maxDays = 400
+
+// units — названия единиц интервала: английские и русские из Todoist и сокращения Taskwarrior
+var units = map[string]interval{
+	"d": {unitDay, 1}, "day": {unitDay, 1}, "days": {unitDay, 1},
+	"день": {unitDay, 1}, "дня": {unitDay, 1}, "дней": {unitDay, 1},
+
+	"w": {unitWeek, 1}, "wk": {unitWeek, 1}, "wks": {unitWeek, 1}, "week": {unitWeek, 1}, "weeks": {unitWeek, 1},
+	"неделя": {unitWeek, 1}, "неделю": {unitWeek, 1}, "недели": {unitWeek, 1}, "недель": {unitWeek, 1},
+	"fortnight": {unitWeek, 2}, "fortnights": {unitWeek, 2},
+
+	"mo": {unitMonth, 1}, "mos": {unitMonth, 1}, "mth": {unitMonth, 1}, "mths": {unitMonth, 1},
+	"month": {unitMonth, 1}, "months": {unitMonth, 1},
+	"месяц": {unitMonth, 1}, "месяца": {unitMonth, 1}, "месяцев": {unitMonth, 1},
+	"q": {unitMonth, 3}, "qtr": {unitMonth, 3}, "qtrs": {unitMonth, 3}, "quarter": {unitMonth, 3}, "quarters": {unitMonth, 3},
+	"квартал": {unitMonth, 3}, "квартала": {unitMonth, 3}, "кварталов": {unitMonth, 3},
+
+	"y": {unitYear, 1}, "yr": {unitYear, 1}, "yrs": {unitYear, 1}, "year": {unitYear, 1}, "years": {unitYear, 1},
+	"год": {unitYear, 1}, "года": {unitYear, 1}, "лет": {unitYear, 1},
+}
+
+// adverbs — правила из одного слова: "daily" в Todoist, "monthly" и "quarterly" в Taskwarrior
+var adverbs = map[string]interval{
+	"daily": {unitDay, 1}, "ежедневно": {unitDay, 1},
+	"weekly": {unitWeek, 1}, "еженедельно": {unitWeek, 1},
+	"biweekly": {unitWeek, 2}, "fortnightly": {unitWeek, 2},
+	"monthly": {unitMonth, 1}, "ежемесячно": {unitMonth, 1},
+	"bimonthly": {unitMonth, 2},
+	"quarterly": {unitMonth, 3}, "ежеквартально": {unitMonth, 3},
+	"semiannual": {unitMonth, 6}, "semiannually": {unitMonth, 6},
+	"annual": {unitYear, 1}, "annually": {unitYear, 1}, "yearly": {unitYear, 1}, "ежегодно": {unitYear, 1},
+	"biannual": {unitYear, 2}, "biyearly": {unitYear, 2},
+}
+
+// leaders — слова, с которых начинаются правила Todoist ("every!" и "after" считают
+// интервал от выполнения, в планировщике это то же самое)
+var leaders = map[string]bool{
+	"every": true, "every!": true, "after": true,
+	"каждый": true, "каждую": true, "каждое": true, "каждые": true, "каждых": true,
+}
+
+// weekdays — дни недели; 1 — понедельник, как в правиле w
+var weekdays = map[string]int{
+	"monday": 1, "mon": 1, "понедельник": 1, "пн": 1,
+	"tuesday": 2, "tue": 2, "tues": 2, "вторник": 2, "вт": 2,
+	"wednesday": 3, "wed": 3, "среда": 3, "среду": 3, "ср": 3,
+	"thursday": 4, "thu": 4, "thur": 4, "thurs": 4, "четверг": 4, "чт": 4,
+	"friday": 5, "fri": 5, "пятница": 5, "пятницу": 5, "пт": 5,
+	"saturday": 6, "sat": 6, "суббота": 6, "субботу": 6, "сб": 6,
+	"sunday": 7, "sun": 7, "воскресенье": 7, "вс": 7,
+}
+
+// months — названия месяцев для ежегодных правил вида "every jan 15"
+var months = map[string]time.Month{
+	"jan": time.January, "january": time.January, "января": time.January,
+	"feb": time.February, "february": time.February, "февраля": time.February,
+	"mar": time.March, "march": time.March, "марта": time.March,
+	"apr": time.April, "april": time.April, "апреля": time.April,
+	"may": time.May, "мая": time.May,
+	"jun": time.June, "june": time.June, "июня": time.June,
+	"jul": time.July, "july": time.July, "июля": time.July,
+	"aug": time.August, "august": time.August, "августа": time.August,
+	"sep": time.September, "sept": time.September, "september": time.September, "сентября": time.September,
+	"oct": time.October, "october": time.October, "октября": time.October,
+	"nov": time.November, "november": time.November, "ноября": time.November,
+	"dec": time.December, "december": time.December, "декабря": time.December,
+}
+
+// fillers — слова, которые не меняют смысла правила: "every month on the 15th"
+var fillers = map[string]bool{
+	"and": true, "и": true, "on": true, "the": true, "of": true, "day": true, "month": true,
+	"день": true, "число": true, "числа": true, "месяца": true,
+}
+
+// every возвращает правило для повторения через n единиц u, если его можно выразить:
+// d — до maxDays дней, m — для делителей 12 месяцев (в день месяца даты due), y — раз в год
+func every(iv interval, due time.Time) (string, bool) {
+	n := iv.n
+	if n < 1 {
+		return "", false
+	}
+	switch iv.u {
+	case unitDay:
+		if n <= maxDays {
+			return "d " + strconv.Itoa(n), true
+		}
+	case unitWeek:
+		if n*7 <= maxDays {
+			return "d " + strconv.Itoa(n*7), true
+		}
+	case unitMonth:
+		if n == 12 {
+			return "y", true
+		}
+		if 12%n != 0 {
+			return "", false
+		}
+		rule := "m " + monthDay(due)
+		if n > 1 {
+			var list []int
+			for m := int(due.Month()-1) % n; m < 12; m += n {
+				list = append(list, m+1)
+			}
+			rule += " " + joinInts(list)
+		}
+		return rule, true
+	case unitYear:
+		if n == 1 {
+			return "y", true
+		}
+	}
+	return "", false
+}
+
+// monthDay возвращает день месяца для правила m. 31-е число есть не в каждом месяце:
+// такие правила в других планировщиках переходят на последний день, то есть -1.
+func monthDay(due time.Time) string {
+	if due.Day() == 31 {
+		return "-1"
+	}
+	return strconv.Itoa(due.Day())
+}
+
+// parsePhrase переводит правило Todoist на английском или русском ("every 2 weeks",
+// "every mon, fri", "every 15th", "каждый день"); due — ближайшая дата задачи
+func parsePhrase(text string, due time.Time) (string, bool) {
+	words := phraseWords(text)
+	if len(words) == 0 {
+		return "", false
+	}
+	if iv, ok := adverbs[words[0]]; ok && len(words) == 1 {
+		return every(iv, due)
+	}
+	if !leaders[words[0]] || len(words) == 1 {
+		return "", false
+	}
+	words = words[1:]
+
+	n := 1
+	if words[0] == "other" {
+		n, words = 2, words[1:]
+	} else if v, err := strconv.Atoi(words[0]); err == nil {
+		n, words = v, words[1:]
+	}
+	if len(words) == 1 {
+		if iv, ok := units[words[0]]; ok {
+			iv.n *= n
+			return every(iv, due)
+		}
+	}
+	if n != 1 || len(words) == 0 {
+		return "", false
+	}
+
+	switch strings.Join(words, " ") {
+	case "weekday", "workday", "рабочий день":
+		return "w 1,2,3,4,5", true
+	case "weekend", "выходные":
+		return "w 6,7", true
+	}
+	if days, ok := weekdayList(words); ok {
+		return "w " + joinInts(days), true
+	}
+	if days, ok := monthDayList(words); ok {
+		return "m " + joinInts(days), true
+	}
+	if yearlyDate(words) {
+		return "y", true
+	}
+	return "", false
+}
+
+// isPhrase сообщает, что текст похож на правило повторения, даже если его не удалось перевести
+func isPhrase(text string) bool {
+	words := phraseWords(text)
+	if len(words) == 0 {
+		return false
+	}
+	_, adverb := adverbs[words[0]]
+	return adverb || leaders[words[0]]
+}
+
+// phraseWords разбивает правило на слова в нижнем регистре. Время дня ("at 9am", "в 10:00")
+// на даты не влияет и отбрасывается.
+func phraseWords(text string) []string {
+	s := strings.ToLower(strings.TrimSpace(text))
+	for _, sep := range []string{" at ", " в "} {
+		if i := strings.Index(s, sep); i >= 0 {
+			s = s[:i]
+		}
+	}
+	return strings.FieldsFunc(s, func(r rune) bool {
+		return unicode.IsSpace(r) || r == ','
+	})
+}
+
+// weekdayList разбирает перечисление дней недели: "mon, wed and fri"
+func weekdayList(words []string) ([]int, bool) {
+	var days []int
+	for _, w := range words {
+		if w == "and" || w == "и" {
+			continue
+		}
+		day, ok := weekdays[w]
+		if !ok {
+			return nil, false
+		}
+		days = append(days, day)
+	}
+	return days, len(days) > 0
+}
+
+// monthDayList разбирает дни месяца: "1st, 15th", "month on the 15th", "last day"
+func monthDayList(words []string) ([]int, bool) {
+	var days []int
+	for _, w := range words {
+		if fillers[w] {
+			continue
+		}
+		if w == "last" || w == "последний" || w == "последнее" {
+			days = append(days, -1)
+			continue
+		}
+		day, ok := ordinal(w)
+		if !ok || day < 1 || day > 31 {
+			return nil, false
+		}
+		days = append(days, day)
+	}
+	return days, len(days) > 0
+}
+
+// yearlyDate распознает ежегодную дату: "jan 15", "15th january", "15 января"
+func yearlyDate(words []string) bool {
+	if len(words) != 2 {
+		return false
+	}
+	for _, pair := range [][2]string{{words[0], words[1]}, {words[1], words[0]}} {
+		_, isMonth := months[pair[0]]
+		day, isDay := ordinal(pair[1])
+		if isMonth && isDay && day >= 1 && day <= 31 {
+			return true
+		}
+	}
+	return false
+}
+
+// ordinal разбирает число с необязательным окончанием: "15", "15th", "1st", "15-е", "15-го"
+func ordinal(word string) (int, bool) {
+	for _, suffix := range []string{"st", "nd", "rd", "th", "-е", "-го"} {
+		if s, ok := strings.CutSuffix(word, suffix); ok {
+			word = s
+			break
+		}
+	}
+	n, err := strconv.Atoi(word)
+	return n, err == nil
+}
+
+// parseRecur переводит значение recur из Taskwarrior: "daily", "weekdays", "3d", "2 weeks",
+// "quarterly" или длительность ISO 8601 ("P2W")
+func parseRecur(value string, due time.Time) (string, bool) {
+	s := strings.ToLower(strings.TrimSpace(value))
+	if s == "weekdays" {
+		return "w 1,2,3,4,5", true
+	}
+	if iv, ok := adverbs[s]; ok {
+		return every(iv, due)
+	}
+	if rest, ok := strings.CutPrefix(s, "p"); ok && len(rest) > 1 {
+		// В ISO 8601 M — месяцы; минут в повторении задач не бывает
+		if iv, ok := map[byte]interval{'d': {unitDay, 1}, 'w': {unitWeek, 1}, 'm': {unitMonth, 1}, 'y': {unitYear, 1}}[rest[len(rest)-1]]; ok {
+			if n, err := strconv.Atoi(rest[:len(rest)-1]); err == nil {
+				iv.n *= n
+				return every(iv, due)
+			}
+		}
+		return "", false
+	}
+
+	digits := strings.IndexFunc(s, func(r rune) bool { return r < '0' || r > '9' })
+	if digits < 0 {
+		return "", false
+	}
+	n := 1
+	if digits > 0 {
+		n, _ = strconv.Atoi(s[:digits])
+	}
+	iv, ok := units[strings.TrimSpace(s[digits:])]
+	if !ok {
+		return "", false
+	}
+	iv.n *= n
+	return every(iv, due)
+}
+
+// firstDate возвращает первую дату правила rule начиная с today. Для правил d и y
+// расписание отсчитывается от самой даты задачи, поэтому это today.
+func firstDate(rule string, today time.Time) string {
+	if strings.HasPrefix(rule, "w ") || strings.HasPrefix(rule, "m ") {
+		yesterday := today.AddDate(0, 0, -1)
+		if next, err := task.NextDate(yesterday, task.FormatDate(yesterday), rule); err == nil {
+			return next
+		}
+	}
+	return task.FormatDate(today)
+}
+
+// joinInts возвращает числа через запятую по возрастанию, без повторов; -1 и -2 — в конце
+func joinInts(values []int) string {
+	sorted := append([]int(nil), values...)
+	sort.Slice(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		if (a < 0) != (b < 0) {
+			return a > 0
+		}
+		if a < 0 {
+			return a > b
+		}
+		return a < b
+	})
+	parts := make([]string, 0, len(sorted))
+	for i, v := range sorted {
+		if i > 0 && v == sorted[i-1] {
+			continue
+		}
+		parts = append(parts, strconv.Itoa(v))
+	}
+	return strings.Join(parts, ",")
+}
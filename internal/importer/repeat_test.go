@@ -0,0 +1,94 @@
+package importer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePhrase(t *testing.T) {
+	due := time.Date(2024, 4, 15, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		phrase string
+		want   string // Пустая строка — правило не переводится
+	}{
+		{"every day", "d 1"},
+		{"Every day at 9am", "d 1"},
+		{"daily", "d 1"},
+		{"every! 3 days", "d 3"},
+		{"after 10 days", "d 10"},
+		{"every other day", "d 2"},
+		{"every week", "d 7"},
+		{"every 2 weeks", "d 14"},
+		{"every 60 weeks", ""},
+		{"every weekday", "w 1,2,3,4,5"},
+		{"every weekend", "w 6,7"},
+		{"every fri, mon and wed", "w 1,3,5"},
+		{"every month", "m 15"},
+		{"every 3 months", "m 15 1,4,7,10"},
+		{"every 5 months", ""},
+		{"every 12 months", "y"},
+		{"every 15th", "m 15"},
+		{"every 1st, 15th", "m 1,15"},
+		{"every month on the 20th", "m 20"},
+		{"every last day", "m -1"},
+		{"every year", "y"},
+		{"every jan 15", "y"},
+		{"every 2 years", ""},
+		{"every 3rd friday", ""},
+		{"every day until jun 1", ""},
+		{"каждый день", "d 1"},
+		{"каждые 3 дня", "d 3"},
+		{"каждый понедельник", "w 1"},
+		{"ежемесячно", "m 15"},
+		{"tomorrow", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.phrase, func(t *testing.T) {
+			got, ok := parsePhrase(tt.phrase, due)
+			assert.Equal(t, tt.want != "", ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParseRecur(t *testing.T) {
+	due := time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		recur string
+		want  string
+	}{
+		{"daily", "d 1"},
+		{"weekdays", "w 1,2,3,4,5"},
+		{"weekly", "d 7"},
+		{"biweekly", "d 14"},
+		{"3d", "d 3"},
+		{"2 weeks", "d 14"},
+		{"monthly", "m -1"},
+		{"quarterly", "m -1 1,4,7,10"},
+		{"semiannual", "m -1 1,7"},
+		{"yearly", "y"},
+		{"P2W", "d 14"},
+		{"P1M", "m -1"},
+		{"biannual", ""},
+		{"5mo", ""},
+		{"hourly", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.recur, func(t *testing.T) {
+			got, ok := parseRecur(tt.recur, due)
+			assert.Equal(t, tt.want != "", ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestFirstDate(t *testing.T) {
+	today := time.Date(2024, 1, 17, 0, 0, 0, 0, time.UTC) // Среда
+	assert.Equal(t, "20240117", firstDate("d 3", today))
+	assert.Equal(t, "20240117", firstDate("w 3", today))
+	assert.Equal(t, "20240119", firstDate("w 5", today))
+	assert.Equal(t, "20240201", firstDate("m 1", today))
+	assert.Equal(t, "20240117", firstDate("y", today))
+}
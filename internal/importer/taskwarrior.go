@@ -0,0 +1,139 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"tasktracker/internal/domain/task"
+	"tasktracker/internal/i18n"
+	"time"
+)
+
+// taskwarrior разбирает вывод task export: массив задач JSON или, как в старых версиях,
+// задачи по одной в строке.
+//
+// Повторяющаяся задача в Taskwarrior — шаблон (status recurring) и экземпляры с полем
+// parent. Переносится шаблон: с датой ближайшего невыполненного экземпляра и правилом
+// из recur, а экземпляры, чей шаблон есть в файле, пропускаются.
+type taskwarrior struct{}
+
+type taskwarriorTask struct {
+	UUID        string   `json:"uuid"`
+	Description string   `json:"description"`
+	Status      string   `json:"status"`
+	Due         string   `json:"due"`
+	Recur       string   `json:"recur"`
+	Until       string   `json:"until"`
+	Parent      string   `json:"parent"`
+	Project     string   `json:"project"`
+	Tags        []string `json:"tags"`
+	Annotations []struct {
+		Description string `json:"description"`
+	} `json:"annotations"`
+}
+
+const taskwarriorSource = "Taskwarrior"
+
+// taskwarriorTime — формат дат Taskwarrior, всегда в UTC
+const taskwarriorTime = "20060102T150405Z"
+
+func (taskwarrior) Parse(data []byte, now time.Time) (*Result, error) {
+	tasks, err := decodeTaskwarrior(data)
+	if err != nil {
+		return nil, fileError(taskwarriorSource, err)
+	}
+
+	// Ближайший невыполненный экземпляр каждого шаблона
+	templates := make(map[string]bool)
+	next := make(map[string]string)
+	for _, t := range tasks {
+		if t.Status == "recurring" {
+			templates[t.UUID] = true
+		}
+		if t.Parent != "" && t.Status == "pending" && t.Due != "" && (next[t.Parent] == "" || t.Due < next[t.Parent]) {
+			next[t.Parent] = t.Due
+		}
+	}
+
+	b := newBuilder(now)
+	for _, t := range tasks {
+		switch {
+		case t.Status == "completed" || t.Status == "deleted":
+			b.skip()
+		case t.Parent != "" && templates[t.Parent]:
+			// Экземпляр переносится вместе с шаблоном
+		default:
+			if due := next[t.UUID]; due != "" {
+				t.Due = due
+			}
+			b.taskwarriorTask(t)
+		}
+	}
+	return b.result, nil
+}
+
+// decodeTaskwarrior читает массив задач или поток объектов
+func decodeTaskwarrior(data []byte) ([]taskwarriorTask, error) {
+	var tasks []taskwarriorTask
+	if firstByte(data) == '[' {
+		err := json.Unmarshal(data, &tasks)
+		return tasks, err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	for {
+		var t taskwarriorTask
+		err := dec.Decode(&t)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, t)
+	}
+	if len(tasks) == 0 {
+		return nil, errors.New("нет задач")
+	}
+	return tasks, nil
+}
+
+func (b *builder) taskwarriorTask(t taskwarriorTask) {
+	notes := make([]string, 0, len(t.Annotations))
+	for _, a := range t.Annotations {
+		notes = append(notes, a.Description)
+	}
+	out := task.Task{
+		Title:   t.Description,
+		Comment: comment(strings.Join(notes, "\n"), hashtags(append([]string{t.Project}, t.Tags...)...)),
+	}
+
+	var warnings []error
+	due := b.today()
+	if t.Due != "" {
+		if parsed, err := time.Parse(taskwarriorTime, t.Due); err == nil {
+			due = parsed.In(b.now.Location())
+			out.Date = b.date(parsed)
+		} else {
+			warnings = append(warnings, dateUnmapped(t.Due))
+		}
+	}
+	if t.Recur != "" {
+		if rule, ok := parseRecur(t.Recur, due); ok {
+			out.Repeat = rule
+		} else {
+			warnings = append(warnings, repeatUnmapped(t.Recur))
+		}
+		// Окончания у правил Repeat нет: задача будет повторяться и после until
+		if out.Repeat != "" && t.Until != "" {
+			until := t.Until
+			if parsed, err := time.Parse(taskwarriorTime, until); err == nil {
+				until = b.date(parsed)
+			}
+			warnings = append(warnings, task.Invalid("repeat", i18n.ImportRepeatUntilUnmapped, i18n.Params{"value": until}))
+		}
+	}
+	b.add(t.UUID, out, warnings...)
+}
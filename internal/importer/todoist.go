@@ -0,0 +1,238 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"tasktracker/internal/domain/task"
+	"time"
+)
+
+// todoist разбирает выгрузки Todoist: ответ Sync API (объект с items, projects и sections),
+// массив задач REST API и CSV-шаблон проекта. Формат определяется по первому символу.
+type todoist struct{}
+
+type todoistDue struct {
+	Date        string `json:"date"`
+	String      string `json:"string"`
+	IsRecurring bool   `json:"is_recurring"`
+}
+
+type todoistItem struct {
+	ID          string      `json:"id"`
+	Content     string      `json:"content"`
+	Description string      `json:"description"`
+	Due         *todoistDue `json:"due"`
+	Checked     bool        `json:"checked"`
+	IsCompleted bool        `json:"is_completed"`
+	IsDeleted   bool        `json:"is_deleted"`
+	ProjectID   string      `json:"project_id"`
+	SectionID   string      `json:"section_id"`
+	Labels      []string    `json:"labels"`
+}
+
+type todoistNamed struct {
+	ID           string `json:"id"`
+	Name         string `json:"name"`
+	InboxProject bool   `json:"inbox_project"`
+}
+
+type todoistSync struct {
+	Items    []todoistItem  `json:"items"`
+	Projects []todoistNamed `json:"projects"`
+	Sections []todoistNamed `json:"sections"`
+}
+
+const todoistSource = "Todoist"
+
+func (todoist) Parse(data []byte, now time.Time) (*Result, error) {
+	b := newBuilder(now)
+	switch firstByte(data) {
+	case '{':
+		var sync todoistSync
+		if err := json.Unmarshal(data, &sync); err != nil {
+			return nil, fileError(todoistSource, err)
+		}
+		if sync.Items == nil {
+			return nil, fileError(todoistSource, errors.New("нет списка задач items"))
+		}
+		// Входящие есть у всех, метка #Inbox у каждой задачи ничего не дает
+		names := make(map[string]string)
+		for _, p := range sync.Projects {
+			if !p.InboxProject {
+				names[p.ID] = p.Name
+			}
+		}
+		for _, s := range sync.Sections {
+			names[s.ID] = s.Name
+		}
+		for _, item := range sync.Items {
+			b.todoistItem(item, names)
+		}
+	case '[':
+		var items []todoistItem
+		if err := json.Unmarshal(data, &items); err != nil {
+			return nil, fileError(todoistSource, err)
+		}
+		for _, item := range items {
+			b.todoistItem(item, nil)
+		}
+	default:
+		if err := b.todoistCSV(data); err != nil {
+			return nil, fileError(todoistSource, err)
+		}
+	}
+	return b.result, nil
+}
+
+// todoistItem переносит задачу из JSON; names — названия проектов и разделов по id
+func (b *builder) todoistItem(item todoistItem, names map[string]string) {
+	if item.Checked || item.IsCompleted || item.IsDeleted {
+		b.skip()
+		return
+	}
+
+	t := task.Task{
+		Title:   item.Content,
+		Comment: comment(item.Description, hashtags(append([]string{names[item.ProjectID], names[item.SectionID]}, item.Labels...)...)),
+	}
+	var warnings []error
+	if item.Due != nil && item.Due.Date != "" {
+		due, err := b.todoistDueDate(item.Due.Date)
+		if err != nil {
+			warnings = append(warnings, dateUnmapped(item.Due.Date))
+			due = b.today()
+		}
+		t.Date = task.FormatDate(due)
+		if item.Due.IsRecurring {
+			if rule, ok := parsePhrase(item.Due.String, due); ok {
+				t.Repeat = rule
+			} else {
+				warnings = append(warnings, repeatUnmapped(item.Due.String))
+			}
+		}
+	}
+	b.add(item.ID, t, warnings...)
+}
+
+// todoistDueDate разбирает срок: дату "2024-01-15", местное время "2024-01-15T10:00:00"
+// или время UTC "2024-01-15T10:00:00Z"
+func (b *builder) todoistDueDate(value string) (time.Time, error) {
+	if strings.HasSuffix(value, "Z") {
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return time.Time{}, err
+		}
+		t = t.In(b.now.Location())
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location()), nil
+	}
+	if len(value) > len(time.DateOnly) {
+		value = value[:len(time.DateOnly)]
+	}
+	return time.ParseInLocation(time.DateOnly, value, b.now.Location())
+}
+
+// todoistCSV переносит задачи из CSV-шаблона проекта. Строки с TYPE=task — задачи,
+// note — комментарии к задаче выше, section — раздел для задач ниже.
+// В столбце DATE срок записан так, как его ввели: дата или правило повторения.
+func (b *builder) todoistCSV(data []byte) error {
+	r := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, bom)))
+	r.FieldsPerRecord = -1
+
+	header, err := r.Read()
+	if errors.Is(err, io.EOF) {
+		return errors.New("файл пуст")
+	}
+	if err != nil {
+		return err
+	}
+	columns := make(map[string]int)
+	for i, name := range header {
+		columns[strings.ToUpper(strings.TrimSpace(name))] = i
+	}
+	for _, name := range []string{"TYPE", "CONTENT"} {
+		if _, ok := columns[name]; !ok {
+			return errors.New("нет столбца " + name)
+		}
+	}
+
+	var (
+		pending  *task.Task
+		item     string
+		warnings []error
+		section  string
+	)
+	flush := func() {
+		if pending != nil {
+			b.add(item, *pending, warnings...)
+			pending, warnings = nil, nil
+		}
+	}
+	for {
+		record, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		value := func(name string) string {
+			if i, ok := columns[name]; ok && i < len(record) {
+				return strings.TrimSpace(record[i])
+			}
+			return ""
+		}
+		line, _ := r.FieldPos(0)
+
+		switch strings.ToLower(value("TYPE")) {
+		case "task":
+			flush()
+			pending = &task.Task{Title: value("CONTENT"), Comment: value("DESCRIPTION")}
+			item = strconv.Itoa(line)
+			pending.Date, pending.Repeat, warnings = b.todoistDateText(value("DATE"))
+			if section != "" {
+				pending.Comment = comment(pending.Comment, hashtags(section))
+			}
+		case "note":
+			if pending != nil {
+				pending.Comment = comment(pending.Comment, value("CONTENT"))
+			}
+		case "section":
+			flush()
+			section = value("CONTENT")
+		}
+	}
+	flush()
+	return nil
+}
+
+// todoistDateText разбирает срок из CSV: дату, "today", "tomorrow" или правило повторения.
+// Для правила датой задачи становится его первая дата начиная с сегодня.
+func (b *builder) todoistDateText(text string) (date, repeat string, warnings []error) {
+	today := b.today()
+	switch strings.ToLower(text) {
+	case "":
+		return "", "", nil
+	case "today", "сегодня":
+		return task.FormatDate(today), "", nil
+	case "tomorrow", "завтра":
+		return task.FormatDate(today.AddDate(0, 0, 1)), "", nil
+	}
+	if t, err := time.Parse(time.DateOnly, text); err == nil {
+		return task.FormatDate(t), "", nil
+	}
+	if date, err := task.NormalizeDate(text); err == nil {
+		return date, "", nil
+	}
+	if rule, ok := parsePhrase(text, today); ok {
+		return firstDate(rule, today), rule, nil
+	}
+	if isPhrase(text) {
+		return "", "", []error{repeatUnmapped(text)}
+	}
+	return "", "", []error{dateUnmapped(text)}
+}
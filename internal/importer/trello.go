@@ -0,0 +1,120 @@
+package importer
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+	"strings"
+	"tasktracker/internal/domain/task"
+	"time"
+)
+
+// trello разбирает JSON доски Trello (меню доски → «Печать и экспорт» → JSON).
+// Карточка становится задачей: описание и чек-листы — комментарием, список и метки — #метками.
+// Повторений у карточек Trello нет, поэтому правила Repeat не заполняются.
+type trello struct{}
+
+type trelloBoard struct {
+	Lists []struct {
+		ID     string `json:"id"`
+		Name   string `json:"name"`
+		Closed bool   `json:"closed"`
+	} `json:"lists"`
+	Cards []struct {
+		ID          string `json:"id"`
+		Name        string `json:"name"`
+		Desc        string `json:"desc"`
+		Due         string `json:"due"`
+		DueComplete bool   `json:"dueComplete"`
+		Closed      bool   `json:"closed"`
+		IDList      string `json:"idList"`
+		Labels      []struct {
+			Name  string `json:"name"`
+			Color string `json:"color"`
+		} `json:"labels"`
+	} `json:"cards"`
+	Checklists []struct {
+		IDCard     string  `json:"idCard"`
+		Name       string  `json:"name"`
+		Pos        float64 `json:"pos"`
+		CheckItems []struct {
+			Name  string  `json:"name"`
+			State string  `json:"state"`
+			Pos   float64 `json:"pos"`
+		} `json:"checkItems"`
+	} `json:"checklists"`
+}
+
+const trelloSource = "Trello"
+
+func (trello) Parse(data []byte, now time.Time) (*Result, error) {
+	var board trelloBoard
+	if firstByte(data) != '{' {
+		return nil, fileError(trelloSource, errors.New("ожидается объект JSON доски"))
+	}
+	if err := json.Unmarshal(data, &board); err != nil {
+		return nil, fileError(trelloSource, err)
+	}
+	if board.Cards == nil {
+		return nil, fileError(trelloSource, errors.New("нет списка карточек cards"))
+	}
+
+	lists := make(map[string]string)
+	closedLists := make(map[string]bool)
+	for _, l := range board.Lists {
+		lists[l.ID] = l.Name
+		closedLists[l.ID] = l.Closed
+	}
+
+	sort.SliceStable(board.Checklists, func(i, j int) bool {
+		return board.Checklists[i].Pos < board.Checklists[j].Pos
+	})
+	checklists := make(map[string][]string)
+	for _, cl := range board.Checklists {
+		sort.SliceStable(cl.CheckItems, func(i, j int) bool {
+			return cl.CheckItems[i].Pos < cl.CheckItems[j].Pos
+		})
+		lines := []string{cl.Name}
+		for _, item := range cl.CheckItems {
+			mark := "[ ]"
+			if item.State == "complete" {
+				mark = "[x]"
+			}
+			lines = append(lines, "- "+mark+" "+item.Name)
+		}
+		checklists[cl.IDCard] = append(checklists[cl.IDCard], strings.Join(lines, "\n"))
+	}
+
+	b := newBuilder(now)
+	for _, card := range board.Cards {
+		if card.Closed || card.DueComplete || closedLists[card.IDList] {
+			b.skip()
+			continue
+		}
+
+		// Метка без названия в Trello — просто цвет
+		tags := []string{lists[card.IDList]}
+		for _, l := range card.Labels {
+			if l.Name != "" {
+				tags = append(tags, l.Name)
+			} else {
+				tags = append(tags, l.Color)
+			}
+		}
+		t := task.Task{
+			Title:   card.Name,
+			Comment: comment(card.Desc, strings.Join(checklists[card.ID], "\n\n"), hashtags(tags...)),
+		}
+
+		var warnings []error
+		if card.Due != "" {
+			if due, err := time.Parse(time.RFC3339, card.Due); err == nil {
+				t.Date = b.date(due)
+			} else {
+				warnings = append(warnings, dateUnmapped(card.Due))
+			}
+		}
+		b.add(card.ID, t, warnings...)
+	}
+	return b.result, nil
+}
@@ -0,0 +1,28 @@
+// Package logging настраивает структурированное логирование на основе log/slog.
+package logging
+
+import (
+	"io"
+	"log/slog"
+)
+
+// New создает логгер с указанным уровнем (debug, info, warn, error) и форматом (text или json).
+// Неизвестный уровень трактуется как info, неизвестный формат — как text;
+// корректность значений проверяет config.Validate.
+func New(w io.Writer, level, format string) *slog.Logger {
+	var lvl slog.Level
+	if err := lvl.UnmarshalText([]byte(level)); err != nil {
+		lvl = slog.LevelInfo
+	}
+
+	opts := &slog.HandlerOptions{Level: lvl}
+	if format == "json" {
+		return slog.New(slog.NewJSONHandler(w, opts))
+	}
+	return slog.New(slog.NewTextHandler(w, opts))
+}
+
+// Discard возвращает логгер, который ничего не пишет (удобно в тестах)
+func Discard() *slog.Logger {
+	return slog.New(slog.NewTextHandler(io.Discard, nil))
+}
@@ -0,0 +1,189 @@
+// Package metrics собирает метрики приложения и отдает их в текстовом формате Prometheus.
+package metrics
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+	"tasktracker/internal/domain/task"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "tasktracker"
+
+// Metrics хранит все метрики приложения в собственном реестре,
+// поэтому несколько экземпляров приложения в одном процессе не конфликтуют
+type Metrics struct {
+	registry *prometheus.Registry
+
+	httpRequests *prometheus.CounterVec
+	httpDuration *prometheus.HistogramVec
+	dbDuration   *prometheus.HistogramVec
+	completed    *prometheus.CounterVec
+	workerUp     *prometheus.GaugeVec
+	workerRuns   *prometheus.CounterVec
+	workerLast   *prometheus.GaugeVec
+}
+
+// New создает реестр с метриками приложения, среды выполнения Go и процесса
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "Количество обработанных HTTP-запросов.",
+		}, []string{"route", "method", "status"}),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "Длительность обработки HTTP-запросов.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"route", "method", "status"}),
+		dbDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "db_query_duration_seconds",
+			Help:      "Длительность вызовов методов репозитория задач.",
+			Buckets:   []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1},
+		}, []string{"method", "result"}),
+		completed: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "tasks_completed_total",
+			Help:      "Количество выполненных задач.",
+		}, []string{"kind"}),
+		workerUp: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "worker_up",
+			Help:      "1, если фоновая задача запущена.",
+		}, []string{"worker"}),
+		workerRuns: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "worker_runs_total",
+			Help:      "Количество запусков фоновой задачи.",
+		}, []string{"worker", "result"}),
+		workerLast: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Name:      "worker_last_success_timestamp_seconds",
+			Help:      "Время последнего успешного запуска фоновой задачи.",
+		}, []string{"worker"}),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpRequests, m.httpDuration, m.dbDuration, m.completed,
+		m.workerUp, m.workerRuns, m.workerLast,
+	)
+	return m
+}
+
+// Handler отдает метрики в текстовом формате Prometheus
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// Middleware считает запросы и их длительность в разрезе маршрута, метода и статуса.
+// route возвращает шаблон маршрута обработанного запроса (например, r.Pattern),
+// чтобы количество меток не зависело от URL.
+func (m *Metrics) Middleware(route func(*http.Request) string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			rec := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+
+			next.ServeHTTP(rec, r)
+
+			pattern := route(r)
+			if pattern == "" {
+				pattern = "unmatched"
+			}
+			status := strconv.Itoa(rec.status)
+			m.httpRequests.WithLabelValues(pattern, r.Method, status).Inc()
+			m.httpDuration.WithLabelValues(pattern, r.Method, status).Observe(time.Since(start).Seconds())
+		})
+	}
+}
+
+// TaskCompleted реализует task.Observer
+func (m *Metrics) TaskCompleted(t task.Task) {
+	kind := "once"
+	if t.Repeat != "" {
+		kind = "recurring"
+	}
+	m.completed.WithLabelValues(kind).Inc()
+}
+
+// WorkerStarted и WorkerStopped отмечают состояние фоновой задачи
+func (m *Metrics) WorkerStarted(name string) {
+	m.workerUp.WithLabelValues(name).Set(1)
+}
+
+func (m *Metrics) WorkerStopped(name string) {
+	m.workerUp.WithLabelValues(name).Set(0)
+}
+
+// WorkerRun учитывает очередной запуск фоновой задачи; фоновые задачи вызывают его
+// после каждого запуска (см. backup.Manager.Run)
+func (m *Metrics) WorkerRun(name string, err error) {
+	if err != nil {
+		m.workerRuns.WithLabelValues(name, "error").Inc()
+		return
+	}
+	m.workerRuns.WithLabelValues(name, "ok").Inc()
+	m.workerLast.WithLabelValues(name).SetToCurrentTime()
+}
+
+// RegisterTaskStats добавляет количество задач по состояниям; stats вызывается при каждом сборе метрик
+func (m *Metrics) RegisterTaskStats(stats func() (*task.Stats, error), logger *slog.Logger) {
+	m.registry.MustRegister(&taskStatsCollector{stats: stats, logger: logger})
+}
+
+var taskCountDesc = prometheus.NewDesc(
+	prometheus.BuildFQName(namespace, "", "tasks"),
+	"Количество задач по состояниям.",
+	[]string{"state"}, nil,
+)
+
+type taskStatsCollector struct {
+	stats  func() (*task.Stats, error)
+	logger *slog.Logger
+}
+
+func (c *taskStatsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- taskCountDesc
+}
+
+func (c *taskStatsCollector) Collect(ch chan<- prometheus.Metric) {
+	stats, err := c.stats()
+	if err != nil {
+		c.logger.Error("failed to collect task stats", "error", err)
+		ch <- prometheus.NewInvalidMetric(taskCountDesc, err)
+		return
+	}
+	ch <- prometheus.MustNewConstMetric(taskCountDesc, prometheus.GaugeValue, float64(stats.Upcoming), "upcoming")
+	ch <- prometheus.MustNewConstMetric(taskCountDesc, prometheus.GaugeValue, float64(stats.Overdue), "overdue")
+	ch <- prometheus.MustNewConstMetric(taskCountDesc, prometheus.GaugeValue, float64(stats.Recurring), "recurring")
+}
+
+// statusWriter запоминает код ответа
+type statusWriter struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	if !w.wroteHeader {
+		w.status = status
+		w.wroteHeader = true
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *statusWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,61 @@
+package metrics
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"tasktracker/internal/domain/task"
+	"tasktracker/internal/logging"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func scrape(t *testing.T, m *Metrics) string {
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	body, err := io.ReadAll(rec.Body)
+	require.NoError(t, err)
+	return string(body)
+}
+
+func TestMetrics(t *testing.T) {
+	m := New()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/task", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+	handler := m.Middleware(func(r *http.Request) string { return r.Pattern })(mux)
+	for i := 0; i < 2; i++ {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/task?id=1", nil))
+	}
+
+	m.TaskCompleted(task.Task{Repeat: "d 1"})
+	m.TaskCompleted(task.Task{})
+	m.WorkerStarted("backup")
+	m.WorkerRun("backup", nil)
+	m.WorkerRun("backup", errors.New("сбой"))
+	m.RegisterTaskStats(func() (*task.Stats, error) {
+		return &task.Stats{Upcoming: 3, Overdue: 1, Recurring: 2}, nil
+	}, logging.Discard())
+
+	body := scrape(t, m)
+	for _, line := range []string{
+		`tasktracker_http_requests_total{method="GET",route="/api/task",status="404"} 2`,
+		`tasktracker_http_request_duration_seconds_count{method="GET",route="/api/task",status="404"} 2`,
+		`tasktracker_tasks_completed_total{kind="once"} 1`,
+		`tasktracker_tasks_completed_total{kind="recurring"} 1`,
+		`tasktracker_tasks{state="upcoming"} 3`,
+		`tasktracker_tasks{state="overdue"} 1`,
+		`tasktracker_tasks{state="recurring"} 2`,
+		`tasktracker_worker_up{worker="backup"} 1`,
+		`tasktracker_worker_runs_total{result="error",worker="backup"} 1`,
+		`tasktracker_worker_runs_total{result="ok",worker="backup"} 1`,
+	} {
+		assert.Contains(t, body, line)
+	}
+}
@@ -0,0 +1,105 @@
+package metrics
+
+import (
+	"context"
+	"tasktracker/internal/domain/task"
+	"time"
+)
+
+// Repository измеряет длительность вызовов каждого метода репозитория задач
+type Repository struct {
+	next    task.Repository
+	metrics *Metrics
+}
+
+// InstrumentRepository оборачивает репозиторий сбором метрик
+func (m *Metrics) InstrumentRepository(next task.Repository) *Repository {
+	return &Repository{next: next, metrics: m}
+}
+
+// observe вызывается через defer; err читается по указателю уже после выполнения метода
+func (r *Repository) observe(method string, start time.Time, err *error) {
+	result := "ok"
+	if *err != nil {
+		result = "error"
+	}
+	r.metrics.dbDuration.WithLabelValues(method, result).Observe(time.Since(start).Seconds())
+}
+
+func (r *Repository) Create(ctx context.Context, t *task.Task) (err error) {
+	defer r.observe("Create", time.Now(), &err)
+	return r.next.Create(ctx, t)
+}
+
+func (r *Repository) GetTasks(ctx context.Context, q *task.ListQuery) (_ []task.Task, err error) {
+	defer r.observe("GetTasks", time.Now(), &err)
+	return r.next.GetTasks(ctx, q)
+}
+
+func (r *Repository) Search(ctx context.Context, q *task.SearchQuery) (_ []task.SearchResult, err error) {
+	defer r.observe("Search", time.Now(), &err)
+	return r.next.Search(ctx, q)
+}
+
+func (r *Repository) GetTaskByID(ctx context.Context, id int64) (_ *task.Task, err error) {
+	defer r.observe("GetTaskByID", time.Now(), &err)
+	return r.next.GetTaskByID(ctx, id)
+}
+
+func (r *Repository) UpdateTask(ctx context.Context, t *task.Task) (err error) {
+	defer r.observe("UpdateTask", time.Now(), &err)
+	return r.next.UpdateTask(ctx, t)
+}
+
+func (r *Repository) DeleteTask(ctx context.Context, id int64) (err error) {
+	defer r.observe("DeleteTask", time.Now(), &err)
+	return r.next.DeleteTask(ctx, id)
+}
+
+func (r *Repository) UpdateTaskDate(ctx context.Context, id int64, date string) (err error) {
+	defer r.observe("UpdateTaskDate", time.Now(), &err)
+	return r.next.UpdateTaskDate(ctx, id, date)
+}
+
+func (r *Repository) CreateSavedList(ctx context.Context, l *task.SavedList) (err error) {
+	defer r.observe("CreateSavedList", time.Now(), &err)
+	return r.next.CreateSavedList(ctx, l)
+}
+
+func (r *Repository) GetSavedLists(ctx context.Context, owner string) (_ []task.SavedList, err error) {
+	defer r.observe("GetSavedLists", time.Now(), &err)
+	return r.next.GetSavedLists(ctx, owner)
+}
+
+func (r *Repository) GetSavedListByID(ctx context.Context, id int64, owner string) (_ *task.SavedList, err error) {
+	defer r.observe("GetSavedListByID", time.Now(), &err)
+	return r.next.GetSavedListByID(ctx, id, owner)
+}
+
+func (r *Repository) DeleteSavedList(ctx context.Context, id int64) (err error) {
+	defer r.observe("DeleteSavedList", time.Now(), &err)
+	return r.next.DeleteSavedList(ctx, id)
+}
+
+func (r *Repository) GetStats(ctx context.Context, today string) (_ *task.Stats, err error) {
+	defer r.observe("GetStats", time.Now(), &err)
+	return r.next.GetStats(ctx, today)
+}
+
+func (r *Repository) AppendAudit(ctx context.Context, e *task.AuditEntry) (err error) {
+	defer r.observe("AppendAudit", time.Now(), &err)
+	return r.next.AppendAudit(ctx, e)
+}
+
+func (r *Repository) GetAudit(ctx context.Context, query *task.AuditQuery) (_ []task.AuditEntry, err error) {
+	defer r.observe("GetAudit", time.Now(), &err)
+	return r.next.GetAudit(ctx, query)
+}
+
+// WithTx измеряет всю единицу работы; вызовы внутри нее измеряются по отдельности
+func (r *Repository) WithTx(ctx context.Context, fn func(tx task.Repository) error) (err error) {
+	defer r.observe("WithTx", time.Now(), &err)
+	return r.next.WithTx(ctx, func(tx task.Repository) error {
+		return fn(&Repository{next: tx, metrics: r.metrics})
+	})
+}
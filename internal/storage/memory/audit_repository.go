@@ -0,0 +1,47 @@
+package memory
+
+import (
+	"context"
+	"maps"
+	"tasktracker/internal/domain/task"
+)
+
+func (r *Repository) AppendAudit(_ context.Context, e *task.AuditEntry) error {
+	defer r.write()()
+
+	e.ID = int64(len(r.audit)) + 1
+	entry := *e
+	entry.Changes = maps.Clone(e.Changes)
+	r.audit = append(r.audit, entry)
+	return nil
+}
+
+func (r *Repository) GetAudit(_ context.Context, query *task.AuditQuery) ([]task.AuditEntry, error) {
+	defer r.read()()
+
+	var entries []task.AuditEntry
+	for i := len(r.audit) - 1; i >= 0 && len(entries) < query.Limit; i-- {
+		e := r.audit[i]
+		if !auditMatches(&e, query) {
+			continue
+		}
+		e.Changes = maps.Clone(e.Changes)
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+func auditMatches(e *task.AuditEntry, q *task.AuditQuery) bool {
+	switch {
+	case q.Actor != "" && e.Actor != q.Actor,
+		q.Action != "" && e.Action != q.Action,
+		q.TaskID != 0 && e.TaskID != q.TaskID,
+		q.ListID != 0 && e.ListID != q.ListID,
+		q.RequestID != "" && e.RequestID != q.RequestID,
+		!q.Since.IsZero() && e.Time.Before(q.Since),
+		!q.Until.IsZero() && !e.Time.Before(q.Until),
+		q.Before != 0 && e.ID >= q.Before:
+		return false
+	}
+	return true
+}
@@ -0,0 +1,64 @@
+package memory
+
+import (
+	"strings"
+	"tasktracker/internal/domain/task"
+)
+
+// matchFilter вычисляет дерево фильтра для задачи так же, как условие WHERE в SQLite
+func matchFilter(expr task.FilterExpr, t task.Task) bool {
+	switch e := expr.(type) {
+	case task.AndExpr:
+		for _, term := range e.Terms {
+			if !matchFilter(term, t) {
+				return false
+			}
+		}
+		return true
+
+	case task.NotExpr:
+		return !matchFilter(e.Expr, t)
+
+	case task.TextMatch:
+		value := strings.ToLower(e.Value)
+		switch e.Field {
+		case task.TextFieldTitle:
+			return containsFold(t.Title, value)
+		case task.TextFieldComment:
+			return containsFold(t.Comment, value)
+		default:
+			return containsFold(t.Title, value) || containsFold(t.Comment, value)
+		}
+
+	case task.DateCompare:
+		switch e.Op {
+		case task.OpEq:
+			return t.Date == e.Date
+		case task.OpLt:
+			return t.Date < e.Date
+		case task.OpLe:
+			return t.Date <= e.Date
+		case task.OpGt:
+			return t.Date > e.Date
+		case task.OpGe:
+			return t.Date >= e.Date
+		}
+
+	case task.RepeatMatch:
+		switch e.Kind {
+		case task.RepeatAny:
+			return t.Repeat != ""
+		case task.RepeatNone:
+			return t.Repeat == ""
+		default:
+			kind := string(e.Kind)
+			return t.Repeat == kind || strings.HasPrefix(t.Repeat, kind+" ")
+		}
+	}
+	return false
+}
+
+// containsFold сообщает, содержит ли text подстроку lower, заданную в нижнем регистре
+func containsFold(text, lower string) bool {
+	return strings.Contains(strings.ToLower(text), lower)
+}
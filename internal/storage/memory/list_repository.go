@@ -0,0 +1,64 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"tasktracker/internal/domain/task"
+	"tasktracker/internal/i18n"
+)
+
+func (r *Repository) CreateSavedList(_ context.Context, l *task.SavedList) error {
+	defer r.write()()
+
+	if l.ID != 0 {
+		if _, ok := r.lists[l.ID]; ok {
+			return task.Conflict(i18n.ListIDTaken, i18n.Params{"id": l.ID})
+		}
+		r.listID = max(r.listID, l.ID)
+	} else {
+		r.listID++
+		l.ID = r.listID
+	}
+	r.lists[l.ID] = *l
+	return nil
+}
+
+func (r *Repository) GetSavedLists(_ context.Context, owner string) ([]task.SavedList, error) {
+	defer r.read()()
+
+	lists := make([]task.SavedList, 0, len(r.lists))
+	for _, l := range r.lists {
+		if visibleTo(l, owner) {
+			lists = append(lists, l)
+		}
+	}
+	sort.Slice(lists, func(i, j int) bool {
+		return lists[i].ID < lists[j].ID
+	})
+	return lists, nil
+}
+
+func (r *Repository) GetSavedListByID(_ context.Context, id int64, owner string) (*task.SavedList, error) {
+	defer r.read()()
+
+	l, ok := r.lists[id]
+	if !ok || !visibleTo(l, owner) {
+		return nil, task.NotFound(i18n.ListNotFound)
+	}
+	return &l, nil
+}
+
+func (r *Repository) DeleteSavedList(_ context.Context, id int64) error {
+	defer r.write()()
+
+	if _, ok := r.lists[id]; !ok {
+		return task.NotFound(i18n.ListNotFound)
+	}
+	delete(r.lists, id)
+	return nil
+}
+
+// visibleTo сообщает, виден ли список владельцу owner: свои списки и общие без владельца
+func visibleTo(l task.SavedList, owner string) bool {
+	return l.Owner == owner || l.Owner == ""
+}
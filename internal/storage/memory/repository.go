@@ -0,0 +1,225 @@
+// Package memory реализует task.Repository в памяти процесса.
+//
+// Хранилище используется в тестах сервиса и в режиме -ephemeral: данные живут,
+// пока работает процесс. Семантика фильтра совпадает с SQLite и PostgreSQL: текст —
+// подстрока без учета регистра, выдача упорядочена по дате, затем по id.
+package memory
+
+import (
+	"context"
+	"maps"
+	"sort"
+	"strings"
+	"sync"
+	"tasktracker/internal/domain/task"
+	"tasktracker/internal/i18n"
+)
+
+// Repository безопасен для одновременного использования из нескольких горутин.
+// Задачи возвращаются копиями, поэтому вызывающий код не может изменить хранимые данные.
+type Repository struct {
+	mu     *sync.RWMutex // nil у репозитория транзакции: блокировку держит WithTx
+	tasks  map[int64]task.Task
+	lists  map[int64]task.SavedList
+	taskID int64             // Последний выданный id задачи
+	listID int64             // Последний выданный id списка
+	audit  []task.AuditEntry // Журнал изменений; id записи — ее номер, начиная с 1
+}
+
+func NewRepository() *Repository {
+	return &Repository{
+		mu:    &sync.RWMutex{},
+		tasks: make(map[int64]task.Task),
+		lists: make(map[int64]task.SavedList),
+	}
+}
+
+// read и write берут блокировку и возвращают функцию ее снятия для defer
+func (r *Repository) read() func() {
+	if r.mu == nil {
+		return func() {}
+	}
+	r.mu.RLock()
+	return r.mu.RUnlock
+}
+
+func (r *Repository) write() func() {
+	if r.mu == nil {
+		return func() {}
+	}
+	r.mu.Lock()
+	return r.mu.Unlock
+}
+
+// WithTx выполняет fn над копией данных под блокировкой записи и при успехе
+// подменяет данные копией. Транзакции выполняются строго по очереди.
+func (r *Repository) WithTx(_ context.Context, fn func(tx task.Repository) error) error {
+	if r.mu == nil {
+		return fn(r)
+	}
+	defer r.write()()
+
+	tx := &Repository{
+		tasks:  maps.Clone(r.tasks),
+		lists:  maps.Clone(r.lists),
+		taskID: r.taskID,
+		listID: r.listID,
+		// Емкость по длине: записи транзакции не попадут в общий массив до успеха
+		audit: r.audit[:len(r.audit):len(r.audit)],
+	}
+	if err := fn(tx); err != nil {
+		return err
+	}
+	r.tasks, r.lists, r.taskID, r.listID, r.audit = tx.tasks, tx.lists, tx.taskID, tx.listID, tx.audit
+	return nil
+}
+
+func (r *Repository) Create(_ context.Context, t *task.Task) error {
+	defer r.write()()
+
+	if t.ID != 0 {
+		if _, ok := r.tasks[t.ID]; ok {
+			return task.Conflict(i18n.TaskIDTaken, i18n.Params{"id": t.ID})
+		}
+		r.taskID = max(r.taskID, t.ID)
+	} else {
+		r.taskID++
+		t.ID = r.taskID
+	}
+	r.tasks[t.ID] = stored(*t)
+	return nil
+}
+
+// stored оставляет только хранимые поля задачи: вычисляемые признаки просрочки не сохраняются
+func stored(t task.Task) task.Task {
+	return task.Task{
+		ID:            t.ID,
+		Date:          t.Date,
+		Title:         t.Title,
+		Comment:       t.Comment,
+		Repeat:        t.Repeat,
+		OverduePolicy: t.OverduePolicy,
+	}
+}
+
+// sorted возвращает задачи, подходящие под match, в порядке (date, id)
+func (r *Repository) sorted(match func(task.Task) bool) []task.Task {
+	var tasks []task.Task
+	for _, t := range r.tasks {
+		if match(t) {
+			tasks = append(tasks, t)
+		}
+	}
+	sort.Slice(tasks, func(i, j int) bool {
+		if tasks[i].Date != tasks[j].Date {
+			return tasks[i].Date < tasks[j].Date
+		}
+		return tasks[i].ID < tasks[j].ID
+	})
+	return tasks
+}
+
+func (r *Repository) GetTasks(_ context.Context, query *task.ListQuery) ([]task.Task, error) {
+	defer r.read()()
+
+	tasks := r.sorted(func(t task.Task) bool {
+		if query.After != nil {
+			if t.Date < query.After.Date || t.Date == query.After.Date && t.ID <= query.After.ID {
+				return false
+			}
+		}
+		return query.Filter == nil || matchFilter(query.Filter, t)
+	})
+	if len(tasks) > query.Limit {
+		tasks = tasks[:query.Limit]
+	}
+	return tasks, nil
+}
+
+// Search ищет подстроки без учета регистра: каждый термин должен встречаться в заголовке
+// или комментарии. Ранжирования нет, результаты упорядочены по дате.
+func (r *Repository) Search(_ context.Context, query *task.SearchQuery) ([]task.SearchResult, error) {
+	defer r.read()()
+
+	tasks := r.sorted(func(t task.Task) bool {
+		for _, term := range query.Terms {
+			value := strings.ToLower(term.Text)
+			if !containsFold(t.Title, value) && !containsFold(t.Comment, value) {
+				return false
+			}
+		}
+		return true
+	})
+	if len(tasks) > query.Limit {
+		tasks = tasks[:query.Limit]
+	}
+
+	results := make([]task.SearchResult, 0, len(tasks))
+	for _, t := range tasks {
+		results = append(results, task.SearchResult{
+			Task:             t,
+			TitleHighlight:   task.Highlight(t.Title, query.Terms),
+			CommentHighlight: task.Highlight(t.Comment, query.Terms),
+		})
+	}
+	return results, nil
+}
+
+func (r *Repository) GetTaskByID(_ context.Context, id int64) (*task.Task, error) {
+	defer r.read()()
+
+	t, ok := r.tasks[id]
+	if !ok {
+		return nil, task.NotFound(i18n.TaskNotFound)
+	}
+	return &t, nil
+}
+
+func (r *Repository) UpdateTask(_ context.Context, t *task.Task) error {
+	defer r.write()()
+
+	if _, ok := r.tasks[t.ID]; !ok {
+		return task.NotFound(i18n.TaskNotFound)
+	}
+	r.tasks[t.ID] = stored(*t)
+	return nil
+}
+
+func (r *Repository) DeleteTask(_ context.Context, id int64) error {
+	defer r.write()()
+
+	if _, ok := r.tasks[id]; !ok {
+		return task.NotFound(i18n.TaskNotFound)
+	}
+	delete(r.tasks, id)
+	return nil
+}
+
+func (r *Repository) UpdateTaskDate(_ context.Context, id int64, newDate string) error {
+	defer r.write()()
+
+	t, ok := r.tasks[id]
+	if !ok {
+		return task.NotFound(i18n.TaskNotFound)
+	}
+	t.Date = newDate
+	r.tasks[id] = t
+	return nil
+}
+
+func (r *Repository) GetStats(_ context.Context, today string) (*task.Stats, error) {
+	defer r.read()()
+
+	var stats task.Stats
+	for _, t := range r.tasks {
+		if t.Date >= today {
+			stats.Upcoming++
+		} else {
+			stats.Overdue++
+		}
+		if t.Repeat != "" {
+			stats.Recurring++
+		}
+	}
+	return &stats, nil
+}
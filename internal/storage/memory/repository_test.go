@@ -0,0 +1,13 @@
+package memory
+
+import (
+	"tasktracker/internal/domain/task"
+	"tasktracker/internal/storage/repotest"
+	"testing"
+)
+
+func TestRepository(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) task.Repository {
+		return NewRepository()
+	})
+}
@@ -0,0 +1,126 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"tasktracker/internal/domain/task"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// auditRow — запись audit_log в том виде, в каком она хранится
+type auditRow struct {
+	ID        int64         `db:"id"`
+	CreatedAt time.Time     `db:"created_at"`
+	Actor     string        `db:"actor"`
+	Action    string        `db:"action"`
+	TaskID    sql.NullInt64 `db:"task_id"`
+	ListID    sql.NullInt64 `db:"list_id"`
+	RequestID string        `db:"request_id"`
+	Changes   []byte        `db:"changes"`
+}
+
+// nullID записывает отсутствующий идентификатор как NULL
+func nullID(id int64) sql.NullInt64 {
+	return sql.NullInt64{Int64: id, Valid: id != 0}
+}
+
+func (r *Repository) AppendAudit(ctx context.Context, e *task.AuditEntry) (err error) {
+	query := r.db.Rebind(`
+        INSERT INTO audit_log (created_at, actor, action, task_id, list_id, request_id, changes)
+        VALUES (?, ?, ?, ?, ?, ?, ?)
+        RETURNING id`)
+
+	ctx, span := startSpan(ctx, "AppendAudit", query)
+	defer span.end(&err)
+
+	changes, err := json.Marshal(e.Changes)
+	if err != nil {
+		return fmt.Errorf("ошибка кодирования изменений: %w", err)
+	}
+	row := r.q().QueryRowxContext(ctx, query, e.Time, e.Actor, string(e.Action),
+		nullID(e.TaskID), nullID(e.ListID), e.RequestID, string(changes))
+	if err := row.Scan(&e.ID); err != nil {
+		return fmt.Errorf("ошибка записи в журнал изменений: %w", err)
+	}
+
+	span.rows = 1
+	return nil
+}
+
+func (r *Repository) GetAudit(ctx context.Context, query *task.AuditQuery) (_ []task.AuditEntry, err error) {
+	var conditions []string
+	var args []interface{}
+
+	if query.Actor != "" {
+		conditions = append(conditions, "actor = ?")
+		args = append(args, query.Actor)
+	}
+	if query.Action != "" {
+		conditions = append(conditions, "action = ?")
+		args = append(args, string(query.Action))
+	}
+	if query.TaskID != 0 {
+		conditions = append(conditions, "task_id = ?")
+		args = append(args, query.TaskID)
+	}
+	if query.ListID != 0 {
+		conditions = append(conditions, "list_id = ?")
+		args = append(args, query.ListID)
+	}
+	if query.RequestID != "" {
+		conditions = append(conditions, "request_id = ?")
+		args = append(args, query.RequestID)
+	}
+	if !query.Since.IsZero() {
+		conditions = append(conditions, "created_at >= ?")
+		args = append(args, query.Since)
+	}
+	if !query.Until.IsZero() {
+		conditions = append(conditions, "created_at < ?")
+		args = append(args, query.Until)
+	}
+	if query.Before != 0 {
+		conditions = append(conditions, "id < ?")
+		args = append(args, query.Before)
+	}
+
+	queryStr := "SELECT id, created_at, actor, action, task_id, list_id, request_id, changes FROM audit_log"
+	if len(conditions) > 0 {
+		queryStr += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	queryStr += " ORDER BY id DESC LIMIT ?"
+	args = append(args, query.Limit)
+	queryStr = r.db.Rebind(queryStr)
+
+	ctx, span := startSpan(ctx, "GetAudit", queryStr)
+	defer span.end(&err)
+
+	var rows []auditRow
+	if err := sqlx.SelectContext(ctx, r.q(), &rows, queryStr, args...); err != nil {
+		return nil, fmt.Errorf("ошибка выборки журнала изменений: %w", err)
+	}
+
+	entries := make([]task.AuditEntry, 0, len(rows))
+	for _, row := range rows {
+		e := task.AuditEntry{
+			ID:        row.ID,
+			Time:      row.CreatedAt.UTC(),
+			Actor:     row.Actor,
+			Action:    task.AuditAction(row.Action),
+			TaskID:    row.TaskID.Int64,
+			ListID:    row.ListID.Int64,
+			RequestID: row.RequestID,
+		}
+		if err := json.Unmarshal(row.Changes, &e.Changes); err != nil {
+			return nil, fmt.Errorf("некорректные изменения в записи журнала %d: %w", row.ID, err)
+		}
+		entries = append(entries, e)
+	}
+	span.rows = int64(len(entries))
+	return entries, nil
+}
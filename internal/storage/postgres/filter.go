@@ -0,0 +1,96 @@
+package postgres
+
+import (
+	"fmt"
+	"strings"
+	"tasktracker/internal/domain/task"
+	"unicode"
+)
+
+// likeEscaper экранирует спецсимволы LIKE, чтобы значение искалось как обычная подстрока
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// compileFilter транслирует дерево фильтра в SQL-условие с плейсхолдерами "?"
+// (перед выполнением запрос проходит через Rebind). Текст ищется как подстрока без учета
+// регистра, как и в остальных хранилищах; поисковый вектор используется только в Search.
+func compileFilter(expr task.FilterExpr) (string, []interface{}, error) {
+	switch e := expr.(type) {
+	case task.AndExpr:
+		parts := make([]string, 0, len(e.Terms))
+		var args []interface{}
+		for _, term := range e.Terms {
+			sql, termArgs, err := compileFilter(term)
+			if err != nil {
+				return "", nil, err
+			}
+			parts = append(parts, sql)
+			args = append(args, termArgs...)
+		}
+		return "(" + strings.Join(parts, " AND ") + ")", args, nil
+
+	case task.NotExpr:
+		sql, args, err := compileFilter(e.Expr)
+		if err != nil {
+			return "", nil, err
+		}
+		return "NOT " + sql, args, nil
+
+	case task.TextMatch:
+		pattern := likePattern(e.Value)
+		switch e.Field {
+		case task.TextFieldTitle:
+			return `(lower(title) LIKE ? ESCAPE '\')`, []interface{}{pattern}, nil
+		case task.TextFieldComment:
+			return `(lower(comment) LIKE ? ESCAPE '\')`, []interface{}{pattern}, nil
+		case task.TextFieldAny:
+			return `(lower(title) LIKE ? ESCAPE '\' OR lower(comment) LIKE ? ESCAPE '\')`, []interface{}{pattern, pattern}, nil
+		}
+		return "", nil, fmt.Errorf("неподдерживаемое текстовое поле: %s", e.Field)
+
+	case task.DateCompare:
+		switch e.Op {
+		case task.OpEq, task.OpLt, task.OpLe, task.OpGt, task.OpGe:
+			return fmt.Sprintf("(date %s ?)", e.Op), []interface{}{e.Date}, nil
+		}
+		return "", nil, fmt.Errorf("неподдерживаемый оператор сравнения: %s", e.Op)
+
+	case task.RepeatMatch:
+		switch e.Kind {
+		case task.RepeatAny:
+			return "(repeat != '')", nil, nil
+		case task.RepeatNone:
+			return "(repeat = '')", nil, nil
+		case task.RepeatDaily, task.RepeatWeekly, task.RepeatMonthly, task.RepeatYearly:
+			return "(repeat = ? OR repeat LIKE ?)", []interface{}{string(e.Kind), string(e.Kind) + " %"}, nil
+		}
+		return "", nil, fmt.Errorf("неподдерживаемый тип повторения: %s", e.Kind)
+	}
+
+	return "", nil, fmt.Errorf("неподдерживаемое условие фильтра: %T", expr)
+}
+
+// likePattern строит шаблон LIKE для регистронезависимого поиска подстроки
+func likePattern(value string) string {
+	return "%" + likeEscaper.Replace(strings.ToLower(value)) + "%"
+}
+
+// tsQuery строит выражение для to_tsquery: термины соединяются через &, слова фразы —
+// через <-> (следуют друг за другом), префикс помечается :*. В выражение попадают только
+// буквы и цифры, поэтому пользовательский ввод не интерпретируется как синтаксис tsquery.
+// Пустая строка означает, что в терминах нет ни одного слова.
+func tsQuery(terms []task.SearchTerm) string {
+	parts := make([]string, 0, len(terms))
+	for _, term := range terms {
+		words := strings.FieldsFunc(strings.ToLower(term.Text), func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})
+		if len(words) == 0 {
+			continue
+		}
+		if term.Prefix {
+			words[len(words)-1] += ":*"
+		}
+		parts = append(parts, "("+strings.Join(words, " <-> ")+")")
+	}
+	return strings.Join(parts, " & ")
+}
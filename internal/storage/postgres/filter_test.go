@@ -0,0 +1,28 @@
+package postgres
+
+import (
+	"tasktracker/internal/domain/task"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTsQuery(t *testing.T) {
+	tests := []struct {
+		terms []task.SearchTerm
+		want  string
+	}{
+		{[]task.SearchTerm{{Text: "Молоко"}}, "(молоко)"},
+		{[]task.SearchTerm{{Text: "хлеб", Prefix: true}, {Text: "отчет"}}, "(хлеб:*) & (отчет)"},
+		{[]task.SearchTerm{{Text: "купить свежий хлеб"}}, "(купить <-> свежий <-> хлеб)"},
+		{[]task.SearchTerm{{Text: "a'b & c:*"}}, "(a <-> b <-> c)"},
+		{[]task.SearchTerm{{Text: "!!!"}}, ""},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, tsQuery(tt.terms), "%+v", tt.terms)
+	}
+}
+
+func TestLikePattern(t *testing.T) {
+	assert.Equal(t, `%100\%\_готово\\%`, likePattern(`100%_ГОТОВО\`))
+}
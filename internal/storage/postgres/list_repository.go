@@ -0,0 +1,110 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"tasktracker/internal/domain/task"
+	"tasktracker/internal/i18n"
+
+	"github.com/jmoiron/sqlx"
+)
+
+func (r *Repository) CreateSavedList(ctx context.Context, l *task.SavedList) (err error) {
+	if l.ID != 0 {
+		return r.createSavedListWithID(ctx, l)
+	}
+
+	query := r.db.Rebind(`
+        INSERT INTO saved_lists (name, query, owner)
+        VALUES (?, ?, ?)
+        RETURNING id`)
+
+	ctx, span := startSpan(ctx, "CreateSavedList", query)
+	defer span.end(&err)
+
+	row := r.q().QueryRowxContext(ctx, query, l.Name, l.Query, l.Owner)
+	if err := row.Scan(&l.ID); err != nil {
+		return fmt.Errorf("ошибка при создании списка: %w", err)
+	}
+
+	span.rows = 1
+	return nil
+}
+
+// createSavedListWithID сохраняет список с заданным идентификатором (импорт)
+func (r *Repository) createSavedListWithID(ctx context.Context, l *task.SavedList) (err error) {
+	query := r.db.Rebind(`INSERT INTO saved_lists (id, name, query, owner) VALUES (?, ?, ?, ?)`)
+
+	ctx, span := startSpan(ctx, "CreateSavedList", query)
+	defer span.end(&err)
+
+	return r.WithTx(ctx, func(tx task.Repository) error {
+		repo := tx.(*Repository)
+		_, err := repo.q().ExecContext(ctx, query, l.ID, l.Name, l.Query, l.Owner)
+		if isUniqueViolation(err) {
+			return task.Conflict(i18n.ListIDTaken, i18n.Params{"id": l.ID})
+		}
+		if err != nil {
+			return fmt.Errorf("ошибка при создании списка: %w", err)
+		}
+		span.rows = 1
+		return repo.syncSequence(ctx, "saved_lists")
+	})
+}
+
+func (r *Repository) GetSavedLists(ctx context.Context, owner string) (_ []task.SavedList, err error) {
+	query := r.db.Rebind(`SELECT id, name, query, owner FROM saved_lists WHERE owner IN (?, '') ORDER BY id ASC`)
+
+	ctx, span := startSpan(ctx, "GetSavedLists", query)
+	defer span.end(&err)
+
+	var lists []task.SavedList
+	if err := sqlx.SelectContext(ctx, r.q(), &lists, query, owner); err != nil {
+		return nil, fmt.Errorf("ошибка выборки списков: %w", err)
+	}
+	span.rows = int64(len(lists))
+	return lists, nil
+}
+
+func (r *Repository) GetSavedListByID(ctx context.Context, id int64, owner string) (_ *task.SavedList, err error) {
+	query := r.db.Rebind(`SELECT id, name, query, owner FROM saved_lists WHERE id = ? AND owner IN (?, '')`)
+
+	ctx, span := startSpan(ctx, "GetSavedListByID", query)
+	defer span.end(&err)
+
+	var list task.SavedList
+	err = sqlx.GetContext(ctx, r.q(), &list, query, id, owner)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, task.NotFound(i18n.ListNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения списка: %w", err)
+	}
+	span.rows = 1
+	return &list, nil
+}
+
+func (r *Repository) DeleteSavedList(ctx context.Context, id int64) (err error) {
+	query := r.db.Rebind("DELETE FROM saved_lists WHERE id = ?")
+
+	ctx, span := startSpan(ctx, "DeleteSavedList", query)
+	defer span.end(&err)
+
+	result, err := r.q().ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("ошибка удаления списка: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("ошибка получения количества удаленных строк: %w", err)
+	}
+	span.rows = rows
+	if rows == 0 {
+		return task.NotFound(i18n.ListNotFound)
+	}
+
+	return nil
+}
@@ -0,0 +1,317 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"tasktracker/internal/domain/task"
+	"tasktracker/internal/i18n"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// taskColumns — колонки задачи в таблице tasks
+const taskColumns = "id, date, title, comment, repeat, overdue_policy"
+
+// Repository хранит задачи в PostgreSQL. Запросы пишутся с плейсхолдерами "?",
+// как в хранилище SQLite, и перед выполнением переводятся в $1, $2, … через Rebind.
+type Repository struct {
+	db *DB
+	tx *sqlx.Tx // Открытая транзакция у репозитория, переданного в WithTx
+}
+
+func NewRepository(db *DB) *Repository {
+	return &Repository{
+		db: db,
+	}
+}
+
+func (r *Repository) Create(ctx context.Context, t *task.Task) (err error) {
+	if t.ID != 0 {
+		return r.createWithID(ctx, t)
+	}
+
+	query := r.db.Rebind(`
+        INSERT INTO tasks (date, title, comment, repeat, overdue_policy)
+        VALUES (?, ?, ?, ?, ?)
+        RETURNING id`)
+
+	ctx, span := startSpan(ctx, "Create", query)
+	defer span.end(&err)
+
+	row := r.q().QueryRowxContext(ctx, query, t.Date, t.Title, t.Comment, t.Repeat, t.OverduePolicy)
+	if err := row.Scan(&t.ID); err != nil {
+		return fmt.Errorf("ошибка при создании задачи: %w", err)
+	}
+	span.rows = 1
+	return nil
+}
+
+// createWithID сохраняет задачу с заданным идентификатором (импорт)
+func (r *Repository) createWithID(ctx context.Context, t *task.Task) (err error) {
+	query := r.db.Rebind(`
+        INSERT INTO tasks (id, date, title, comment, repeat, overdue_policy)
+        VALUES (?, ?, ?, ?, ?, ?)`)
+
+	ctx, span := startSpan(ctx, "Create", query)
+	defer span.end(&err)
+
+	return r.WithTx(ctx, func(tx task.Repository) error {
+		repo := tx.(*Repository)
+		_, err := repo.q().ExecContext(ctx, query, t.ID, t.Date, t.Title, t.Comment, t.Repeat, t.OverduePolicy)
+		if isUniqueViolation(err) {
+			return task.Conflict(i18n.TaskIDTaken, i18n.Params{"id": t.ID})
+		}
+		if err != nil {
+			return fmt.Errorf("ошибка при создании задачи: %w", err)
+		}
+		span.rows = 1
+		return repo.syncSequence(ctx, "tasks")
+	})
+}
+
+// syncSequence сдвигает последовательность идентификаторов table за наибольший занятый:
+// после вставки с явным id следующий nextval иначе выдал бы уже занятый идентификатор
+func (r *Repository) syncSequence(ctx context.Context, table string) error {
+	// Имя таблицы — константа из кода, а не ввод пользователя
+	query := fmt.Sprintf("SELECT setval(pg_get_serial_sequence('%[1]s', 'id'), (SELECT MAX(id) FROM %[1]s))", table)
+	if _, err := r.q().ExecContext(ctx, query); err != nil {
+		return fmt.Errorf("ошибка обновления последовательности %s: %w", table, err)
+	}
+	return nil
+}
+
+// isUniqueViolation сообщает, что запись с таким ключом уже есть
+func isUniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
+
+func (r *Repository) GetTasks(ctx context.Context, query *task.ListQuery) (_ []task.Task, err error) {
+	var tasks []task.Task
+	var queryStr string
+	var args []interface{}
+
+	queryStr = "SELECT " + taskColumns + " FROM tasks"
+
+	var conditions []string
+
+	if query.Filter != nil {
+		where, filterArgs, err := compileFilter(query.Filter)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка построения фильтра: %w", err)
+		}
+		conditions = append(conditions, where)
+		args = append(args, filterArgs...)
+	}
+
+	if query.After != nil {
+		conditions = append(conditions, "(date > ? OR (date = ? AND id > ?))")
+		args = append(args, query.After.Date, query.After.Date, query.After.ID)
+	}
+
+	if len(conditions) > 0 {
+		queryStr += " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	queryStr += " ORDER BY date ASC, id ASC LIMIT ?"
+	args = append(args, query.Limit)
+	queryStr = r.db.Rebind(queryStr)
+
+	ctx, span := startSpan(ctx, "GetTasks", queryStr)
+	defer span.end(&err)
+
+	if err := sqlx.SelectContext(ctx, r.q(), &tasks, queryStr, args...); err != nil {
+		return nil, fmt.Errorf("ошибка выборки задач: %w", err)
+	}
+
+	span.rows = int64(len(tasks))
+	return tasks, nil
+}
+
+// Search ищет по поисковому вектору; заголовок весит больше комментария.
+// Rank — ts_rank с обратным знаком, чтобы, как и bm25 в SQLite, меньшее значение было лучше.
+func (r *Repository) Search(ctx context.Context, query *task.SearchQuery) (_ []task.SearchResult, err error) {
+	expression := tsQuery(query.Terms)
+	if expression == "" {
+		return r.searchLike(ctx, query)
+	}
+
+	statement := r.db.Rebind(`
+        SELECT ` + taskColumns + `, -ts_rank(search, q) AS rank
+        FROM tasks, to_tsquery('simple', ?) AS q
+        WHERE search @@ q
+        ORDER BY rank, date, id
+        LIMIT ?`)
+
+	ctx, span := startSpan(ctx, "Search", statement)
+	defer span.end(&err)
+
+	var results []task.SearchResult
+	if err := sqlx.SelectContext(ctx, r.q(), &results, statement, expression, query.Limit); err != nil {
+		return nil, fmt.Errorf("ошибка полнотекстового поиска: %w", err)
+	}
+	span.rows = int64(len(results))
+
+	for i := range results {
+		results[i].TitleHighlight = task.Highlight(results[i].Title, query.Terms)
+		results[i].CommentHighlight = task.Highlight(results[i].Comment, query.Terms)
+	}
+	return results, nil
+}
+
+// searchLike используется, если в терминах нет ни одного слова (только знаки препинания):
+// такие термины не попадают в поисковый вектор, поэтому ищем подстроку
+func (r *Repository) searchLike(ctx context.Context, query *task.SearchQuery) (_ []task.SearchResult, err error) {
+	queryStr := "SELECT " + taskColumns + " FROM tasks"
+	var conditions []string
+	var args []interface{}
+
+	for _, term := range query.Terms {
+		pattern := likePattern(term.Text)
+		conditions = append(conditions, `(lower(title) LIKE ? ESCAPE '\' OR lower(comment) LIKE ? ESCAPE '\')`)
+		args = append(args, pattern, pattern)
+	}
+	if len(conditions) > 0 {
+		queryStr += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	queryStr += " ORDER BY date ASC, id ASC LIMIT ?"
+	args = append(args, query.Limit)
+	queryStr = r.db.Rebind(queryStr)
+
+	ctx, span := startSpan(ctx, "Search", queryStr)
+	defer span.end(&err)
+
+	var tasks []task.Task
+	if err := sqlx.SelectContext(ctx, r.q(), &tasks, queryStr, args...); err != nil {
+		return nil, fmt.Errorf("ошибка поиска задач: %w", err)
+	}
+	span.rows = int64(len(tasks))
+
+	results := make([]task.SearchResult, 0, len(tasks))
+	for _, t := range tasks {
+		results = append(results, task.SearchResult{
+			Task:             t,
+			TitleHighlight:   task.Highlight(t.Title, query.Terms),
+			CommentHighlight: task.Highlight(t.Comment, query.Terms),
+		})
+	}
+	return results, nil
+}
+
+// GetTaskByID внутри транзакции блокирует строку задачи до ее завершения (FOR UPDATE):
+// параллельная единица работы над той же задачей ждет и затем читает уже новые данные
+func (r *Repository) GetTaskByID(ctx context.Context, id int64) (_ *task.Task, err error) {
+	query := "SELECT " + taskColumns + " FROM tasks WHERE id = ?"
+	if r.tx != nil {
+		query += " FOR UPDATE"
+	}
+	query = r.db.Rebind(query)
+
+	ctx, span := startSpan(ctx, "GetTaskByID", query)
+	defer span.end(&err)
+
+	var t task.Task
+	err = sqlx.GetContext(ctx, r.q(), &t, query, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, task.NotFound(i18n.TaskNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения задачи: %w", err)
+	}
+	span.rows = 1
+	return &t, nil
+}
+
+func (r *Repository) UpdateTask(ctx context.Context, t *task.Task) (err error) {
+	query := r.db.Rebind(`
+        UPDATE tasks
+        SET date = ?, title = ?, comment = ?, repeat = ?, overdue_policy = ?
+        WHERE id = ?`)
+
+	ctx, span := startSpan(ctx, "UpdateTask", query)
+	defer span.end(&err)
+
+	result, err := r.q().ExecContext(ctx, query, t.Date, t.Title, t.Comment, t.Repeat, t.OverduePolicy, t.ID)
+	if err != nil {
+		return fmt.Errorf("ошибка обновления задачи: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("ошибка получения количества обновленных строк: %w", err)
+	}
+	span.rows = rows
+	if rows == 0 {
+		return task.NotFound(i18n.TaskNotFound)
+	}
+
+	return nil
+}
+
+func (r *Repository) DeleteTask(ctx context.Context, id int64) (err error) {
+	query := r.db.Rebind("DELETE FROM tasks WHERE id = ?")
+
+	ctx, span := startSpan(ctx, "DeleteTask", query)
+	defer span.end(&err)
+
+	result, err := r.q().ExecContext(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("ошибка удаления задачи: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("ошибка получения количества удаленных строк: %w", err)
+	}
+	span.rows = rows
+	if rows == 0 {
+		return task.NotFound(i18n.TaskNotFound)
+	}
+
+	return nil
+}
+
+func (r *Repository) UpdateTaskDate(ctx context.Context, id int64, newDate string) (err error) {
+	query := r.db.Rebind("UPDATE tasks SET date = ? WHERE id = ?")
+
+	ctx, span := startSpan(ctx, "UpdateTaskDate", query)
+	defer span.end(&err)
+
+	result, err := r.q().ExecContext(ctx, query, newDate, id)
+	if err != nil {
+		return fmt.Errorf("ошибка обновления даты задачи: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("ошибка получения количества обновленных строк: %w", err)
+	}
+	span.rows = rows
+	if rows == 0 {
+		return task.NotFound(i18n.TaskNotFound)
+	}
+
+	return nil
+}
+
+func (r *Repository) GetStats(ctx context.Context, today string) (_ *task.Stats, err error) {
+	query := r.db.Rebind(`
+        SELECT
+            COUNT(*) FILTER (WHERE date >= ?) AS upcoming,
+            COUNT(*) FILTER (WHERE date < ?) AS overdue,
+            COUNT(*) FILTER (WHERE repeat != '') AS recurring
+        FROM tasks`)
+
+	ctx, span := startSpan(ctx, "GetStats", query)
+	defer span.end(&err)
+
+	var stats task.Stats
+	if err := sqlx.GetContext(ctx, r.q(), &stats, query, today, today); err != nil {
+		return nil, fmt.Errorf("ошибка подсчета задач: %w", err)
+	}
+	return &stats, nil
+}
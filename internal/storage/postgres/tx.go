@@ -0,0 +1,42 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"tasktracker/internal/domain/task"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// q возвращает, через что выполнять запросы: открытую транзакцию или пул соединений
+func (r *Repository) q() sqlx.ExtContext {
+	if r.tx != nil {
+		return r.tx
+	}
+	return r.db
+}
+
+// WithTx выполняет fn в одной транзакции (READ COMMITTED). Одновременные единицы работы
+// над одной задачей упорядочиваются блокировкой строки в GetTaskByID.
+func (r *Repository) WithTx(ctx context.Context, fn func(tx task.Repository) error) (err error) {
+	if r.tx != nil {
+		return fn(r)
+	}
+
+	ctx, span := startSpan(ctx, "WithTx", "BEGIN")
+	defer span.end(&err)
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("не удалось начать транзакцию: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := fn(&Repository{db: r.db, tx: tx}); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("не удалось зафиксировать транзакцию: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,659 @@
+// Package repotest содержит общий набор проверок task.Repository.
+//
+// Каждое хранилище запускает его в своих тестах, поэтому все реализации
+// ведут себя одинаково: возвращают одни и те же ошибки, одинаково сортируют,
+// фильтруют и ищут задачи.
+package repotest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"tasktracker/internal/domain/task"
+	"tasktracker/internal/i18n"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Factory возвращает новый пустой репозиторий; ресурсы освобождаются через t.Cleanup
+type Factory func(t *testing.T) task.Repository
+
+// Run запускает все проверки, каждую на отдельном репозитории
+func Run(t *testing.T, newRepo Factory) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, repo task.Repository)
+	}{
+		{"CRUD", testCRUD},
+		{"NotFound", testNotFound},
+		{"PresetID", testPresetID},
+		{"Ordering", testOrdering},
+		{"Filter", testFilter},
+		{"LikeEscaping", testLikeEscaping},
+		{"Search", testSearch},
+		{"SavedLists", testSavedLists},
+		{"Stats", testStats},
+		{"Audit", testAudit},
+		{"Concurrency", testConcurrency},
+		{"Transactions", testTransactions},
+		{"ConcurrentTransactions", testConcurrentTransactions},
+		{"ConcurrentMarkTaskDone", testConcurrentMarkTaskDone},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn(t, newRepo(t))
+		})
+	}
+}
+
+// create сохраняет задачу и возвращает ее с присвоенным id
+func create(t *testing.T, repo task.Repository, date, title, comment, repeat string) task.Task {
+	t.Helper()
+	tk := task.Task{Date: date, Title: title, Comment: comment, Repeat: repeat}
+	require.NoError(t, repo.Create(context.Background(), &tk))
+	require.NotZero(t, tk.ID)
+	return tk
+}
+
+// list возвращает заголовки задач, отобранных фильтром, в порядке выдачи
+func list(t *testing.T, repo task.Repository, filter string) []string {
+	t.Helper()
+	query := &task.ListQuery{Limit: task.MaxListLimit}
+	if filter != "" {
+		expr, err := task.ParseFilter(filter, time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC))
+		require.NoError(t, err)
+		query.Filter = expr
+	}
+	tasks, err := repo.GetTasks(context.Background(), query)
+	require.NoError(t, err)
+
+	titles := make([]string, 0, len(tasks))
+	for _, tk := range tasks {
+		titles = append(titles, tk.Title)
+	}
+	return titles
+}
+
+func testCRUD(t *testing.T, repo task.Repository) {
+	ctx := context.Background()
+
+	first := create(t, repo, "20240110", "Первая", "комментарий", "d 3")
+	second := create(t, repo, "20240105", "Вторая", "", "")
+	assert.NotEqual(t, first.ID, second.ID)
+
+	got, err := repo.GetTaskByID(ctx, first.ID)
+	require.NoError(t, err)
+	assert.Equal(t, first, *got)
+
+	first.Title = "Первая (изменена)"
+	first.Repeat = ""
+	first.OverduePolicy = task.OverduePolicyKeep
+	require.NoError(t, repo.UpdateTask(ctx, &first))
+	got, err = repo.GetTaskByID(ctx, first.ID)
+	require.NoError(t, err)
+	assert.Equal(t, first, *got)
+
+	// Возврат к политике по умолчанию тоже сохраняется
+	first.OverduePolicy = task.OverduePolicyAuto
+	require.NoError(t, repo.UpdateTask(ctx, &first))
+	got, err = repo.GetTaskByID(ctx, first.ID)
+	require.NoError(t, err)
+	assert.Equal(t, task.OverduePolicyAuto, got.OverduePolicy)
+
+	require.NoError(t, repo.UpdateTaskDate(ctx, second.ID, "20240201"))
+	got, err = repo.GetTaskByID(ctx, second.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "20240201", got.Date)
+
+	assert.Equal(t, []string{"Первая (изменена)", "Вторая"}, list(t, repo, ""))
+
+	require.NoError(t, repo.DeleteTask(ctx, first.ID))
+	assert.Equal(t, []string{"Вторая"}, list(t, repo, ""))
+}
+
+// assertNotFound проверяет, что err — доменная ошибка «не найдено» с кодом code:
+// по ним transport выбирает статус 404 и текст ответа
+func assertNotFound(t *testing.T, err error, code i18n.Code, op string) {
+	t.Helper()
+	if !assert.ErrorIs(t, err, task.ErrNotFound, op) {
+		return
+	}
+	var domainErr *task.Error
+	if assert.True(t, errors.As(err, &domainErr), "%s: ожидается *task.Error, получено %T", op, err) {
+		assert.Equal(t, code, domainErr.Innermost().Code, op)
+	}
+}
+
+func testNotFound(t *testing.T, repo task.Repository) {
+	ctx := context.Background()
+	const missing = 999999
+
+	// Существующая задача не должна влиять на ответы для чужого id
+	existing := create(t, repo, "20240101", "Есть", "", "")
+
+	_, err := repo.GetTaskByID(ctx, missing)
+	assertNotFound(t, err, i18n.TaskNotFound, "GetTaskByID")
+	err = repo.UpdateTask(ctx, &task.Task{ID: missing, Date: "20240101", Title: "Нет"})
+	assertNotFound(t, err, i18n.TaskNotFound, "UpdateTask")
+	assertNotFound(t, repo.UpdateTaskDate(ctx, missing, "20240102"), i18n.TaskNotFound, "UpdateTaskDate")
+	assertNotFound(t, repo.DeleteTask(ctx, missing), i18n.TaskNotFound, "DeleteTask")
+
+	_, err = repo.GetSavedListByID(ctx, missing, "")
+	assertNotFound(t, err, i18n.ListNotFound, "GetSavedListByID")
+	assertNotFound(t, repo.DeleteSavedList(ctx, missing), i18n.ListNotFound, "DeleteSavedList")
+
+	// Повторное удаление уже удаленной задачи — тоже «не найдено»
+	require.NoError(t, repo.DeleteTask(ctx, existing.ID))
+	assertNotFound(t, repo.DeleteTask(ctx, existing.ID), i18n.TaskNotFound, "DeleteTask повторно")
+	_, err = repo.GetTaskByID(ctx, existing.ID)
+	assertNotFound(t, err, i18n.TaskNotFound, "GetTaskByID удаленной")
+}
+
+// testPresetID проверяет создание с заданным id (импорт): id сохраняется,
+// следующие записи получают большие id, а занятый id — конфликт
+func testPresetID(t *testing.T, repo task.Repository) {
+	ctx := context.Background()
+
+	auto := create(t, repo, "20240101", "Авто", "", "")
+	preset := task.Task{ID: auto.ID + 100, Date: "20240102", Title: "Импорт"}
+	require.NoError(t, repo.Create(ctx, &preset))
+	assert.Equal(t, auto.ID+100, preset.ID)
+	got, err := repo.GetTaskByID(ctx, preset.ID)
+	require.NoError(t, err)
+	assert.Equal(t, preset, *got)
+
+	next := create(t, repo, "20240103", "После импорта", "", "")
+	assert.Greater(t, next.ID, preset.ID)
+
+	err = repo.Create(ctx, &task.Task{ID: preset.ID, Date: "20240104", Title: "Дубль"})
+	assertConflict(t, err, i18n.TaskIDTaken, "Create")
+
+	home := task.SavedList{Name: "Дом", Query: "repeat:none"}
+	require.NoError(t, repo.CreateSavedList(ctx, &home))
+	imported := task.SavedList{ID: home.ID + 100, Name: "Импорт", Query: "title:отчет"}
+	require.NoError(t, repo.CreateSavedList(ctx, &imported))
+	assert.Equal(t, home.ID+100, imported.ID)
+
+	work := task.SavedList{Name: "Работа", Query: "title:план"}
+	require.NoError(t, repo.CreateSavedList(ctx, &work))
+	assert.Greater(t, work.ID, imported.ID)
+
+	err = repo.CreateSavedList(ctx, &task.SavedList{ID: imported.ID, Name: "Дубль", Query: "repeat:none"})
+	assertConflict(t, err, i18n.ListIDTaken, "CreateSavedList")
+}
+
+// assertConflict проверяет, что err — доменная ошибка конфликта с кодом code
+func assertConflict(t *testing.T, err error, code i18n.Code, op string) {
+	t.Helper()
+	if !assert.ErrorIs(t, err, task.ErrConflict, op) {
+		return
+	}
+	var domainErr *task.Error
+	if assert.True(t, errors.As(err, &domainErr), "%s: ожидается *task.Error, получено %T", op, err) {
+		assert.Equal(t, code, domainErr.Innermost().Code, op)
+	}
+}
+
+func testOrdering(t *testing.T, repo task.Repository) {
+	ctx := context.Background()
+
+	// Задачи создаются не по порядку дат, несколько задач на одну дату
+	dates := []string{"20240103", "20240101", "20240102", "20240101", "20240103", "20240102", "20240101"}
+	var all []task.Task
+	for i, date := range dates {
+		all = append(all, create(t, repo, date, fmt.Sprintf("Задача %d", i), "", ""))
+	}
+
+	page := func(limit int, after *task.Cursor) []task.Task {
+		tasks, err := repo.GetTasks(ctx, &task.ListQuery{Limit: limit, After: after})
+		require.NoError(t, err)
+		return tasks
+	}
+
+	want := page(len(all)+10, nil)
+	require.Len(t, want, len(all))
+	for i := 1; i < len(want); i++ {
+		prev, cur := want[i-1], want[i]
+		assert.True(t, prev.Date < cur.Date || prev.Date == cur.Date && prev.ID < cur.ID,
+			"нарушен порядок (date, id): %s/%d перед %s/%d", prev.Date, prev.ID, cur.Date, cur.ID)
+	}
+
+	// LIMIT ограничивает выдачу началом того же порядка
+	assert.Equal(t, want[:3], page(3, nil))
+	assert.Equal(t, want[:1], page(1, nil))
+
+	// Страницы по курсору без пропусков и повторов собираются в полный список
+	var got []task.Task
+	var after *task.Cursor
+	for {
+		tasks := page(3, after)
+		got = append(got, tasks...)
+		if len(tasks) < 3 {
+			break
+		}
+		last := tasks[len(tasks)-1]
+		after = &task.Cursor{Date: last.Date, ID: last.ID}
+	}
+	assert.Equal(t, want, got)
+
+	// Курсор после последней задачи дает пустую страницу
+	last := want[len(want)-1]
+	assert.Empty(t, page(10, &task.Cursor{Date: last.Date, ID: last.ID}))
+}
+
+func testFilter(t *testing.T, repo task.Repository) {
+	create(t, repo, "20240110", "Купить молоко", "в магазине у дома", "")
+	create(t, repo, "20240120", "Отчет", "квартальный, срочно", "m 1")
+	create(t, repo, "20240101", "Тренировка", "", "w 1,3,5")
+	create(t, repo, "20240115", "ПОЗВОНИТЬ маме", "", "d 7")
+
+	tests := []struct {
+		filter string
+		want   []string
+	}{
+		{"", []string{"Тренировка", "Купить молоко", "ПОЗВОНИТЬ маме", "Отчет"}},
+		{"title:молоко", []string{"Купить молоко"}},
+		{"title:позвонить", []string{"ПОЗВОНИТЬ маме"}},
+		{"олок", []string{"Купить молоко"}},
+		{"газин", []string{"Купить молоко"}},
+		{"ЧЕТ", []string{"Отчет"}},
+		{"comment:срочно", []string{"Отчет"}},
+		{"date<20240115", []string{"Тренировка", "Купить молоко"}},
+		{"date>=15.01.2024 -repeat:m", []string{"ПОЗВОНИТЬ маме"}},
+		{"repeat:none", []string{"Купить молоко"}},
+		{"repeat:w", []string{"Тренировка"}},
+		{"repeat:any date>20240110", []string{"ПОЗВОНИТЬ маме", "Отчет"}},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, list(t, repo, tt.filter), "фильтр %q", tt.filter)
+	}
+}
+
+// testLikeEscaping проверяет, что %, _ и \ в значении фильтра ищутся как обычные символы,
+// а не как шаблон LIKE
+func testLikeEscaping(t *testing.T, repo task.Repository) {
+	create(t, repo, "20240101", "Готово на 100%", "", "")
+	create(t, repo, "20240102", "Готово на 1000 задач", "", "")
+	create(t, repo, "20240103", "snake_case", "", "")
+	create(t, repo, "20240104", "snakeXcase", "", "")
+	create(t, repo, "20240105", `C:\temp`, "", "")
+	create(t, repo, "20240106", "C:/temp", "путь 50%", "")
+
+	tests := []struct {
+		filter string
+		want   []string
+	}{
+		{"title:100%", []string{"Готово на 100%"}},
+		{"title:snake_case", []string{"snake_case"}},
+		{`title:C:\temp`, []string{`C:\temp`}},
+		{"comment:50%", []string{"C:/temp"}},
+		{"%", []string{"Готово на 100%", "C:/temp"}},
+		{"_", []string{"snake_case"}},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, list(t, repo, tt.filter), "фильтр %q", tt.filter)
+	}
+}
+
+func testSearch(t *testing.T, repo task.Repository) {
+	create(t, repo, "20240110", "Купить молоко", "и хлеб", "")
+	create(t, repo, "20240105", "Хлебозавод", "экскурсия", "")
+	create(t, repo, "20240101", "Отчет", "", "")
+
+	search := func(input string) []string {
+		terms, err := task.ParseSearchTerms(input)
+		require.NoError(t, err)
+		results, err := repo.Search(context.Background(), &task.SearchQuery{Terms: terms, Limit: 10})
+		require.NoError(t, err)
+
+		titles := make([]string, 0, len(results))
+		for _, r := range results {
+			titles = append(titles, r.Title)
+		}
+		return titles
+	}
+
+	assert.Equal(t, []string{"Купить молоко"}, search("молоко"))
+	assert.Equal(t, []string{"Купить молоко"}, search("МОЛОКО"))
+	assert.ElementsMatch(t, []string{"Купить молоко", "Хлебозавод"}, search("хлеб*"))
+	assert.Equal(t, []string{"Купить молоко"}, search("хлеб* молоко"), "все термины должны встречаться")
+	assert.Empty(t, search("самолет"))
+
+	terms, err := task.ParseSearchTerms("хлеб*")
+	require.NoError(t, err)
+	limited, err := repo.Search(context.Background(), &task.SearchQuery{Terms: terms, Limit: 1})
+	require.NoError(t, err)
+	assert.Len(t, limited, 1)
+
+	terms, err = task.ParseSearchTerms("молоко")
+	require.NoError(t, err)
+	results, err := repo.Search(context.Background(), &task.SearchQuery{Terms: terms, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "Купить "+task.HighlightStart+"молоко"+task.HighlightEnd, results[0].TitleHighlight)
+
+	// Разметка из текста задачи экранируется: в подсветке остаются только теги совпадений
+	create(t, repo, "20240102", `<img src=x onerror=alert(1)> вирус`, `<script>вирус</script>`, "")
+	terms, err = task.ParseSearchTerms("вирус")
+	require.NoError(t, err)
+	results, err = repo.Search(context.Background(), &task.SearchQuery{Terms: terms, Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "&lt;img src=x onerror=alert(1)&gt; "+task.HighlightStart+"вирус"+task.HighlightEnd,
+		results[0].TitleHighlight)
+	assert.Equal(t, "&lt;script&gt;"+task.HighlightStart+"вирус"+task.HighlightEnd+"&lt;/script&gt;",
+		results[0].CommentHighlight)
+}
+
+func testSavedLists(t *testing.T, repo task.Repository) {
+	ctx := context.Background()
+
+	work := task.SavedList{Name: "Работа", Query: "title:отчет"}
+	home := task.SavedList{Name: "Дом", Query: "repeat:none", Owner: "owner"}
+	require.NoError(t, repo.CreateSavedList(ctx, &work))
+	require.NoError(t, repo.CreateSavedList(ctx, &home))
+	assert.NotEqual(t, work.ID, home.ID)
+
+	got, err := repo.GetSavedListByID(ctx, home.ID, "owner")
+	require.NoError(t, err)
+	assert.Equal(t, home, *got)
+
+	// Чужие списки хранилище не отдает, общие (без владельца) видны всем
+	_, err = repo.GetSavedListByID(ctx, home.ID, "")
+	assertNotFound(t, err, i18n.ListNotFound, "GetSavedListByID")
+	_, err = repo.GetSavedListByID(ctx, home.ID, "guest")
+	assertNotFound(t, err, i18n.ListNotFound, "GetSavedListByID")
+	got, err = repo.GetSavedListByID(ctx, work.ID, "owner")
+	require.NoError(t, err)
+	assert.Equal(t, work, *got)
+
+	lists, err := repo.GetSavedLists(ctx, "")
+	require.NoError(t, err)
+	assert.Equal(t, []task.SavedList{work}, lists)
+	lists, err = repo.GetSavedLists(ctx, "owner")
+	require.NoError(t, err)
+	assert.Equal(t, []task.SavedList{work, home}, lists)
+	lists, err = repo.GetSavedLists(ctx, "guest")
+	require.NoError(t, err)
+	assert.Equal(t, []task.SavedList{work}, lists)
+
+	require.NoError(t, repo.DeleteSavedList(ctx, work.ID))
+	lists, err = repo.GetSavedLists(ctx, "owner")
+	require.NoError(t, err)
+	assert.Equal(t, []task.SavedList{home}, lists)
+}
+
+func testStats(t *testing.T, repo task.Repository) {
+	stats, err := repo.GetStats(context.Background(), "20240115")
+	require.NoError(t, err)
+	assert.Equal(t, task.Stats{}, *stats)
+
+	create(t, repo, "20240110", "Просрочена", "", "")
+	create(t, repo, "20240115", "Сегодня", "", "d 1")
+	create(t, repo, "20240120", "Позже", "", "y")
+
+	stats, err = repo.GetStats(context.Background(), "20240115")
+	require.NoError(t, err)
+	assert.Equal(t, task.Stats{Upcoming: 2, Overdue: 1, Recurring: 2}, *stats)
+}
+
+// testAudit проверяет отбор записей журнала, порядок от новых к старым и откат вместе с транзакцией
+func testAudit(t *testing.T, repo task.Repository) {
+	ctx := context.Background()
+	start := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	title := "Отчет"
+
+	entries := []task.AuditEntry{
+		{Time: start, Actor: "owner", Action: task.AuditCreate, TaskID: 1, RequestID: "req-1",
+			Changes: map[string]task.AuditChange{"title": {After: &title}}},
+		{Time: start.Add(time.Minute), Actor: "owner", Action: task.AuditCreate, ListID: 1,
+			Changes: map[string]task.AuditChange{}},
+		{Time: start.Add(2 * time.Minute), Actor: "cli", Action: task.AuditDelete, TaskID: 1,
+			Changes: map[string]task.AuditChange{"title": {Before: &title}}},
+	}
+	for i := range entries {
+		require.NoError(t, repo.AppendAudit(ctx, &entries[i]))
+	}
+	assert.Less(t, entries[0].ID, entries[1].ID)
+	assert.Less(t, entries[1].ID, entries[2].ID)
+
+	get := func(query task.AuditQuery) []task.AuditEntry {
+		t.Helper()
+		query.Limit = 10
+		got, err := repo.GetAudit(ctx, &query)
+		require.NoError(t, err)
+		return got
+	}
+	got := get(task.AuditQuery{})
+	require.Len(t, got, 3)
+	assert.Equal(t, entries[2], got[0])
+	assert.Equal(t, entries[0], got[2])
+
+	ids := func(got []task.AuditEntry) []int64 {
+		out := make([]int64, 0, len(got))
+		for _, e := range got {
+			out = append(out, e.ID)
+		}
+		return out
+	}
+	first, saved, last := entries[0].ID, entries[1].ID, entries[2].ID
+	tests := []struct {
+		name  string
+		query task.AuditQuery
+		want  []int64
+	}{
+		{"actor", task.AuditQuery{Actor: "owner"}, []int64{saved, first}},
+		{"action", task.AuditQuery{Action: task.AuditDelete}, []int64{last}},
+		{"task", task.AuditQuery{TaskID: 1}, []int64{last, first}},
+		{"list", task.AuditQuery{ListID: 1}, []int64{saved}},
+		{"request", task.AuditQuery{RequestID: "req-1"}, []int64{first}},
+		{"since", task.AuditQuery{Since: start.Add(time.Minute)}, []int64{last, saved}},
+		{"until", task.AuditQuery{Until: start.Add(time.Minute)}, []int64{first}},
+		{"before", task.AuditQuery{Before: last}, []int64{saved, first}},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, ids(get(tt.query)), tt.name)
+	}
+
+	query := task.AuditQuery{Limit: 2}
+	got, err := repo.GetAudit(ctx, &query)
+	require.NoError(t, err)
+	assert.Equal(t, []int64{last, saved}, ids(got))
+
+	// Записи отмененной транзакции не сохраняются
+	errRollback := errors.New("откат")
+	err = repo.WithTx(ctx, func(tx task.Repository) error {
+		e := task.AuditEntry{Time: start, Actor: "owner", Action: task.AuditUpdate, TaskID: 2,
+			Changes: map[string]task.AuditChange{}}
+		require.NoError(t, tx.AppendAudit(ctx, &e))
+		return errRollback
+	})
+	require.ErrorIs(t, err, errRollback)
+	assert.Len(t, get(task.AuditQuery{}), 3)
+}
+
+// testConcurrency проверяет, что одновременные запросы из разных горутин не теряют
+// и не дублируют задачи и не завершаются ошибками блокировки
+func testConcurrency(t *testing.T, repo task.Repository) {
+	ctx := context.Background()
+	const (
+		workers   = 8
+		perWorker = 10
+	)
+
+	shared := create(t, repo, "20240101", "Общая", "", "d 1")
+
+	var wg sync.WaitGroup
+	ids := make(chan int64, workers*perWorker)
+	errs := make(chan error, workers*perWorker*3)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < perWorker; i++ {
+				tk := task.Task{Date: "20240201", Title: fmt.Sprintf("Задача %d-%d", w, i)}
+				if err := repo.Create(ctx, &tk); err != nil {
+					errs <- err
+					continue
+				}
+				ids <- tk.ID
+				if _, err := repo.GetTasks(ctx, &task.ListQuery{Limit: 5}); err != nil {
+					errs <- err
+				}
+				if err := repo.UpdateTaskDate(ctx, shared.ID, fmt.Sprintf("202403%02d", w+1)); err != nil {
+					errs <- err
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+	close(ids)
+	close(errs)
+
+	for err := range errs {
+		t.Errorf("ошибка при одновременной работе: %v", err)
+	}
+
+	seen := make(map[int64]bool)
+	for id := range ids {
+		assert.False(t, seen[id], "id %d выдан дважды", id)
+		seen[id] = true
+	}
+	assert.Len(t, seen, workers*perWorker)
+
+	tasks, err := repo.GetTasks(ctx, &task.ListQuery{Limit: task.MaxListLimit})
+	require.NoError(t, err)
+	assert.Len(t, tasks, workers*perWorker+1)
+
+	got, err := repo.GetTaskByID(ctx, shared.ID)
+	require.NoError(t, err)
+	assert.Regexp(t, `^202403(0[1-8])$`, got.Date, "дата должна быть одной из записанных")
+}
+
+func testTransactions(t *testing.T, repo task.Repository) {
+	ctx := context.Background()
+	errAbort := errors.New("abort")
+
+	kept := create(t, repo, "20240101", "Останется", "", "")
+
+	// Ошибка внутри единицы работы откатывает все ее изменения, включая вложенный WithTx
+	var created task.Task
+	err := repo.WithTx(ctx, func(tx task.Repository) error {
+		created = task.Task{Date: "20240102", Title: "Откатится"}
+		require.NoError(t, tx.Create(ctx, &created))
+		require.NoError(t, tx.UpdateTaskDate(ctx, kept.ID, "20240301"))
+		require.NoError(t, tx.WithTx(ctx, func(inner task.Repository) error {
+			return inner.DeleteTask(ctx, kept.ID)
+		}))
+
+		// Внутри транзакции изменения уже видны
+		_, err := tx.GetTaskByID(ctx, kept.ID)
+		assertNotFound(t, err, i18n.TaskNotFound, "GetTaskByID внутри транзакции")
+		return errAbort
+	})
+	assert.ErrorIs(t, err, errAbort, "WithTx должен вернуть ошибку fn без изменений")
+
+	got, err := repo.GetTaskByID(ctx, kept.ID)
+	require.NoError(t, err)
+	assert.Equal(t, kept, *got)
+	_, err = repo.GetTaskByID(ctx, created.ID)
+	assertNotFound(t, err, i18n.TaskNotFound, "GetTaskByID после отката")
+
+	// Без ошибки изменения фиксируются
+	err = repo.WithTx(ctx, func(tx task.Repository) error {
+		created = task.Task{Date: "20240102", Title: "Зафиксируется"}
+		if err := tx.Create(ctx, &created); err != nil {
+			return err
+		}
+		return tx.UpdateTaskDate(ctx, kept.ID, "20240301")
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Зафиксируется", "Останется"}, list(t, repo, ""))
+}
+
+// testConcurrentTransactions проверяет, что чтение с последующей записью в WithTx
+// не теряет изменений: каждая из единиц работы сдвигает дату на один день
+func testConcurrentTransactions(t *testing.T, repo task.Repository) {
+	ctx := context.Background()
+	const workers = 20
+
+	counter := create(t, repo, "20240101", "Счетчик", "", "")
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := repo.WithTx(ctx, func(tx task.Repository) error {
+				cur, err := tx.GetTaskByID(ctx, counter.ID)
+				if err != nil {
+					return err
+				}
+				date, err := task.ParseDate(cur.Date)
+				if err != nil {
+					return err
+				}
+				return tx.UpdateTaskDate(ctx, counter.ID, task.FormatDate(date.AddDate(0, 0, 1)))
+			})
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	got, err := repo.GetTaskByID(ctx, counter.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "20240121", got.Date)
+}
+
+// testConcurrentMarkTaskDone одновременно отмечает задачи выполненными через task.Service:
+// повторяющаяся задача должна сдвинуться ровно на число отметок, а разовая — удалиться
+// ровно один раз
+func testConcurrentMarkTaskDone(t *testing.T, repo task.Repository) {
+	ctx := context.Background()
+	const clicks = 20
+
+	service := task.NewService(repo)
+	// now раньше даты задачи, поэтому каждая отметка переносит ее ровно на один день
+	now := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	daily := create(t, repo, "20240101", "Каждый день", "", "d 1")
+	once := create(t, repo, "20240101", "Один раз", "", "")
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		done     int
+		notFound int
+	)
+	for i := 0; i < clicks; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			assert.NoError(t, service.MarkTaskDone(ctx, daily.ID, now))
+		}()
+		go func() {
+			defer wg.Done()
+			err := service.MarkTaskDone(ctx, once.ID, now)
+			mu.Lock()
+			defer mu.Unlock()
+			switch {
+			case err == nil:
+				done++
+			case errors.Is(err, task.ErrNotFound):
+				notFound++
+			default:
+				t.Errorf("MarkTaskDone: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	got, err := repo.GetTaskByID(ctx, daily.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "20240121", got.Date, "ни одна отметка не должна потеряться")
+	assert.Equal(t, 1, done, "разовая задача выполняется ровно один раз")
+	assert.Equal(t, clicks-1, notFound)
+}
@@ -0,0 +1,59 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jmoiron/sqlx"
+	"net/url"
+)
+
+// Snapshot записывает согласованную копию БД в новый файл path командой VACUUM INTO.
+// Копия делается в одной читающей транзакции, поэтому в режиме wal запись во время
+// копирования не останавливается. Файл path не должен существовать.
+func (db *DB) Snapshot(ctx context.Context, path string) (err error) {
+	statement := "VACUUM INTO ?"
+
+	ctx, span := startSpan(ctx, "Snapshot", statement)
+	defer span.end(&err)
+
+	if _, err := db.ExecContext(ctx, statement, path); err != nil {
+		return fmt.Errorf("не удалось создать копию БД: %w", err)
+	}
+	return nil
+}
+
+// CheckSnapshot проверяет, что файл path — целая БД приложения, и возвращает версию ее схемы.
+// Схема старше текущей допустима: New обновит ее при открытии; новее — нет, такую копию
+// создала более новая версия приложения.
+func CheckSnapshot(path string) (int, error) {
+	db, err := sqlx.Open(driverName, "file:"+path+"?"+url.Values{"mode": {"ro"}}.Encode())
+	if err != nil {
+		return 0, fmt.Errorf("не удалось открыть копию: %w", err)
+	}
+	defer db.Close()
+
+	var integrity string
+	if err := db.Get(&integrity, "PRAGMA integrity_check"); err != nil {
+		return 0, fmt.Errorf("файл не является БД SQLite: %w", err)
+	}
+	if integrity != "ok" {
+		return 0, fmt.Errorf("копия повреждена: %s", integrity)
+	}
+
+	var version int
+	if err := db.Get(&version, "PRAGMA user_version"); err != nil {
+		return 0, fmt.Errorf("не удалось получить версию схемы: %w", err)
+	}
+	var tables int
+	if err := db.Get(&tables, "SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'scheduler'"); err != nil {
+		return 0, fmt.Errorf("не удалось прочитать схему копии: %w", err)
+	}
+	switch {
+	case version == 0 || tables == 0:
+		return 0, errors.New("файл не является БД задач")
+	case version > len(migrations):
+		return 0, fmt.Errorf("версия схемы копии %d новее поддерживаемой %d", version, len(migrations))
+	}
+	return version, nil
+}
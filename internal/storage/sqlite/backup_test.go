@@ -0,0 +1,71 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"tasktracker/internal/domain/task"
+	"tasktracker/internal/logging"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSnapshot(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	db, err := New(filepath.Join(dir, "scheduler.db"), DefaultOptions(), logging.Discard())
+	require.NoError(t, err)
+	defer db.Close()
+	repo := NewRepository(db)
+	require.NoError(t, repo.Create(ctx, &task.Task{Date: "20240115", Title: "В копии"}))
+
+	snapshot := filepath.Join(dir, "snapshot.db")
+	require.NoError(t, db.Snapshot(ctx, snapshot))
+	assert.Error(t, db.Snapshot(ctx, snapshot), "существующий файл не перезаписывается")
+
+	// Задача, созданная после копии, в нее не попадает
+	require.NoError(t, repo.Create(ctx, &task.Task{Date: "20240116", Title: "После копии"}))
+
+	version, err := CheckSnapshot(snapshot)
+	require.NoError(t, err)
+	assert.Equal(t, LatestSchemaVersion(), version)
+
+	restored, err := New(snapshot, DefaultOptions(), logging.Discard())
+	require.NoError(t, err)
+	defer restored.Close()
+	tasks, err := NewRepository(restored).GetTasks(ctx, &task.ListQuery{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, tasks, 1)
+	assert.Equal(t, "В копии", tasks[0].Title)
+}
+
+func TestCheckSnapshotRejects(t *testing.T) {
+	dir := t.TempDir()
+
+	garbage := filepath.Join(dir, "garbage.db")
+	require.NoError(t, os.WriteFile(garbage, []byte("это не база данных, а просто текст достаточной длины"), 0o600))
+	_, err := CheckSnapshot(garbage)
+	assert.Error(t, err)
+
+	// Пустая БД SQLite — не БД задач
+	foreign := filepath.Join(dir, "foreign.db")
+	other, err := sqlx.Connect(driverName, foreign)
+	require.NoError(t, err)
+	other.MustExec("CREATE TABLE notes (id INTEGER)")
+	other.Close()
+	_, err = CheckSnapshot(foreign)
+	assert.ErrorContains(t, err, "не является БД задач")
+
+	// Копия, созданная более новой версией приложения
+	newer := filepath.Join(dir, "newer.db")
+	db, err := New(newer, DefaultOptions(), logging.Discard())
+	require.NoError(t, err)
+	db.MustExec(fmt.Sprintf("PRAGMA user_version = %d", LatestSchemaVersion()+1))
+	db.DB.Close()
+	_, err = CheckSnapshot(newer)
+	assert.ErrorContains(t, err, "новее поддерживаемой")
+}
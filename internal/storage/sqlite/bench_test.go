@@ -0,0 +1,113 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sync/atomic"
+	"tasktracker/internal/domain/task"
+	"tasktracker/internal/logging"
+	"testing"
+)
+
+// Бенчмарки показывают пропускную способность списка и создания задач при параллельной
+// нагрузке в разных режимах журнала:
+//
+//	go test -run '^$' -bench . -cpu 1,4,8 ./internal/storage/sqlite
+
+// benchSeed — число задач в БД перед замером
+const benchSeed = 1000
+
+func newBenchRepository(b *testing.B, opts Options) *Repository {
+	b.Helper()
+	db, err := New(filepath.Join(b.TempDir(), "scheduler.db"), opts, logging.Discard())
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.Cleanup(func() { db.Close() })
+
+	repo := NewRepository(db)
+	ctx := context.Background()
+	for i := 0; i < benchSeed; i++ {
+		t := task.Task{Date: fmt.Sprintf("202401%02d", i%28+1), Title: fmt.Sprintf("Задача %d", i), Comment: "комментарий"}
+		if err := repo.Create(ctx, &t); err != nil {
+			b.Fatal(err)
+		}
+	}
+	return repo
+}
+
+// benchOptions — сравниваемые настройки: wal против журнала отката и размер пула
+func benchOptions() map[string]Options {
+	wal := DefaultOptions()
+
+	rollback := DefaultOptions()
+	rollback.JournalMode = "delete"
+
+	single := DefaultOptions()
+	single.MaxOpenConns, single.MaxIdleConns = 1, 1
+
+	return map[string]Options{"wal": wal, "delete": rollback, "wal-1conn": single}
+}
+
+func BenchmarkGetTasksParallel(b *testing.B) {
+	for name, opts := range benchOptions() {
+		b.Run(name, func(b *testing.B) {
+			repo := newBenchRepository(b, opts)
+			ctx := context.Background()
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					if _, err := repo.GetTasks(ctx, &task.ListQuery{Limit: 50}); err != nil {
+						b.Error(err)
+						return
+					}
+				}
+			})
+		})
+	}
+}
+
+func BenchmarkCreateParallel(b *testing.B) {
+	for name, opts := range benchOptions() {
+		b.Run(name, func(b *testing.B) {
+			repo := newBenchRepository(b, opts)
+			ctx := context.Background()
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					if err := repo.Create(ctx, &task.Task{Date: "20240115", Title: "Новая"}); err != nil {
+						b.Error(err)
+						return
+					}
+				}
+			})
+		})
+	}
+}
+
+// BenchmarkMixedParallel — девять чтений списка на одно создание, как у типичного клиента
+func BenchmarkMixedParallel(b *testing.B) {
+	for name, opts := range benchOptions() {
+		b.Run(name, func(b *testing.B) {
+			repo := newBenchRepository(b, opts)
+			ctx := context.Background()
+			var n atomic.Int64
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					var err error
+					if n.Add(1)%10 == 0 {
+						err = repo.Create(ctx, &task.Task{Date: "20240115", Title: "Новая"})
+					} else {
+						_, err = repo.GetTasks(ctx, &task.ListQuery{Limit: 50})
+					}
+					if err != nil {
+						b.Error(err)
+						return
+					}
+				}
+			})
+		})
+	}
+}
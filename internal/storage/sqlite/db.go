@@ -1,33 +1,53 @@
 package sqlite
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
 	"github.com/jmoiron/sqlx"
-	_ "github.com/mattn/go-sqlite3"
+	"github.com/mattn/go-sqlite3"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"strings"
 )
 
+// driverName — драйвер sqlite3 с дополнительными функциями приложения
+const driverName = "sqlite3_tasktracker"
+
+func init() {
+	sql.Register(driverName, &sqlite3.SQLiteDriver{
+		ConnectHook: func(conn *sqlite3.SQLiteConn) error {
+			// Встроенная lower() в SQLite переводит в нижний регистр только ASCII,
+			// поэтому для регистронезависимого поиска по кириллице используем свою функцию
+			return conn.RegisterFunc("unicode_lower", strings.ToLower, true)
+		},
+	})
+	sqlx.BindDriver(driverName, sqlx.QUESTION)
+}
+
 type DB struct {
 	*sqlx.DB
+
+	logger *slog.Logger
+
+	// fullText показывает, доступен ли полнотекстовый поиск FTS5
+	fullText bool
+
+	// stmts — подготовленные запросы репозиториев, закрываются вместе с БД
+	stmts *stmtCache
 }
 
-func New(dbFile string) (*DB, error) {
-	// Получаем путь к исполняемому файлу приложения
-	appPath, err := os.Executable()
+// New открывает файл БД dbFile (создавая его при необходимости) с настройками opts
+// и обновляет схему до последней версии
+func New(dbFile string, opts Options, logger *slog.Logger) (*DB, error) {
+	absPath, legacy, err := resolvePath(dbFile)
 	if err != nil {
-		return nil, fmt.Errorf("не удалось получить путь к исполняемому файлу: %w", err)
+		return nil, err
 	}
-	// Получаем директорию приложения
-	appDir := filepath.Dir(appPath)
-	var absPath string
-	if filepath.IsAbs(dbFile) {
-		absPath = dbFile
-	} else {
-		// Если путь относительный, объединяем его с директорией приложения
-		// Так как используется один и тот же конфиг с тестами, то для получения относительного пути используем strings.Replace()
-		absPath = filepath.Join(appDir, strings.Replace(dbFile, "../", "./", 1))
+	if legacy {
+		logger.Warn("database file found at legacy location, move it next to the configured path",
+			"path", absPath, "configured", dbFile)
 	}
 
 	// Проверяем существование файла БД
@@ -35,7 +55,6 @@ func New(dbFile string) (*DB, error) {
 	var install bool
 	if err != nil {
 		install = true // Устанавливаем флаг при любой ошибке
-		fmt.Println("База данных не найдена, будет создана новая")
 
 		// Создаем директории для БД
 		dbDir := filepath.Dir(absPath)
@@ -51,52 +70,212 @@ func New(dbFile string) (*DB, error) {
 		if err := file.Close(); err != nil {
 			return nil, fmt.Errorf("не удалось закрыть файл БД: %w", err)
 		}
-		fmt.Printf("Файл БД создан: %s\n", absPath)
+		logger.Info("database file created", "path", absPath)
 	} else {
-		fmt.Printf("Найден существующий файл БД: %s\n", absPath)
+		logger.Info("database file found", "path", absPath)
 	}
 	// Подключаемся к БД
-	db, err := sqlx.Connect("sqlite3", absPath)
+	db, err := sqlx.Connect(driverName, opts.dsn(absPath))
 	if err != nil {
 		return nil, fmt.Errorf("не удалось подключиться к БД: %w", err)
 	}
-	database := &DB{DB: db}
+	db.SetMaxOpenConns(opts.MaxOpenConns)
+	db.SetMaxIdleConns(opts.MaxIdleConns)
+	database := &DB{DB: db, logger: logger, stmts: newStmtCache()}
 
+	if err := database.migrate(); err != nil {
+		// При ошибке миграции закрываем соединение
+		if closeErr := db.Close(); closeErr != nil {
+			return nil, fmt.Errorf("ошибка миграции БД: %w; ошибка закрытия соединения: %v", err, closeErr)
+		}
+		return nil, fmt.Errorf("не удалось обновить схему БД: %w", err)
+	}
 	if install {
-		if err := database.createSchema(); err != nil {
-			// При ошибке создания схемы закрываем соединение
-			if closeErr := db.Close(); closeErr != nil {
-				return nil, fmt.Errorf("ошибка создания схемы БД: %w; ошибка закрытия соединения: %v", err, closeErr)
-			}
-			return nil, fmt.Errorf("не удалось создать схему БД: %w", err)
+		logger.Info("database initialized", "path", absPath)
+	}
+
+	if err := database.setupFullText(); err != nil {
+		if closeErr := db.Close(); closeErr != nil {
+			return nil, fmt.Errorf("ошибка настройки полнотекстового поиска: %w; ошибка закрытия соединения: %v", err, closeErr)
 		}
-		fmt.Println("База данных успешно инициализирована")
+		return nil, fmt.Errorf("ошибка настройки полнотекстового поиска: %w", err)
 	}
 	return database, nil
 }
 
-func (db *DB) createSchema() error {
-	// Создаем таблицу scheduler
-	_, err := db.Exec(`
-        CREATE TABLE scheduler (
+// ResolvePath возвращает абсолютный путь к файлу БД: относительный путь считается
+// от каталога исполняемого файла приложения
+func ResolvePath(dbFile string) (string, error) {
+	path, _, err := resolvePath(dbFile)
+	return path, err
+}
+
+// resolvePath работает как ResolvePath; legacy сообщает, что выбран файл по прежнему правилу.
+//
+// Раньше путь был общим с тестами из tests, и первый "../" в нем заменялся на "./":
+// "../scheduler.db" означал файл рядом с приложением, а не в родительском каталоге.
+// Пока по новому пути файла нет, а по прежнему есть, используется прежний.
+func resolvePath(dbFile string) (path string, legacy bool, err error) {
+	if filepath.IsAbs(dbFile) {
+		return dbFile, false, nil
+	}
+	// Получаем путь к исполняемому файлу приложения
+	appPath, err := os.Executable()
+	if err != nil {
+		return "", false, fmt.Errorf("не удалось получить путь к исполняемому файлу: %w", err)
+	}
+	// Если путь относительный, объединяем его с директорией приложения
+	appDir := filepath.Dir(appPath)
+	path = filepath.Join(appDir, dbFile)
+
+	old := filepath.Join(appDir, strings.Replace(dbFile, "../", "./", 1))
+	if old != path && !fileExists(path) && fileExists(old) {
+		return old, true, nil
+	}
+	return path, false, nil
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// migrations содержит шаги изменения схемы. Номер версии схемы равен количеству
+// примененных шагов и хранится в PRAGMA user_version. Новые шаги добавляются только в конец.
+var migrations = []string{
+	// 1: таблица задач и индекс по дате для быстрой сортировки
+	`CREATE TABLE IF NOT EXISTS scheduler (
             id INTEGER PRIMARY KEY AUTOINCREMENT,
             date TEXT NOT NULL,
             title TEXT NOT NULL,
             comment TEXT,
             repeat VARCHAR(128)
-        )
-    `)
+        );
+        CREATE INDEX IF NOT EXISTS idx_scheduler_date ON scheduler(date);`,
+
+	// 2: сохраненные поисковые запросы
+	`CREATE TABLE saved_lists (
+            id INTEGER PRIMARY KEY AUTOINCREMENT,
+            name TEXT NOT NULL,
+            query TEXT NOT NULL
+        );`,
+
+	// 3: политика просрочки хранится отдельно, чтобы не менять набор колонок scheduler;
+	// представление tasks_view объединяет задачу с ее настройками для чтения
+	`CREATE TABLE task_overdue_policy (
+            task_id INTEGER PRIMARY KEY,
+            policy TEXT NOT NULL
+        );
+        CREATE TRIGGER scheduler_overdue_policy_ad AFTER DELETE ON scheduler BEGIN
+            DELETE FROM task_overdue_policy WHERE task_id = old.id;
+        END;
+        CREATE VIEW tasks_view AS
+            SELECT s.id, s.date, s.title, s.comment, s.repeat,
+                COALESCE(p.policy, '') AS overdue_policy
+            FROM scheduler s
+            LEFT JOIN task_overdue_policy p ON p.task_id = s.id;`,
+
+	// 4: журнал изменений. Время хранится строкой фиксированной ширины в UTC, чтобы
+	// сравнение строк совпадало со сравнением времени; триггеры запрещают правку записей
+	`CREATE TABLE audit_log (
+            id INTEGER PRIMARY KEY AUTOINCREMENT,
+            created_at TEXT NOT NULL,
+            actor TEXT NOT NULL,
+            action TEXT NOT NULL,
+            task_id INTEGER,
+            list_id INTEGER,
+            request_id TEXT NOT NULL DEFAULT '',
+            changes TEXT NOT NULL
+        );
+        CREATE INDEX idx_audit_log_task_id ON audit_log(task_id);
+        CREATE TRIGGER audit_log_bu BEFORE UPDATE ON audit_log BEGIN
+            SELECT RAISE(ABORT, 'audit log is append-only');
+        END;
+        CREATE TRIGGER audit_log_bd BEFORE DELETE ON audit_log BEGIN
+            SELECT RAISE(ABORT, 'audit log is append-only');
+        END;`,
+
+	// 5: владелец сохраненного списка; списки, созданные до появления колонки, остаются общими (owner = '')
+	`ALTER TABLE saved_lists ADD COLUMN owner TEXT NOT NULL DEFAULT '';`,
+}
+
+// SchemaVersion возвращает текущую версию схемы БД
+func (db *DB) SchemaVersion() (int, error) {
+	var version int
+	if err := db.Get(&version, "PRAGMA user_version"); err != nil {
+		return 0, fmt.Errorf("не удалось получить версию схемы: %w", err)
+	}
+	return version, nil
+}
+
+// LatestSchemaVersion возвращает версию схемы, до которой New обновляет БД
+func LatestSchemaVersion() int {
+	return len(migrations)
+}
+
+// Ready проверяет, что БД отвечает и все миграции применены
+func (db *DB) Ready(ctx context.Context) error {
+	if err := db.PingContext(ctx); err != nil {
+		return fmt.Errorf("БД недоступна: %w", err)
+	}
+	version, err := db.SchemaVersion()
 	if err != nil {
-		return fmt.Errorf("не удалось создать таблицу: %w", err)
+		return err
+	}
+	if version != len(migrations) {
+		return fmt.Errorf("версия схемы БД %d, ожидается %d", version, len(migrations))
 	}
+	return nil
+}
 
-	// Создаем индекс по дате для быстрой сортировки
-	_, err = db.Exec(`
-        CREATE INDEX idx_scheduler_date ON scheduler(date)
-    `)
+// migrate применяет недостающие миграции, каждую в отдельной транзакции
+func (db *DB) migrate() error {
+	version, err := db.SchemaVersion()
 	if err != nil {
-		return fmt.Errorf("не удалось создать индекс: %w", err)
+		return err
+	}
+	if version > len(migrations) {
+		return fmt.Errorf("версия схемы БД %d новее поддерживаемой %d", version, len(migrations))
+	}
+
+	for i := version; i < len(migrations); i++ {
+		tx, err := db.Beginx()
+		if err != nil {
+			return fmt.Errorf("не удалось начать транзакцию: %w", err)
+		}
+		if _, err := tx.Exec(migrations[i]); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("не удалось применить миграцию %d: %w", i+1, err)
+		}
+		// PRAGMA не поддерживает параметры, поэтому номер версии подставляется в текст запроса
+		if _, err := tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", i+1)); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("не удалось обновить версию схемы: %w", err)
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("не удалось сохранить миграцию %d: %w", i+1, err)
+		}
+		db.logger.Info("database migration applied", "version", i+1)
 	}
 
 	return nil
 }
+
+// Close сбрасывает журнал на диск и закрывает соединения с БД
+func (db *DB) Close() error {
+	// Ошибки оптимизации не мешают закрытию, поэтому только логируются
+	if _, err := db.Exec("PRAGMA optimize"); err != nil {
+		db.logger.Warn("database optimize failed", "error", err)
+	}
+	if _, err := db.Exec("PRAGMA wal_checkpoint(TRUNCATE)"); err != nil {
+		db.logger.Warn("database checkpoint failed", "error", err)
+	}
+	if err := db.stmts.close(); err != nil {
+		db.logger.Warn("closing prepared statements failed", "error", err)
+	}
+	if err := db.DB.Close(); err != nil {
+		return err
+	}
+	db.logger.Info("database closed")
+	return nil
+}
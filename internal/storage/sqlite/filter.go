@@ -0,0 +1,104 @@
+package sqlite
+
+import (
+	"fmt"
+	"html"
+	"strings"
+	"tasktracker/internal/domain/task"
+)
+
+// likeEscaper экранирует спецсимволы LIKE, чтобы значение искалось как обычная подстрока
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// compileFilter транслирует дерево фильтра в параметризованное SQL-условие.
+// Текст ищется как подстрока без учета регистра и при сборке с FTS5: индекс находит
+// только начала слов, а результат фильтра не должен зависеть от тегов сборки.
+func compileFilter(expr task.FilterExpr) (string, []interface{}, error) {
+	switch e := expr.(type) {
+	case task.AndExpr:
+		parts := make([]string, 0, len(e.Terms))
+		var args []interface{}
+		for _, term := range e.Terms {
+			sql, termArgs, err := compileFilter(term)
+			if err != nil {
+				return "", nil, err
+			}
+			parts = append(parts, sql)
+			args = append(args, termArgs...)
+		}
+		return "(" + strings.Join(parts, " AND ") + ")", args, nil
+
+	case task.NotExpr:
+		sql, args, err := compileFilter(e.Expr)
+		if err != nil {
+			return "", nil, err
+		}
+		return "NOT " + sql, args, nil
+
+	case task.TextMatch:
+		pattern := likePattern(e.Value)
+		switch e.Field {
+		case task.TextFieldTitle:
+			return `(unicode_lower(title) LIKE ? ESCAPE '\')`, []interface{}{pattern}, nil
+		case task.TextFieldComment:
+			return `(unicode_lower(comment) LIKE ? ESCAPE '\')`, []interface{}{pattern}, nil
+		case task.TextFieldAny:
+			return `(unicode_lower(title) LIKE ? ESCAPE '\' OR unicode_lower(comment) LIKE ? ESCAPE '\')`, []interface{}{pattern, pattern}, nil
+		}
+		return "", nil, fmt.Errorf("неподдерживаемое текстовое поле: %s", e.Field)
+
+	case task.DateCompare:
+		switch e.Op {
+		case task.OpEq, task.OpLt, task.OpLe, task.OpGt, task.OpGe:
+			return fmt.Sprintf("(date %s ?)", e.Op), []interface{}{e.Date}, nil
+		}
+		return "", nil, fmt.Errorf("неподдерживаемый оператор сравнения: %s", e.Op)
+
+	case task.RepeatMatch:
+		switch e.Kind {
+		case task.RepeatAny:
+			return "(COALESCE(repeat, '') != '')", nil, nil
+		case task.RepeatNone:
+			return "(COALESCE(repeat, '') = '')", nil, nil
+		case task.RepeatDaily, task.RepeatWeekly, task.RepeatMonthly, task.RepeatYearly:
+			return "(repeat = ? OR repeat LIKE ?)", []interface{}{string(e.Kind), string(e.Kind) + " %"}, nil
+		}
+		return "", nil, fmt.Errorf("неподдерживаемый тип повторения: %s", e.Kind)
+	}
+
+	return "", nil, fmt.Errorf("неподдерживаемое условие фильтра: %T", expr)
+}
+
+// likePattern строит шаблон LIKE для регистронезависимого поиска подстроки
+func likePattern(value string) string {
+	return "%" + likeEscaper.Replace(strings.ToLower(value)) + "%"
+}
+
+// ftsExpression строит выражение MATCH для FTS5.
+// Каждый термин берется в кавычки, чтобы пользовательский ввод не интерпретировался как синтаксис FTS5.
+func ftsExpression(terms []task.SearchTerm) string {
+	parts := make([]string, 0, len(terms))
+	for _, term := range terms {
+		part := `"` + strings.ReplaceAll(term.Text, `"`, `""`) + `"`
+		if term.Prefix {
+			part += "*"
+		}
+		parts = append(parts, part)
+	}
+	return strings.Join(parts, " ")
+}
+
+// ftsMarkStart и ftsMarkEnd — символы из области частного использования, которыми
+// highlight и snippet отмечают совпадения. Теги подставляются после экранирования текста,
+// чтобы разметка из заголовков и комментариев не попала в ответ как HTML.
+const (
+	ftsMarkStart = "\uE000"
+	ftsMarkEnd   = "\uE001"
+)
+
+var ftsMarks = strings.NewReplacer(ftsMarkStart, task.HighlightStart, ftsMarkEnd, task.HighlightEnd)
+
+// ftsHighlight экранирует подсветку FTS5 как HTML и заменяет отметки совпадений тегами
+func ftsHighlight(s string) string {
+	return ftsMarks.Replace(html.EscapeString(s))
+}
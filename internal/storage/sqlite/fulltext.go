@@ -0,0 +1,94 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"tasktracker/internal/domain/task"
+)
+
+// Индекс scheduler_fts хранит копию заголовка и комментария и обновляется кодом репозитория,
+// а не триггерами: с триггерами любая запись в scheduler требовала бы модуля fts5, и клиенты
+// SQLite без него (sqlite3, тесты из tests, сервер без тега) не могли бы менять задачи.
+const fullTextSchema = `
+        CREATE VIRTUAL TABLE IF NOT EXISTS scheduler_fts USING fts5(
+            title, comment,
+            tokenize='unicode61 remove_diacritics 2'
+        )`
+
+// legacyFullTextTriggers синхронизировали индекс в прежних версиях; при запуске они удаляются
+var legacyFullTextTriggers = []string{"scheduler_fts_ai", "scheduler_fts_ad", "scheduler_fts_au"}
+
+// setupFullText создает индекс FTS5 и заполняет его заново по таблице scheduler, чтобы учесть
+// изменения, сделанные клиентами без FTS5. Если SQLite собран без FTS5 (тег сборки sqlite_fts5),
+// поиск работает через LIKE.
+func (db *DB) setupFullText() error {
+	tx, err := db.Beginx()
+	if err != nil {
+		return fmt.Errorf("не удалось начать транзакцию: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, name := range legacyFullTextTriggers {
+		if _, err := tx.Exec("DROP TRIGGER IF EXISTS " + name); err != nil {
+			return fmt.Errorf("не удалось удалить триггер %s: %w", name, err)
+		}
+	}
+
+	var available bool
+	if err := tx.Get(&available, `SELECT sqlite_compileoption_used('ENABLE_FTS5')`); err != nil {
+		return fmt.Errorf("не удалось проверить поддержку FTS5: %w", err)
+	}
+	if !available {
+		db.logger.Warn("FTS5 is not available, full-text search falls back to LIKE")
+		db.fullText = false
+		return tx.Commit()
+	}
+
+	// Прежний индекс читал текст из scheduler (external content) и без триггеров не работает
+	var ddl string
+	err = tx.Get(&ddl, `SELECT COALESCE(MAX(sql), '') FROM sqlite_master WHERE name = 'scheduler_fts'`)
+	if err != nil {
+		return fmt.Errorf("не удалось проверить индекс FTS5: %w", err)
+	}
+	if strings.Contains(ddl, "content=") {
+		if _, err := tx.Exec("DROP TABLE scheduler_fts"); err != nil {
+			return fmt.Errorf("не удалось удалить прежний индекс FTS5: %w", err)
+		}
+	}
+
+	for _, stmt := range []string{
+		fullTextSchema,
+		`DELETE FROM scheduler_fts`,
+		`INSERT INTO scheduler_fts(rowid, title, comment) SELECT id, title, COALESCE(comment, '') FROM scheduler`,
+	} {
+		if _, err := tx.Exec(stmt); err != nil {
+			return fmt.Errorf("не удалось построить индекс FTS5: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("не удалось сохранить индекс FTS5: %w", err)
+	}
+
+	db.fullText = true
+	return nil
+}
+
+// indexFullText заменяет запись задачи id в индексе FTS5; t == nil только удаляет ее.
+// Вызывается в той же транзакции, что и изменение scheduler.
+func (r *Repository) indexFullText(ctx context.Context, id int64, t *task.Task) error {
+	if !r.db.fullText {
+		return nil
+	}
+	if _, err := r.exec(ctx, "DELETE FROM scheduler_fts WHERE rowid = ?", id); err != nil {
+		return fmt.Errorf("ошибка обновления индекса FTS5: %w", err)
+	}
+	if t == nil {
+		return nil
+	}
+	_, err := r.exec(ctx, "INSERT INTO scheduler_fts(rowid, title, comment) VALUES (?, ?, ?)", id, t.Title, t.Comment)
+	if err != nil {
+		return fmt.Errorf("ошибка обновления индекса FTS5: %w", err)
+	}
+	return nil
+}
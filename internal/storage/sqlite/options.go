@@ -0,0 +1,51 @@
+package sqlite
+
+import (
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Options задает режим работы SQLite и размеры пула соединений
+type Options struct {
+	// JournalMode — режим журнала: wal позволяет читать во время записи
+	JournalMode string
+	// BusyTimeout — сколько ждать освобождения блокировки записи, прежде чем вернуть SQLITE_BUSY
+	BusyTimeout time.Duration
+	// ForeignKeys включает проверку внешних ключей (в SQLite по умолчанию выключена)
+	ForeignKeys bool
+	// Synchronous — когда сбрасывать данные на диск: normal достаточно надежен в режиме wal
+	Synchronous string
+
+	// MaxOpenConns и MaxIdleConns ограничивают пул соединений database/sql
+	MaxOpenConns int
+	MaxIdleConns int
+}
+
+// DefaultOptions возвращает настройки по умолчанию
+func DefaultOptions() Options {
+	return Options{
+		JournalMode:  "wal",
+		BusyTimeout:  5 * time.Second,
+		ForeignKeys:  true,
+		Synchronous:  "normal",
+		MaxOpenConns: 4,
+		MaxIdleConns: 4,
+	}
+}
+
+// dsn добавляет к пути параметры драйвера. Параметры применяются к каждому новому
+// соединению пула, поэтому настройки уровня соединения (busy_timeout, foreign_keys,
+// synchronous) действуют во всех запросах, а не только в первом.
+func (o Options) dsn(path string) string {
+	params := url.Values{}
+	params.Set("_journal_mode", o.JournalMode)
+	params.Set("_busy_timeout", strconv.FormatInt(o.BusyTimeout.Milliseconds(), 10))
+	params.Set("_foreign_keys", strconv.FormatBool(o.ForeignKeys))
+	params.Set("_synchronous", o.Synchronous)
+	// Транзакции сразу берут блокировку записи (BEGIN IMMEDIATE): иначе транзакция,
+	// начавшая с чтения, при одновременной записи из другого соединения получает
+	// "database is locked" без ожидания busy_timeout
+	params.Set("_txlock", "immediate")
+	return path + "?" + params.Encode()
+}
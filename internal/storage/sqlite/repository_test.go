@@ -0,0 +1,184 @@
+package sqlite
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"tasktracker/internal/domain/task"
+	"tasktracker/internal/logging"
+	"tasktracker/internal/storage/repotest"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepository(t *testing.T) {
+	repotest.Run(t, func(t *testing.T) task.Repository {
+		db, err := New(filepath.Join(t.TempDir(), "scheduler.db"), DefaultOptions(), logging.Discard())
+		require.NoError(t, err)
+		t.Cleanup(func() { db.Close() })
+		return NewRepository(db)
+	})
+}
+
+// С одним соединением запросы транзакции не могут ждать соединения из пула: оно занято ей же
+func TestRepositorySingleConnection(t *testing.T) {
+	opts := DefaultOptions()
+	opts.MaxOpenConns, opts.MaxIdleConns = 1, 1
+	repotest.Run(t, func(t *testing.T) task.Repository {
+		db, err := New(filepath.Join(t.TempDir(), "scheduler.db"), opts, logging.Discard())
+		require.NoError(t, err)
+		t.Cleanup(func() { db.Close() })
+		return NewRepository(db)
+	})
+}
+
+func TestStatementCache(t *testing.T) {
+	ctx := context.Background()
+	db, err := New(filepath.Join(t.TempDir(), "scheduler.db"), DefaultOptions(), logging.Discard())
+	require.NoError(t, err)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	// Запросы из транзакции Create попадают в кэш после ее завершения
+	tk := task.Task{Date: "20240115", Title: "Задача"}
+	require.NoError(t, repo.Create(ctx, &tk))
+	cached := len(db.stmts.stmts)
+	require.Positive(t, cached)
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, repo.Create(ctx, &task.Task{Date: "20240115", Title: "Задача"}))
+		_, err := repo.GetTaskByID(ctx, tk.ID)
+		require.NoError(t, err)
+	}
+	// GetTaskByID добавил один запрос, повторные вызовы новых не добавляют
+	require.Len(t, db.stmts.stmts, cached+1)
+	require.Empty(t, db.stmts.pending)
+}
+
+func TestAuditLogAppendOnly(t *testing.T) {
+	ctx := context.Background()
+	db, err := New(filepath.Join(t.TempDir(), "scheduler.db"), DefaultOptions(), logging.Discard())
+	require.NoError(t, err)
+	defer db.Close()
+
+	entry := task.AuditEntry{Time: time.Now(), Actor: "owner", Action: task.AuditCreate, TaskID: 1}
+	require.NoError(t, NewRepository(db).AppendAudit(ctx, &entry))
+
+	_, err = db.Exec("UPDATE audit_log SET actor = 'other'")
+	require.ErrorContains(t, err, "append-only")
+	_, err = db.Exec("DELETE FROM audit_log")
+	require.ErrorContains(t, err, "append-only")
+}
+
+// Запись в scheduler не должна требовать FTS5: индекс обновляет репозиторий, а изменения
+// других клиентов попадают в индекс при следующем запуске
+func TestFullTextWithoutTriggers(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "scheduler.db")
+	db, err := New(path, DefaultOptions(), logging.Discard())
+	require.NoError(t, err)
+
+	if db.fullText {
+		// Индекс прежних версий: external content и триггеры синхронизации
+		db.MustExec(`DROP TABLE scheduler_fts`)
+		db.MustExec(`CREATE VIRTUAL TABLE scheduler_fts USING fts5(title, comment, content='scheduler', content_rowid='id')`)
+		db.MustExec(`CREATE TRIGGER scheduler_fts_ai AFTER INSERT ON scheduler BEGIN
+            INSERT INTO scheduler_fts(rowid, title, comment) VALUES (new.id, new.title, new.comment);
+        END`)
+	}
+	require.NoError(t, db.Close())
+
+	db, err = New(path, DefaultOptions(), logging.Discard())
+	require.NoError(t, err)
+	var triggers int
+	require.NoError(t, db.Get(&triggers, `SELECT count(*) FROM sqlite_master WHERE type = 'trigger' AND name LIKE 'scheduler_fts%'`))
+	require.Zero(t, triggers)
+
+	// Так пишет клиент без FTS5
+	db.MustExec(`INSERT INTO scheduler (date, title, comment, repeat) VALUES ('20240115', 'Просмотр фильма', '', '')`)
+	require.NoError(t, db.Close())
+
+	db, err = New(path, DefaultOptions(), logging.Discard())
+	require.NoError(t, err)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	search := func(text string) int {
+		results, err := repo.Search(ctx, &task.SearchQuery{Terms: []task.SearchTerm{{Text: text}}, Limit: 10})
+		require.NoError(t, err)
+		return len(results)
+	}
+	require.Equal(t, 1, search("фильма"))
+
+	tk := task.Task{Date: "20240116", Title: "Покупки"}
+	require.NoError(t, repo.Create(ctx, &tk))
+	require.Equal(t, 1, search("покупки"))
+	tk.Title = "Продукты"
+	require.NoError(t, repo.UpdateTask(ctx, &tk))
+	require.Zero(t, search("покупки"))
+	require.Equal(t, 1, search("продукты"))
+	require.NoError(t, repo.DeleteTask(ctx, tk.ID))
+	require.Zero(t, search("продукты"))
+}
+
+// Списки, созданные до миграции 5, остаются без владельца и видны каждому пользователю
+func TestMigrationKeepsSavedListsShared(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "scheduler.db")
+
+	old, err := sqlx.Connect(driverName, path)
+	require.NoError(t, err)
+	for _, m := range migrations[:4] {
+		old.MustExec(m)
+	}
+	old.MustExec("PRAGMA user_version = 4")
+	old.MustExec("INSERT INTO saved_lists (name, query) VALUES ('Работа', 'title:отчет')")
+	require.NoError(t, old.Close())
+
+	db, err := New(path, DefaultOptions(), logging.Discard())
+	require.NoError(t, err)
+	defer db.Close()
+	repo := NewRepository(db)
+
+	want := []task.SavedList{{ID: 1, Name: "Работа", Query: "title:отчет"}}
+	for _, owner := range []string{"", "owner"} {
+		lists, err := repo.GetSavedLists(ctx, owner)
+		require.NoError(t, err)
+		require.Equal(t, want, lists, owner)
+		_, err = repo.GetSavedListByID(ctx, 1, owner)
+		require.NoError(t, err, owner)
+	}
+}
+
+// Прежние версии считали "../scheduler.db" файлом рядом с приложением
+func TestResolvePathLegacyParent(t *testing.T) {
+	appPath, err := os.Executable()
+	require.NoError(t, err)
+	appDir := filepath.Dir(appPath)
+	name := filepath.Base(t.TempDir()) + ".db"
+	dbFile := "../" + name
+	current, old := filepath.Join(appDir, "..", name), filepath.Join(appDir, name)
+	t.Cleanup(func() {
+		os.Remove(current)
+		os.Remove(old)
+	})
+
+	path, legacy, err := resolvePath(dbFile)
+	require.NoError(t, err)
+	require.Equal(t, current, path, "без файлов — новый путь")
+	require.False(t, legacy)
+
+	require.NoError(t, os.WriteFile(old, nil, 0o600))
+	path, legacy, err = resolvePath(dbFile)
+	require.NoError(t, err)
+	require.Equal(t, old, path)
+	require.True(t, legacy)
+
+	require.NoError(t, os.WriteFile(current, nil, 0o600))
+	path, err = ResolvePath(dbFile)
+	require.NoError(t, err)
+	require.Equal(t, current, path, "файл по новому пути важнее прежнего")
+}
@@ -0,0 +1,160 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// maxCachedStatements ограничивает кэш: запросы с фильтрами строятся динамически,
+// и без ограничения каждый новый фильтр оставался бы в памяти навсегда
+const maxCachedStatements = 128
+
+// stmtCache хранит подготовленные запросы по тексту SQL, чтобы SQLite не разбирал
+// один и тот же запрос при каждом вызове
+type stmtCache struct {
+	mu    sync.Mutex
+	stmts map[string]*sqlx.Stmt
+	// pending — запросы, встреченные в транзакциях: их готовят после завершения транзакции
+	pending map[string]struct{}
+}
+
+func newStmtCache() *stmtCache {
+	return &stmtCache{
+		stmts:   make(map[string]*sqlx.Stmt),
+		pending: make(map[string]struct{}),
+	}
+}
+
+func (c *stmtCache) lookup(query string) (*sqlx.Stmt, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	stmt, ok := c.stmts[query]
+	return stmt, ok
+}
+
+// prepare возвращает подготовленный запрос из кэша или готовит новый. Когда кэш заполнен,
+// новый запрос готовится для одного вызова, и его нужно закрыть (второй результат — true).
+// Блокировка не держится во время подготовки: ожидание свободного соединения не должно
+// останавливать поиск в кэше для других горутин.
+func (c *stmtCache) prepare(ctx context.Context, db *sqlx.DB, query string) (*sqlx.Stmt, bool, error) {
+	if stmt, ok := c.lookup(query); ok {
+		return stmt, false, nil
+	}
+	stmt, err := db.PreparexContext(ctx, query)
+	if err != nil {
+		return nil, false, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if cached, ok := c.stmts[query]; ok {
+		// Другая горутина успела подготовить тот же запрос
+		stmt.Close()
+		return cached, false, nil
+	}
+	if len(c.stmts) >= maxCachedStatements {
+		return stmt, true, nil
+	}
+	c.stmts[query] = stmt
+	return stmt, false, nil
+}
+
+// later откладывает подготовку запроса до завершения транзакции
+func (c *stmtCache) later(query string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.stmts)+len(c.pending) < maxCachedStatements {
+		c.pending[query] = struct{}{}
+	}
+}
+
+// preparePending готовит отложенные запросы; ошибки не важны — запрос будет отложен снова
+func (c *stmtCache) preparePending(ctx context.Context, db *sqlx.DB) {
+	c.mu.Lock()
+	queries := make([]string, 0, len(c.pending))
+	for query := range c.pending {
+		queries = append(queries, query)
+	}
+	clear(c.pending)
+	c.mu.Unlock()
+
+	for _, query := range queries {
+		if stmt, temporary, err := c.prepare(ctx, db, query); err == nil && temporary {
+			stmt.Close()
+		}
+	}
+}
+
+// close закрывает все подготовленные запросы
+func (c *stmtCache) close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var firstErr error
+	for query, stmt := range c.stmts {
+		if err := stmt.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+		delete(c.stmts, query)
+	}
+	return firstErr
+}
+
+// stmt возвращает подготовленный запрос, привязанный к транзакции репозитория, если она открыта.
+// Вызывающий обязан выполнить release после использования.
+func (r *Repository) stmt(ctx context.Context, query string) (stmt *sqlx.Stmt, release func(), err error) {
+	if r.tx == nil {
+		stmt, temporary, err := r.db.stmts.prepare(ctx, r.db.DB, query)
+		if err != nil {
+			return nil, nil, err
+		}
+		if temporary {
+			return stmt, func() { stmt.Close() }, nil
+		}
+		return stmt, func() {}, nil
+	}
+
+	if cached, ok := r.db.stmts.lookup(query); ok {
+		// Запрос готовится на соединении транзакции и закрывается вместе с ней
+		return r.tx.StmtxContext(ctx, cached), func() {}, nil
+	}
+	// Подготовка через пул ждала бы свободного соединения, а их все могут держать транзакции,
+	// в том числе эта. Поэтому запрос готовится в транзакции, а в кэш попадает после нее.
+	r.db.stmts.later(query)
+	stmt, err = r.tx.PreparexContext(ctx, query)
+	if err != nil {
+		return nil, nil, err
+	}
+	return stmt, func() { stmt.Close() }, nil
+}
+
+// get, selectAll и exec выполняют запрос через кэш подготовленных запросов
+func (r *Repository) get(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	stmt, release, err := r.stmt(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer release()
+	return stmt.GetContext(ctx, dest, args...)
+}
+
+func (r *Repository) selectAll(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	stmt, release, err := r.stmt(ctx, query)
+	if err != nil {
+		return err
+	}
+	defer release()
+	return stmt.SelectContext(ctx, dest, args...)
+}
+
+func (r *Repository) exec(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	stmt, release, err := r.stmt(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return stmt.ExecContext(ctx, args...)
+}
@@ -1,12 +1,23 @@
 package sqlite
 
 import (
+	"context"
+	"database/sql"
+	"errors"
 	"fmt"
+	"github.com/jmoiron/sqlx"
+	"github.com/mattn/go-sqlite3"
+	"strings"
 	"tasktracker/internal/domain/task"
+	"tasktracker/internal/i18n"
 )
 
+// taskColumns — колонки задачи в представлении tasks_view
+const taskColumns = "id, date, title, comment, repeat, overdue_policy"
+
 type Repository struct {
 	db *DB
+	tx *sqlx.Tx // Открытая транзакция у репозитория, переданного в WithTx
 }
 
 func NewRepository(db *DB) *Repository {
@@ -15,104 +26,262 @@ func NewRepository(db *DB) *Repository {
 	}
 }
 
-func (r *Repository) Create(t *task.Task) error {
+func (r *Repository) Create(ctx context.Context, t *task.Task) (err error) {
+	// NULL в id выдает следующий идентификатор, заданный id сохраняется как есть
 	query := `
-        INSERT INTO scheduler (date, title, comment, repeat)
-        VALUES (?, ?, ?, ?)
+        INSERT INTO scheduler (id, date, title, comment, repeat)
+        VALUES (?, ?, ?, ?, ?)
         RETURNING id`
 
-	row := r.db.QueryRow(query, t.Date, t.Title, t.Comment, t.Repeat)
-	if err := row.Scan(&t.ID); err != nil {
-		return fmt.Errorf("ошибка при создании задачи: %w", err)
+	ctx, span := startSpan(ctx, "Create", query)
+	defer span.end(&err)
+
+	err = r.inTx(ctx, func(tx *Repository) error {
+		err := tx.get(ctx, &t.ID, query, presetID(t.ID), t.Date, t.Title, t.Comment, t.Repeat)
+		if isPrimaryKeyConflict(err) {
+			return task.Conflict(i18n.TaskIDTaken, i18n.Params{"id": t.ID})
+		}
+		if err != nil {
+			return fmt.Errorf("ошибка при создании задачи: %w", err)
+		}
+		if err := tx.indexFullText(ctx, t.ID, t); err != nil {
+			return err
+		}
+		return tx.saveOverduePolicy(ctx, t.ID, t.OverduePolicy)
+	})
+	if err != nil {
+		return err
 	}
+	span.rows = 1
+	return nil
+}
 
+// saveOverduePolicy сохраняет политику просрочки задачи; политика по умолчанию не хранится
+func (r *Repository) saveOverduePolicy(ctx context.Context, id int64, policy string) error {
+	var err error
+	if policy == task.OverduePolicyAuto {
+		_, err = r.exec(ctx, "DELETE FROM task_overdue_policy WHERE task_id = ?", id)
+	} else {
+		_, err = r.exec(ctx, `
+            INSERT INTO task_overdue_policy (task_id, policy) VALUES (?, ?)
+            ON CONFLICT (task_id) DO UPDATE SET policy = excluded.policy`, id, policy)
+	}
+	if err != nil {
+		return fmt.Errorf("ошибка сохранения политики просрочки: %w", err)
+	}
 	return nil
 }
 
-func (r *Repository) GetTasks(query *task.ListQuery) ([]task.Task, error) {
+// presetID передает в INSERT заданный идентификатор или NULL, чтобы SQLite выдал следующий
+func presetID(id int64) sql.NullInt64 {
+	return sql.NullInt64{Int64: id, Valid: id != 0}
+}
+
+// isPrimaryKeyConflict сообщает, что запись с таким идентификатором уже есть
+func isPrimaryKeyConflict(err error) bool {
+	var sqliteErr sqlite3.Error
+	return errors.As(err, &sqliteErr) && sqliteErr.ExtendedCode == sqlite3.ErrConstraintPrimaryKey
+}
+
+func (r *Repository) GetTasks(ctx context.Context, query *task.ListQuery) (_ []task.Task, err error) {
 	var tasks []task.Task
 	var queryStr string
 	var args []interface{}
 
-	queryStr = "SELECT id, date, title, comment, repeat FROM scheduler"
+	queryStr = "SELECT " + taskColumns + " FROM tasks_view"
 
-	hasConditions := false
+	var conditions []string
 
-	if query.Date != "" {
-		queryStr += " WHERE date = ?"
-		args = append(args, query.Date)
-		hasConditions = true
+	if query.Filter != nil {
+		where, filterArgs, err := compileFilter(query.Filter)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка построения фильтра: %w", err)
+		}
+		conditions = append(conditions, where)
+		args = append(args, filterArgs...)
 	}
 
-	if query.Comment != "" {
-		if hasConditions {
-			queryStr += " AND"
-		} else {
-			queryStr += " WHERE"
-		}
-		queryStr += " title LIKE ?"
-		args = append(args, fmt.Sprintf("%%%s%%", query.Comment))
+	if query.After != nil {
+		conditions = append(conditions, "(date > ? OR (date = ? AND id > ?))")
+		args = append(args, query.After.Date, query.After.Date, query.After.ID)
 	}
 
-	queryStr += " ORDER BY date ASC LIMIT ?"
+	if len(conditions) > 0 {
+		queryStr += " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	queryStr += " ORDER BY date ASC, id ASC LIMIT ?"
 	args = append(args, query.Limit)
 
-	err := r.db.Select(&tasks, queryStr, args...)
-	if err != nil {
+	ctx, span := startSpan(ctx, "GetTasks", queryStr)
+	defer span.end(&err)
+
+	if err := r.selectAll(ctx, &tasks, queryStr, args...); err != nil {
 		return nil, fmt.Errorf("ошибка выборки задач: %w", err)
 	}
 
+	span.rows = int64(len(tasks))
 	return tasks, nil
 }
 
-func (r *Repository) GetTaskByID(id int64) (*task.Task, error) {
-	var task task.Task
-	err := r.db.Get(&task, `SELECT id, date, title, comment, repeat FROM scheduler WHERE id = ?`, id)
-	if err != nil {
-		return nil, fmt.Errorf("задача не найдена")
+func (r *Repository) Search(ctx context.Context, query *task.SearchQuery) ([]task.SearchResult, error) {
+	if r.db.fullText {
+		return r.searchFullText(ctx, query)
 	}
-	return &task, nil
+	return r.searchLike(ctx, query)
 }
 
-func (r *Repository) UpdateTask(t *task.Task) error {
-	result, err := r.db.Exec(`
-        UPDATE scheduler 
-        SET date = ?, title = ?, comment = ?, repeat = ?
-        WHERE id = ?`,
-		t.Date, t.Title, t.Comment, t.Repeat, t.ID)
-	if err != nil {
-		return fmt.Errorf("ошибка обновления задачи: %w", err)
-	}
+// searchFullText ищет по индексу FTS5, заголовок весит больше комментария
+func (r *Repository) searchFullText(ctx context.Context, query *task.SearchQuery) (_ []task.SearchResult, err error) {
+	statement := `
+        SELECT s.id, s.date, s.title, s.comment, s.repeat, s.overdue_policy,
+            COALESCE(highlight(scheduler_fts, 0, ?, ?), '') AS title_highlight,
+            COALESCE(snippet(scheduler_fts, 1, ?, ?, '…', 16), '') AS comment_highlight,
+            bm25(scheduler_fts, 10.0, 1.0) AS rank
+        FROM scheduler_fts
+        JOIN tasks_view s ON s.id = scheduler_fts.rowid
+        WHERE scheduler_fts MATCH ?
+        ORDER BY rank, s.date, s.id
+        LIMIT ?`
 
-	rows, err := result.RowsAffected()
+	ctx, span := startSpan(ctx, "Search", statement)
+	defer span.end(&err)
+
+	var results []task.SearchResult
+	err = r.selectAll(ctx, &results, statement,
+		ftsMarkStart, ftsMarkEnd,
+		ftsMarkStart, ftsMarkEnd,
+		ftsExpression(query.Terms), query.Limit)
 	if err != nil {
-		return fmt.Errorf("ошибка получения количества обновленных строк: %w", err)
+		return nil, fmt.Errorf("ошибка полнотекстового поиска: %w", err)
 	}
-	if rows == 0 {
-		return fmt.Errorf("задача не найдена")
+	for i := range results {
+		results[i].TitleHighlight = ftsHighlight(results[i].TitleHighlight)
+		results[i].CommentHighlight = ftsHighlight(results[i].CommentHighlight)
 	}
 
-	return nil
+	span.rows = int64(len(results))
+	return results, nil
 }
-func (r *Repository) DeleteTask(id int64) error {
-	result, err := r.db.Exec("DELETE FROM scheduler WHERE id = ?", id)
-	if err != nil {
-		return fmt.Errorf("ошибка удаления задачи: %w", err)
+
+// searchLike используется, если SQLite собран без FTS5: подстрока без учета регистра, порядок по дате
+func (r *Repository) searchLike(ctx context.Context, query *task.SearchQuery) (_ []task.SearchResult, err error) {
+	queryStr := "SELECT " + taskColumns + " FROM tasks_view"
+	var conditions []string
+	var args []interface{}
+
+	for _, term := range query.Terms {
+		pattern := likePattern(term.Text)
+		conditions = append(conditions, `(unicode_lower(title) LIKE ? ESCAPE '\' OR unicode_lower(comment) LIKE ? ESCAPE '\')`)
+		args = append(args, pattern, pattern)
 	}
+	if len(conditions) > 0 {
+		queryStr += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	queryStr += " ORDER BY date ASC, id ASC LIMIT ?"
+	args = append(args, query.Limit)
 
-	rows, err := result.RowsAffected()
-	if err != nil {
-		return fmt.Errorf("ошибка получения количества удаленных строк: %w", err)
+	ctx, span := startSpan(ctx, "Search", queryStr)
+	defer span.end(&err)
+
+	var tasks []task.Task
+	if err := r.selectAll(ctx, &tasks, queryStr, args...); err != nil {
+		return nil, fmt.Errorf("ошибка поиска задач: %w", err)
 	}
-	if rows == 0 {
-		return fmt.Errorf("задача не найдена")
+	span.rows = int64(len(tasks))
+
+	results := make([]task.SearchResult, 0, len(tasks))
+	for _, t := range tasks {
+		results = append(results, task.SearchResult{
+			Task:             t,
+			TitleHighlight:   task.Highlight(t.Title, query.Terms),
+			CommentHighlight: task.Highlight(t.Comment, query.Terms),
+		})
 	}
+	return results, nil
+}
 
-	return nil
+func (r *Repository) GetTaskByID(ctx context.Context, id int64) (_ *task.Task, err error) {
+	query := "SELECT " + taskColumns + " FROM tasks_view WHERE id = ?"
+
+	ctx, span := startSpan(ctx, "GetTaskByID", query)
+	defer span.end(&err)
+
+	var t task.Task
+	err = r.get(ctx, &t, query, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, task.NotFound(i18n.TaskNotFound)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ошибка получения задачи: %w", err)
+	}
+	span.rows = 1
+	return &t, nil
+}
+
+func (r *Repository) UpdateTask(ctx context.Context, t *task.Task) (err error) {
+	query := `
+        UPDATE scheduler 
+        SET date = ?, title = ?, comment = ?, repeat = ?
+        WHERE id = ?`
+
+	ctx, span := startSpan(ctx, "UpdateTask", query)
+	defer span.end(&err)
+
+	return r.inTx(ctx, func(tx *Repository) error {
+		result, err := tx.exec(ctx, query, t.Date, t.Title, t.Comment, t.Repeat, t.ID)
+		if err != nil {
+			return fmt.Errorf("ошибка обновления задачи: %w", err)
+		}
+
+		rows, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("ошибка получения количества обновленных строк: %w", err)
+		}
+		span.rows = rows
+		if rows == 0 {
+			return task.NotFound(i18n.TaskNotFound)
+		}
+
+		if err := tx.indexFullText(ctx, t.ID, t); err != nil {
+			return err
+		}
+		return tx.saveOverduePolicy(ctx, t.ID, t.OverduePolicy)
+	})
+}
+
+func (r *Repository) DeleteTask(ctx context.Context, id int64) (err error) {
+	query := "DELETE FROM scheduler WHERE id = ?"
+
+	ctx, span := startSpan(ctx, "DeleteTask", query)
+	defer span.end(&err)
+
+	return r.inTx(ctx, func(tx *Repository) error {
+		result, err := tx.exec(ctx, query, id)
+		if err != nil {
+			return fmt.Errorf("ошибка удаления задачи: %w", err)
+		}
+
+		rows, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("ошибка получения количества удаленных строк: %w", err)
+		}
+		span.rows = rows
+		if rows == 0 {
+			return task.NotFound(i18n.TaskNotFound)
+		}
+
+		return tx.indexFullText(ctx, id, nil)
+	})
 }
 
-func (r *Repository) UpdateTaskDate(id int64, newDate string) error {
-	result, err := r.db.Exec("UPDATE scheduler SET date = ? WHERE id = ?", newDate, id)
+func (r *Repository) UpdateTaskDate(ctx context.Context, id int64, newDate string) (err error) {
+	query := "UPDATE scheduler SET date = ? WHERE id = ?"
+
+	ctx, span := startSpan(ctx, "UpdateTaskDate", query)
+	defer span.end(&err)
+
+	result, err := r.exec(ctx, query, newDate, id)
 	if err != nil {
 		return fmt.Errorf("ошибка обновления даты задачи: %w", err)
 	}
@@ -121,9 +290,28 @@ func (r *Repository) UpdateTaskDate(id int64, newDate string) error {
 	if err != nil {
 		return fmt.Errorf("ошибка получения количества обновленных строк: %w", err)
 	}
+	span.rows = rows
 	if rows == 0 {
-		return fmt.Errorf("задача не найдена")
+		return task.NotFound(i18n.TaskNotFound)
 	}
 
 	return nil
 }
+
+func (r *Repository) GetStats(ctx context.Context, today string) (_ *task.Stats, err error) {
+	query := `
+        SELECT
+            COALESCE(SUM(date >= ?), 0) AS upcoming,
+            COALESCE(SUM(date < ?), 0) AS overdue,
+            COALESCE(SUM(COALESCE(repeat, '') != ''), 0) AS recurring
+        FROM scheduler`
+
+	ctx, span := startSpan(ctx, "GetStats", query)
+	defer span.end(&err)
+
+	var stats task.Stats
+	if err := r.get(ctx, &stats, query, today, today); err != nil {
+		return nil, fmt.Errorf("ошибка подсчета задач: %w", err)
+	}
+	return &stats, nil
+}
@@ -0,0 +1,42 @@
+package sqlite
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("tasktracker/internal/storage/sqlite")
+
+// querySpan — span одного метода репозитория. rows заполняется методом
+// и попадает в атрибут db.rows: число прочитанных или измененных строк.
+type querySpan struct {
+	trace.Span
+	rows int64
+}
+
+// startSpan открывает span запроса с текстом SQL в атрибуте db.query.text
+func startSpan(ctx context.Context, method, statement string) (context.Context, *querySpan) {
+	ctx, span := tracer.Start(ctx, "sqlite."+method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemSqlite,
+			semconv.DBOperationName(method),
+			semconv.DBQueryText(statement),
+		))
+	return ctx, &querySpan{Span: span}
+}
+
+// end завершает span; вызывается через defer с указателем на именованный результат err
+func (s *querySpan) end(err *error) {
+	s.SetAttributes(attribute.Int64("db.rows", s.rows))
+	if *err != nil {
+		s.RecordError(*err)
+		s.SetStatus(codes.Error, (*err).Error())
+	}
+	s.End()
+}
@@ -0,0 +1,42 @@
+package sqlite
+
+import (
+	"context"
+	"fmt"
+	"tasktracker/internal/domain/task"
+)
+
+// WithTx выполняет fn в одной транзакции. Транзакции SQLite начинаются с BEGIN IMMEDIATE
+// (см. New), поэтому единицы работы выполняются по очереди, а не читают одни и те же
+// данные одновременно.
+func (r *Repository) WithTx(ctx context.Context, fn func(tx task.Repository) error) error {
+	return r.inTx(ctx, func(tx *Repository) error {
+		return fn(tx)
+	})
+}
+
+// inTx выполняет fn в новой транзакции или, если репозиторий уже работает в транзакции, в ней же
+func (r *Repository) inTx(ctx context.Context, fn func(tx *Repository) error) (err error) {
+	if r.tx != nil {
+		return fn(r)
+	}
+
+	ctx, span := startSpan(ctx, "WithTx", "BEGIN IMMEDIATE")
+	defer span.end(&err)
+
+	tx, err := r.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("не удалось начать транзакцию: %w", err)
+	}
+	// Отложенные defer выполняются в обратном порядке: запросы готовятся, когда соединение транзакции уже свободно
+	defer r.db.stmts.preparePending(context.WithoutCancel(ctx), r.db.DB)
+	defer tx.Rollback()
+
+	if err := fn(&Repository{db: r.db, tx: tx}); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("не удалось зафиксировать транзакцию: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,245 @@
+// Package taskcsv читает и пишет задачи в формате CSV для работы с таблицами.
+//
+// Выгрузка содержит столбцы id, date, title, comment и repeat. При загрузке столбцы
+// сопоставляются полям задачи по названиям из заголовка (Mapping), поэтому подходит
+// таблица с любым порядком и названиями столбцов. Разделитель (запятая, точка с запятой
+// или табуляция) определяется по строке заголовка, метка порядка байтов UTF-8 пропускается.
+//
+// Табличные редакторы выполняют ячейки, начинающиеся с =, +, -, @, табуляции или возврата
+// каретки, как формулы. Поэтому такие заголовок и комментарий выгружаются с апострофом
+// в начале (редакторы показывают его как признак текста), а при загрузке апостроф снимается.
+package taskcsv
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"tasktracker/internal/domain/task"
+	"tasktracker/internal/i18n"
+)
+
+// Header — заголовок выгрузки; его же по умолчанию ожидает загрузка
+var Header = []string{"id", "date", "title", "comment", "repeat"}
+
+// bom — метка порядка байтов UTF-8, которую добавляют табличные редакторы
+var bom = []byte{0xef, 0xbb, 0xbf}
+
+// Writer пишет задачи в CSV, начиная со строки заголовка
+type Writer struct {
+	w           *csv.Writer
+	wroteHeader bool
+}
+
+// NewWriter создает Writer с разделителем comma
+func NewWriter(w io.Writer, comma rune) *Writer {
+	cw := csv.NewWriter(w)
+	cw.Comma = comma
+	return &Writer{w: cw}
+}
+
+// Write пишет задачу; перед первой задачей пишется заголовок
+func (w *Writer) Write(t task.Task) error {
+	if err := w.writeHeader(); err != nil {
+		return err
+	}
+	return w.w.Write([]string{strconv.FormatInt(t.ID, 10), t.Date, escapeFormula(t.Title), escapeFormula(t.Comment), t.Repeat})
+}
+
+// formulaPrefix — символы, с которых табличные редакторы начинают формулу
+const formulaPrefix = "=+-@\t\r"
+
+// looksLikeFormula сообщает, что ячейку s нужно выгрузить с апострофом. Значения, которые
+// уже выглядят экранированными, тоже экранируются, чтобы загрузка вернула их без изменений.
+func looksLikeFormula(s string) bool {
+	for strings.HasPrefix(s, "'") {
+		s = s[1:]
+	}
+	return s != "" && strings.ContainsRune(formulaPrefix, rune(s[0]))
+}
+
+// escapeFormula добавляет апостроф перед значением, которое редактор принял бы за формулу
+func escapeFormula(s string) string {
+	if looksLikeFormula(s) {
+		return "'" + s
+	}
+	return s
+}
+
+// unescapeFormula снимает апостроф, добавленный escapeFormula
+func unescapeFormula(s string) string {
+	if rest, ok := strings.CutPrefix(s, "'"); ok && looksLikeFormula(rest) {
+		return rest
+	}
+	return s
+}
+
+// Flush дописывает буферизованные строки; пустая выгрузка все равно получает заголовок
+func (w *Writer) Flush() error {
+	if err := w.writeHeader(); err != nil {
+		return err
+	}
+	w.w.Flush()
+	return w.w.Error()
+}
+
+func (w *Writer) writeHeader() error {
+	if w.wroteHeader {
+		return nil
+	}
+	w.wroteHeader = true
+	return w.w.Write(Header)
+}
+
+// Mapping задает столбцы для полей задачи: название столбца в заголовке (без учета
+// регистра) или его номер, начиная с 1. Пустое значение — столбец с названием поля
+// из Header, если он есть; нулевой Mapping читает файлы выгрузки.
+type Mapping struct {
+	Date    string
+	Title   string
+	Comment string
+	Repeat  string
+}
+
+// Row — задача из одной строки файла или ошибка в этой строке
+type Row struct {
+	Line int // Номер строки в файле, с 1; заголовок — строка 1
+	Task task.Task
+	Err  error
+}
+
+// Reader читает задачи из CSV
+type Reader struct {
+	r       *csv.Reader
+	columns [4]int // Индексы столбцов date, title, comment, repeat; -1 — столбца нет
+}
+
+// NewReader читает заголовок и сопоставляет столбцы полям по m. Столбец заголовка задачи
+// и столбцы, явно указанные в m, должны быть в файле; иначе возвращается ошибка валидации
+// с полем date, title, comment или repeat.
+func NewReader(r io.Reader, m Mapping) (*Reader, error) {
+	br := bufio.NewReader(r)
+	if prefix, _ := br.Peek(len(bom)); bytes.Equal(prefix, bom) {
+		br.Discard(len(bom))
+	}
+
+	cr := csv.NewReader(br)
+	cr.Comma = sniffComma(br)
+	cr.FieldsPerRecord = -1
+	cr.ReuseRecord = true
+
+	header, err := cr.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, task.Invalid("file", i18n.CSVEmpty, nil)
+	}
+	if err != nil {
+		return nil, task.Invalid("file", i18n.CSVRowInvalid, i18n.Params{"cause": err})
+	}
+
+	reader := &Reader{r: cr}
+	for i, field := range []struct{ name, column string }{
+		{"date", m.Date},
+		{"title", m.Title},
+		{"comment", m.Comment},
+		{"repeat", m.Repeat},
+	} {
+		required := field.column != "" || field.name == "title"
+		if field.column == "" {
+			field.column = field.name
+		}
+		index, ok := findColumn(header, field.column)
+		if !ok && required {
+			return nil, task.Invalid(field.name, i18n.CSVColumnUnknown, i18n.Params{"column": field.column, "field": field.name})
+		}
+		if !ok {
+			index = -1
+		}
+		reader.columns[i] = index
+	}
+	return reader, nil
+}
+
+// findColumn ищет столбец по названию, а если такого нет — по номеру
+func findColumn(header []string, column string) (int, bool) {
+	for i, name := range header {
+		if strings.EqualFold(strings.TrimSpace(name), strings.TrimSpace(column)) {
+			return i, true
+		}
+	}
+	if n, err := strconv.Atoi(column); err == nil && n >= 1 && n <= len(header) {
+		return n - 1, true
+	}
+	return 0, false
+}
+
+// sniffComma выбирает разделитель, который чаще встречается в первой строке
+func sniffComma(br *bufio.Reader) rune {
+	data, _ := br.Peek(br.Size())
+	if i := bytes.IndexByte(data, '\n'); i >= 0 {
+		data = data[:i]
+	}
+	comma, best := ',', bytes.Count(data, []byte{','})
+	for _, candidate := range []rune{';', '\t'} {
+		if n := bytes.Count(data, []byte(string(candidate))); n > best {
+			comma, best = candidate, n
+		}
+	}
+	return comma
+}
+
+// Next возвращает следующую непустую строку файла; в конце файла — io.EOF.
+// Ошибки в строке (некорректный CSV или дата) возвращаются в Row.Err: остальные строки
+// можно читать дальше. Даты принимаются в форматах YYYYMMDD и DD.MM.YYYY.
+func (r *Reader) Next() (Row, error) {
+	for {
+		record, err := r.r.Read()
+		if errors.Is(err, io.EOF) {
+			return Row{}, io.EOF
+		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			return Row{Line: parseErr.StartLine, Err: task.Invalid("", i18n.CSVRowInvalid, i18n.Params{"cause": parseErr.Err})}, nil
+		}
+		if err != nil {
+			return Row{}, err
+		}
+		line, _ := r.r.FieldPos(0)
+		if blank(record) {
+			continue
+		}
+
+		value := func(i int) string {
+			if c := r.columns[i]; c >= 0 && c < len(record) {
+				return strings.TrimSpace(record[c])
+			}
+			return ""
+		}
+		row := Row{Line: line, Task: task.Task{
+			Date:    value(0),
+			Title:   unescapeFormula(value(1)),
+			Comment: unescapeFormula(value(2)),
+			Repeat:  value(3),
+		}}
+		if row.Task.Date != "" && row.Task.Date != "today" {
+			if date, err := task.NormalizeDate(row.Task.Date); err != nil {
+				row.Err = task.Invalid("date", i18n.CSVDateInvalid, i18n.Params{"value": row.Task.Date})
+			} else {
+				row.Task.Date = date
+			}
+		}
+		return row, nil
+	}
+}
+
+// blank сообщает, что все ячейки строки пусты: такие строки таблицы пропускаются
+func blank(record []string) bool {
+	for _, v := range record {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,128 @@
+package taskcsv
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"tasktracker/internal/domain/task"
+	"tasktracker/internal/i18n"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readAll читает все строки файла
+func readAll(t *testing.T, input string, m Mapping) []Row {
+	t.Helper()
+	r, err := NewReader(strings.NewReader(input), m)
+	require.NoError(t, err)
+	var rows []Row
+	for {
+		row, err := r.Next()
+		if errors.Is(err, io.EOF) {
+			return rows
+		}
+		require.NoError(t, err)
+		rows = append(rows, row)
+	}
+}
+
+// codeOf возвращает код ошибки строки
+func codeOf(err error) i18n.Code {
+	var e *task.Error
+	if errors.As(err, &e) {
+		return e.Innermost().Code
+	}
+	return ""
+}
+
+func TestWriteRead(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf, ',')
+	require.NoError(t, w.Write(task.Task{ID: 7, Date: "20240115", Title: "Отчет, квартальный", Comment: "строка 1\nстрока 2", Repeat: "m 15"}))
+	require.NoError(t, w.Flush())
+	assert.Equal(t, "id,date,title,comment,repeat\n7,20240115,\"Отчет, квартальный\",\"строка 1\nстрока 2\",m 15\n", buf.String())
+
+	rows := readAll(t, buf.String(), Mapping{})
+	require.Len(t, rows, 1)
+	assert.Equal(t, task.Task{Date: "20240115", Title: "Отчет, квартальный", Comment: "строка 1\nстрока 2", Repeat: "m 15"}, rows[0].Task)
+	assert.Equal(t, 2, rows[0].Line)
+
+	var empty bytes.Buffer
+	require.NoError(t, NewWriter(&empty, ';').Flush())
+	assert.Equal(t, "id;date;title;comment;repeat\n", empty.String())
+}
+
+func TestWriteReadFormulas(t *testing.T) {
+	values := []string{"=HYPERLINK(\"http://example.com\")", "+7 999", "-1", "@SUM(A1)", "\tотступ", "'=уже с апострофом", "'цитата", "Обычная"}
+	var buf bytes.Buffer
+	w := NewWriter(&buf, ',')
+	for _, v := range values {
+		require.NoError(t, w.Write(task.Task{Date: "20240115", Title: v, Comment: v}))
+	}
+	require.NoError(t, w.Flush())
+
+	// Ни одна ячейка заголовка или комментария не начинается с символа формулы
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	assert.Contains(t, lines[1], `,"'=HYPERLINK(""http://example.com"")",`)
+	assert.Equal(t, "0,20240115,'-1,'-1,", lines[3])
+	assert.Equal(t, "0,20240115,''=уже с апострофом,''=уже с апострофом,", lines[6])
+	assert.Equal(t, "0,20240115,'цитата,'цитата,", lines[7], "апостроф без формулы не удваивается")
+
+	rows := readAll(t, buf.String(), Mapping{})
+	require.Len(t, rows, len(values))
+	for i, v := range values {
+		assert.Equal(t, v, rows[i].Task.Title)
+		assert.Equal(t, v, rows[i].Task.Comment)
+	}
+}
+
+func TestReadMapping(t *testing.T) {
+	// Таблица из редактора: метка BOM, точка с запятой, свои названия столбцов и пустая строка
+	input := "\ufeffЗадача;Заметки;Срок;Лишний\n" +
+		"Позвонить;;28.01.2024;x\n" +
+		";;;\n" +
+		"Отчет;квартальный;20240131;\n" +
+		"Дата;;31.02.2024;\n" +
+		"Без даты\n"
+	rows := readAll(t, input, Mapping{Date: "срок", Title: "ЗАДАЧА", Comment: "2"})
+	require.Len(t, rows, 4)
+
+	assert.Equal(t, task.Task{Date: "20240128", Title: "Позвонить"}, rows[0].Task)
+	assert.NoError(t, rows[0].Err)
+	assert.Equal(t, task.Task{Date: "20240131", Title: "Отчет", Comment: "квартальный"}, rows[1].Task)
+	assert.Equal(t, 4, rows[1].Line, "пустая строка пропускается, но учитывается в номерах")
+	assert.Equal(t, i18n.CSVDateInvalid, codeOf(rows[2].Err))
+	assert.Equal(t, 5, rows[2].Line)
+	assert.Equal(t, task.Task{Title: "Без даты"}, rows[3].Task, "недостающие ячейки пусты")
+}
+
+func TestReadErrors(t *testing.T) {
+	_, err := NewReader(strings.NewReader(""), Mapping{})
+	assert.Equal(t, i18n.CSVEmpty, codeOf(err))
+
+	_, err = NewReader(strings.NewReader("name,date\n"), Mapping{})
+	assert.Equal(t, i18n.CSVColumnUnknown, codeOf(err), "без столбца заголовка задачи")
+	_, err = NewReader(strings.NewReader("title,due\n"), Mapping{Date: "date"})
+	assert.Equal(t, i18n.CSVColumnUnknown, codeOf(err), "явно указанный столбец должен быть в файле")
+	var e *task.Error
+	require.True(t, errors.As(err, &e))
+	assert.Equal(t, "date", e.Field)
+
+	// Столбцы по умолчанию, кроме title, необязательны
+	rows := readAll(t, "title\nТолько заголовок\n", Mapping{})
+	require.Len(t, rows, 1)
+	assert.Equal(t, task.Task{Title: "Только заголовок"}, rows[0].Task)
+
+	_, err = NewReader(strings.NewReader("title\n"), Mapping{Title: "9"})
+	assert.Equal(t, i18n.CSVColumnUnknown, codeOf(err), "номер столбца за пределами заголовка")
+
+	// Некорректная строка не мешает читать следующие
+	rows = readAll(t, "title,date\nПроект \"Альфа\",20240101\n\"Проект \"\"Бета\"\"\",20240102\n", Mapping{Title: "title", Date: "date"})
+	require.Len(t, rows, 2)
+	assert.Equal(t, i18n.CSVRowInvalid, codeOf(rows[0].Err))
+	assert.Equal(t, 2, rows[0].Line)
+	assert.Equal(t, `Проект "Бета"`, rows[1].Task.Title)
+}
@@ -0,0 +1,95 @@
+// Package tracing настраивает трассировку OpenTelemetry: провайдер, экспортер и
+// распространение контекста в формате W3C Trace Context (заголовок traceparent).
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+// Экспортеры, которые можно выбрать в настройках
+const (
+	ExporterNone   = "none"
+	ExporterStdout = "stdout"
+	ExporterOTLP   = "otlp"
+)
+
+// ServiceName — имя сервиса в атрибуте service.name
+const ServiceName = "tasktracker"
+
+// Provider владеет провайдером трассировки и должен быть остановлен через Shutdown,
+// чтобы выгрузить накопленные span'ы
+type Provider struct {
+	tp *sdktrace.TracerProvider
+}
+
+// New настраивает глобальные провайдер и пропагатор OpenTelemetry.
+//
+// exporter выбирает, куда отправлять span'ы:
+//
+//	none   — никуда, span'ы не записываются (traceparent все равно принимается);
+//	stdout — JSON в w, удобно при разработке;
+//	otlp   — OTLP/HTTP на endpoint (например, http://localhost:4318); при пустом endpoint
+//	         используются переменные OTEL_EXPORTER_OTLP_*.
+func New(ctx context.Context, exporter, endpoint string, w io.Writer) (*Provider, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	var spanExporter sdktrace.SpanExporter
+	switch exporter {
+	case ExporterNone, "":
+		return &Provider{}, nil
+	case ExporterStdout:
+		exp, err := stdouttrace.New(stdouttrace.WithWriter(w))
+		if err != nil {
+			return nil, fmt.Errorf("ошибка создания stdout-экспортера: %w", err)
+		}
+		spanExporter = exp
+	case ExporterOTLP:
+		var opts []otlptracehttp.Option
+		if endpoint != "" {
+			opts = append(opts, otlptracehttp.WithEndpointURL(endpoint))
+		}
+		exp, err := otlptracehttp.New(ctx, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка создания OTLP-экспортера: %w", err)
+		}
+		spanExporter = exp
+	default:
+		return nil, fmt.Errorf("неизвестный экспортер трассировки %q", exporter)
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(spanExporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.AlwaysSample())),
+		sdktrace.WithResource(resource.NewWithAttributes(
+			semconv.SchemaURL,
+			semconv.ServiceName(ServiceName),
+		)),
+	)
+	otel.SetTracerProvider(tp)
+
+	return &Provider{tp: tp}, nil
+}
+
+// Shutdown выгружает оставшиеся span'ы и останавливает экспортер
+func (p *Provider) Shutdown(ctx context.Context) error {
+	if p.tp == nil {
+		return nil
+	}
+	if err := p.tp.Shutdown(ctx); err != nil {
+		return fmt.Errorf("ошибка остановки трассировки: %w", err)
+	}
+	return nil
+}
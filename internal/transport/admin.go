@@ -0,0 +1,78 @@
+package transport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"tasktracker/internal/backup"
+	"tasktracker/internal/i18n"
+	"time"
+)
+
+// Backup создает резервные копии БД (см. backup.Manager)
+type Backup interface {
+	// Write записывает копию в w; если копию сделать не удалось, в w ничего не записано
+	Write(ctx context.Context, w io.Writer, compress bool) error
+	// Create сохраняет копию в каталоге копий и возвращает путь к ней
+	Create(ctx context.Context) (string, error)
+}
+
+type backupResponse struct {
+	File string `json:"file"`
+}
+
+// handleBackup выгружает копию БД (GET, ?gzip=1 — сжатую) или сохраняет ее в каталоге копий на сервере (POST).
+// Без аутентификации копию всей БД мог бы скачать любой, кто достучался до порта, поэтому
+// эндпоинт работает, только если задан секрет.
+func (h *Handler) handleBackup(w http.ResponseWriter, r *http.Request) {
+	if h.backup == nil {
+		writeProblem(w, r, http.StatusNotImplemented, i18n.BackupUnsupported, nil)
+		return
+	}
+	if !h.auth.Enabled() {
+		writeProblem(w, r, http.StatusForbidden, i18n.BackupAuthRequired, nil)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		compress := r.FormValue("gzip") == "1" || r.FormValue("gzip") == "true"
+		name := "scheduler-" + time.Now().UTC().Format("20060102T150405Z") + ".db"
+		contentType := "application/vnd.sqlite3"
+		if compress {
+			name += ".gz"
+			contentType = "application/gzip"
+		}
+
+		// Заголовки ответа с ошибкой заменят эти: пока копия не готова, тело не пишется
+		header := w.Header()
+		header.Set("Content-Type", contentType)
+		header.Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name))
+		header.Set("Cache-Control", "no-store")
+		if err := h.backup.Write(r.Context(), w, compress); err != nil {
+			header.Del("Content-Disposition")
+			h.writeError(w, r, err)
+		}
+
+	case http.MethodPost:
+		w.Header().Set("Content-Type", "application/json")
+		path, err := h.backup.Create(r.Context())
+		if errors.Is(err, backup.ErrNoDir) {
+			writeProblem(w, r, http.StatusConflict, i18n.BackupDirMissing, nil)
+			return
+		}
+		if err != nil {
+			h.writeError(w, r, err)
+			return
+		}
+		h.logger.InfoContext(r.Context(), "backup created", "path", path)
+		writeJSON(r.Context(), w, backupResponse{File: filepath.Base(path)}, http.StatusOK)
+
+	default:
+		w.Header().Set("Content-Type", "application/json")
+		h.writeError(w, r, errMethodNotAllowed)
+	}
+}
@@ -0,0 +1,95 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"tasktracker/internal/backup"
+	"tasktracker/internal/logging"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeBackup struct {
+	err error
+}
+
+func (b *fakeBackup) Write(_ context.Context, w io.Writer, compress bool) error {
+	if b.err != nil {
+		return b.err
+	}
+	if compress {
+		_, err := w.Write([]byte("gzip"))
+		return err
+	}
+	_, err := w.Write([]byte("SQLite format 3"))
+	return err
+}
+
+func (b *fakeBackup) Create(context.Context) (string, error) {
+	if b.err != nil {
+		return "", b.err
+	}
+	return "/backups/backup-20240115T100000.000Z.db.gz", nil
+}
+
+func TestBackupEndpoint(t *testing.T) {
+	b := &fakeBackup{}
+	auth := NewAuth("secret")
+	serve := func(opts []HandlerOption, method, target string) *httptest.ResponseRecorder {
+		h := NewHandler(nil, http.NotFoundHandler(), auth, logging.Discard(), opts...)
+		rec := httptest.NewRecorder()
+		h.RegisterRoutes().ServeHTTP(rec, httptest.NewRequest(method, target, nil))
+		return rec
+	}
+	withBackup := []HandlerOption{WithBackup(b)}
+
+	rec := serve(withBackup, http.MethodGet, "/api/admin/backup")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/vnd.sqlite3", rec.Header().Get("Content-Type"))
+	assert.Contains(t, rec.Header().Get("Content-Disposition"), `.db"`)
+	assert.Equal(t, "SQLite format 3", rec.Body.String())
+
+	rec = serve(withBackup, http.MethodGet, "/api/admin/backup?gzip=1")
+	assert.Equal(t, "application/gzip", rec.Header().Get("Content-Type"))
+	assert.Contains(t, rec.Header().Get("Content-Disposition"), `.db.gz"`)
+
+	rec = serve(withBackup, http.MethodPost, "/api/admin/backup")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	var resp map[string]string
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+	assert.Equal(t, "backup-20240115T100000.000Z.db.gz", resp["file"], "клиент не видит путей на сервере")
+
+	b.err = backup.ErrNoDir
+	rec = serve(withBackup, http.MethodPost, "/api/admin/backup")
+	assert.Equal(t, http.StatusConflict, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"backup.dir_missing"`)
+
+	b.err = errors.New("диск заполнен")
+	rec = serve(withBackup, http.MethodGet, "/api/admin/backup")
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Equal(t, problemContentType, rec.Header().Get("Content-Type"))
+	assert.Empty(t, rec.Header().Get("Content-Disposition"))
+
+	rec = serve(nil, http.MethodGet, "/api/admin/backup")
+	assert.Equal(t, http.StatusNotImplemented, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"backup.unsupported"`)
+
+	rec = serve(withBackup, http.MethodDelete, "/api/admin/backup")
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+
+	// Без аутентификации копии через API недоступны
+	auth = NewAuth("")
+	b.err = nil
+	for _, method := range []string{http.MethodGet, http.MethodPost} {
+		rec = serve(withBackup, method, "/api/admin/backup")
+		assert.Equal(t, http.StatusForbidden, rec.Code)
+		assert.Contains(t, rec.Body.String(), `"backup.auth_required"`)
+		assert.Empty(t, rec.Header().Get("Content-Disposition"))
+	}
+}
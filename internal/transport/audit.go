@@ -0,0 +1,138 @@
+package transport
+
+import (
+	"net/http"
+	"strconv"
+	"tasktracker/internal/domain/task"
+	"tasktracker/internal/i18n"
+	"time"
+)
+
+type auditResponse struct {
+	Entries    []task.AuditEntry `json:"entries"`
+	NextCursor string            `json:"next_cursor,omitempty"`
+}
+
+// handleAudit возвращает журнал изменений, от новых записей к старым.
+// Параметры отбора: actor, action, task_id, list_id, request_id, since, until, limit, cursor.
+func (h *Handler) handleAudit(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != http.MethodGet {
+		h.writeError(w, r, errMethodNotAllowed)
+		return
+	}
+
+	query, err := parseAuditQuery(r)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+	for _, p := range []struct {
+		name string
+		id   *int64
+	}{{"task_id", &query.TaskID}, {"list_id", &query.ListID}} {
+		if s := r.FormValue(p.name); s != "" {
+			id, err := strconv.ParseInt(s, 10, 64)
+			if err != nil || id <= 0 {
+				h.writeError(w, r, errBadRequest(i18n.RequestIDInvalid))
+				return
+			}
+			*p.id = id
+		}
+	}
+
+	page, err := h.service.GetAudit(r.Context(), query)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+	writeJSON(r.Context(), w, auditResponse{Entries: page.Entries, NextCursor: page.NextCursor}, http.StatusOK)
+}
+
+// handleTaskHistory возвращает изменения задачи ?id=, в том числе уже удаленной
+func (h *Handler) handleTaskHistory(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != http.MethodGet {
+		h.writeError(w, r, errMethodNotAllowed)
+		return
+	}
+
+	idStr := r.FormValue("id")
+	if idStr == "" {
+		h.writeError(w, r, errBadRequest(i18n.RequestIDMissing))
+		return
+	}
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		h.writeError(w, r, errBadRequest(i18n.RequestIDInvalid))
+		return
+	}
+
+	query, err := parseAuditQuery(r)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	page, err := h.service.GetTaskHistory(r.Context(), id, query)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+	writeJSON(r.Context(), w, auditResponse{Entries: page.Entries, NextCursor: page.NextCursor}, http.StatusOK)
+}
+
+// parseAuditQuery разбирает общие параметры журнала: автора, вид изменения, запрос,
+// период и страницу
+func parseAuditQuery(r *http.Request) (task.AuditQuery, error) {
+	query := task.AuditQuery{
+		Actor:     r.FormValue("actor"),
+		RequestID: r.FormValue("request_id"),
+	}
+
+	var err error
+	if query.Action, err = task.ParseAuditAction(r.FormValue("action")); err != nil {
+		return query, err
+	}
+	if query.Since, err = parseAuditTime("since", r.FormValue("since"), false); err != nil {
+		return query, err
+	}
+	if query.Until, err = parseAuditTime("until", r.FormValue("until"), true); err != nil {
+		return query, err
+	}
+
+	if limitStr := r.FormValue("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit <= 0 {
+			return query, errBadRequest(i18n.RequestLimitInvalid)
+		}
+		query.Limit = limit
+	}
+	if cursor := r.FormValue("cursor"); cursor != "" {
+		if query.Before, err = task.ParseAuditCursor(cursor); err != nil {
+			return query, err
+		}
+	}
+	return query, nil
+}
+
+// parseAuditTime разбирает время в RFC 3339 или дату YYYYMMDD в часовом поясе сервера.
+// Дата в until включает весь день: граница переносится на начало следующего.
+func parseAuditTime(field, s string, endOfDay bool) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	day, err := time.ParseInLocation(task.DateFormat, s, time.Local)
+	if err != nil {
+		return time.Time{}, task.Invalid(field, i18n.AuditTimeInvalid, i18n.Params{"value": s})
+	}
+	if endOfDay {
+		day = day.AddDate(0, 0, 1)
+	}
+	return day, nil
+}
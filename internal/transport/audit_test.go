@@ -0,0 +1,81 @@
+package transport
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"tasktracker/internal/domain/task"
+	"tasktracker/internal/storage/memory"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditEndpoints(t *testing.T) {
+	h := Chain(newAPI(memory.NewRepository(), task.WithAuditContext(AuditContext)), RequestID)
+	requestID := func(r *http.Request) *http.Request {
+		r.Header.Set(RequestIDHeader, "req-"+r.Method)
+		return r
+	}
+	audit := func(target string) auditResponse {
+		t.Helper()
+		rec := serve(h, http.MethodGet, target, "", requestID)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		var resp auditResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		return resp
+	}
+
+	rec := serve(h, http.MethodPost, "/api/task", `{"date":"20300101","title":"Отчет"}`, requestID)
+	require.Equal(t, http.StatusOK, rec.Code)
+	var created createTaskResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &created))
+	id := strconv.FormatInt(created.ID, 10)
+	rec = serve(h, http.MethodPut, "/api/task", `{"id":"`+id+`","date":"20300101","title":"Отчет за год"}`, requestID)
+	require.Equal(t, http.StatusOK, rec.Code)
+	rec = serve(h, http.MethodPost, "/api/lists", `{"name":"Работа","query":"title:отчет"}`, requestID)
+	require.Equal(t, http.StatusOK, rec.Code)
+
+	resp := audit("/api/audit")
+	require.Len(t, resp.Entries, 3)
+	assert.Empty(t, resp.NextCursor)
+	for _, e := range resp.Entries {
+		assert.Equal(t, ActorAnonymous, e.Actor)
+	}
+	update := resp.Entries[1]
+	assert.Equal(t, task.AuditUpdate, update.Action)
+	assert.Equal(t, "req-PUT", update.RequestID)
+	assert.Equal(t, "Отчет за год", *update.Changes["title"].After)
+
+	resp = audit("/api/task/history?id=" + id + "&limit=1")
+	require.Len(t, resp.Entries, 1)
+	assert.Equal(t, task.AuditUpdate, resp.Entries[0].Action)
+	resp = audit("/api/task/history?id=" + id + "&cursor=" + resp.NextCursor)
+	require.Len(t, resp.Entries, 1)
+	assert.Equal(t, task.AuditCreate, resp.Entries[0].Action)
+
+	assert.Len(t, audit("/api/audit?action=create&request_id=req-POST").Entries, 2)
+	assert.Len(t, audit("/api/audit?task_id="+id+"&since=20000101&until=20300101").Entries, 2)
+	assert.Empty(t, audit("/api/audit?until=2000-01-01T00:00:00Z").Entries)
+
+	// Ответ сохраняет формат журнала: id строками, изменения до и после
+	body := serve(h, http.MethodGet, "/api/audit?action=update", "", requestID).Body.String()
+	assert.Contains(t, body, `"task_id":"`+id+`"`)
+	assert.Contains(t, body, `"title":{"before":"Отчет","after":"Отчет за год"}`)
+
+	for target, code := range map[string]string{
+		"/api/audit?action=rename": "audit.action.unknown",
+		"/api/audit?since=вчера":   "audit.time.invalid",
+		"/api/audit?task_id=abc":   "request.id.invalid",
+		"/api/audit?cursor=-1":     "cursor.invalid",
+		"/api/task/history":        "request.id.missing",
+		"/api/task/history?id=0":   "task.id.invalid",
+		"/api/audit?limit=0":       "request.limit.invalid",
+	} {
+		rec := serve(h, http.MethodGet, target, "", requestID)
+		assert.Equal(t, http.StatusBadRequest, rec.Code, target)
+		assert.Contains(t, rec.Body.String(), code, target)
+	}
+	assert.Equal(t, http.StatusMethodNotAllowed, serve(h, http.MethodPost, "/api/audit", "", requestID).Code)
+}
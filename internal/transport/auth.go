@@ -0,0 +1,147 @@
+package transport
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"tasktracker/internal/i18n"
+	"time"
+)
+
+const (
+	// tokenCookie — cookie, в которой фронтенд хранит токен после входа
+	tokenCookie = "token"
+
+	// tokenTTL совпадает со сроком жизни cookie, который выставляет фронтенд
+	tokenTTL = 8 * time.Hour
+
+	// authUser — имя пользователя в логах: пароль один, пользователь тоже один
+	authUser = "owner"
+)
+
+// publicAPIPaths доступны без аутентификации, как и все пути вне /api/
+var publicAPIPaths = map[string]bool{
+	"/api/signin":   true,
+	"/api/nextdate": true,
+}
+
+// Auth проверяет пароль при входе и токены в запросах к API.
+// Пустой секрет отключает аутентификацию.
+type Auth struct {
+	secret string
+	now    func() time.Time
+}
+
+// NewAuth создает аутентификацию по общему паролю secret
+func NewAuth(secret string) *Auth {
+	return &Auth{secret: secret, now: time.Now}
+}
+
+// Enabled сообщает, требуется ли аутентификация
+func (a *Auth) Enabled() bool {
+	return a.secret != ""
+}
+
+// Middleware пропускает к API только запросы с действительным токеном в cookie token
+// или в заголовке Authorization: Bearer. Остальные получают 401, по которому фронтенд
+// открывает страницу входа.
+func (a *Auth) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !a.Enabled() || !strings.HasPrefix(r.URL.Path, "/api/") || publicAPIPaths[r.URL.Path] {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if err := a.verify(requestToken(r)); err != nil {
+			writeProblem(w, r, http.StatusUnauthorized, i18n.AuthRequired, nil)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(WithUser(r.Context(), authUser)))
+	})
+}
+
+type signinRequest struct {
+	Password string `json:"password"`
+}
+
+type signinResponse struct {
+	Token string `json:"token"`
+}
+
+// handleSignin проверяет пароль и возвращает токен для cookie
+func (a *Auth) handleSignin(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != http.MethodPost {
+		writeProblem(w, r, http.StatusMethodNotAllowed, i18n.RequestMethodNotAllowed, nil)
+		return
+	}
+	if !a.Enabled() {
+		writeProblem(w, r, http.StatusBadRequest, i18n.AuthDisabled, nil)
+		return
+	}
+
+	var req signinRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeProblem(w, r, http.StatusBadRequest, i18n.RequestInvalidJSON, nil)
+		return
+	}
+
+	if subtle.ConstantTimeCompare([]byte(req.Password), []byte(a.secret)) != 1 {
+		writeProblem(w, r, http.StatusUnauthorized, i18n.AuthWrongPassword, nil)
+		return
+	}
+
+	writeJSON(r.Context(), w, signinResponse{
+		Token: a.token(a.now().Add(tokenTTL)),
+	}, http.StatusOK)
+}
+
+func requestToken(r *http.Request) string {
+	if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		return token
+	}
+	if cookie, err := r.Cookie(tokenCookie); err == nil {
+		return cookie.Value
+	}
+	return ""
+}
+
+// token создает токен вида <срок действия>.<подпись HMAC-SHA256>.
+// Подпись зависит от секрета, поэтому смена пароля делает старые токены недействительными.
+func (a *Auth) token(expires time.Time) string {
+	payload := strconv.FormatInt(expires.Unix(), 10)
+	return payload + "." + a.sign(payload)
+}
+
+func (a *Auth) verify(token string) error {
+	payload, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return errors.New("некорректный токен")
+	}
+	if !hmac.Equal([]byte(signature), []byte(a.sign(payload))) {
+		return errors.New("неверная подпись токена")
+	}
+
+	expires, err := strconv.ParseInt(payload, 10, 64)
+	if err != nil {
+		return errors.New("некорректный токен")
+	}
+	if a.now().Unix() >= expires {
+		return errors.New("срок действия токена истек")
+	}
+	return nil
+}
+
+func (a *Auth) sign(payload string) string {
+	mac := hmac.New(sha256.New, []byte(a.secret))
+	mac.Write([]byte(payload))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,79 @@
+package transport
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuth(t *testing.T) {
+	now := time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+	auth := NewAuth("secret")
+	auth.now = func() time.Time { return now }
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/signin", auth.handleSignin)
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(UserFromContext(r.Context())))
+	})
+	handler := auth.Middleware(mux)
+
+	signin := func(password string) (int, signinResponse) {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/signin", strings.NewReader(`{"password":"`+password+`"}`)))
+		var resp signinResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		return rec.Code, resp
+	}
+	get := func(path, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if token != "" {
+			req.AddCookie(&http.Cookie{Name: tokenCookie, Value: token})
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	code, resp := signin("wrong")
+	assert.Equal(t, http.StatusUnauthorized, code)
+	assert.Empty(t, resp.Token)
+
+	code, resp = signin("secret")
+	require.Equal(t, http.StatusOK, code)
+	require.NotEmpty(t, resp.Token)
+
+	assert.Equal(t, http.StatusUnauthorized, get("/api/tasks", "").Code)
+	assert.Equal(t, http.StatusUnauthorized, get("/api/tasks", resp.Token+"x").Code)
+	assert.Equal(t, http.StatusOK, get("/index.html", "").Code, "статика доступна без входа")
+	assert.Equal(t, http.StatusOK, get("/api/nextdate", "").Code)
+
+	rec := get("/api/tasks", resp.Token)
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, authUser, rec.Body.String())
+
+	req := httptest.NewRequest(http.MethodGet, "/api/tasks", nil)
+	req.Header.Set("Authorization", "Bearer "+resp.Token)
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+
+	// Токен другого секрета и просроченный токен не принимаются
+	assert.Equal(t, http.StatusUnauthorized, get("/api/tasks", NewAuth("other").token(now.Add(time.Hour))).Code)
+	now = now.Add(tokenTTL)
+	assert.Equal(t, http.StatusUnauthorized, get("/api/tasks", resp.Token).Code)
+}
+
+func TestAuthDisabled(t *testing.T) {
+	handler := NewAuth("").Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/tasks", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
@@ -0,0 +1,144 @@
+package transport
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"tasktracker/internal/domain/task"
+	"tasktracker/internal/i18n"
+	"tasktracker/internal/taskcsv"
+	"time"
+)
+
+// csvImportResponse — итог загрузки CSV: сколько задач создано и ошибки по строкам
+type csvImportResponse struct {
+	Created int           `json:"created"`
+	Errors  []csvRowError `json:"errors"`
+}
+
+// csvRowError — ошибка в строке файла; row — номер строки, заголовок — строка 1
+type csvRowError struct {
+	Row     int       `json:"row"`
+	Field   string    `json:"field,omitempty"`
+	Code    i18n.Code `json:"code"`
+	Message string    `json:"message"`
+}
+
+// csvComma возвращает разделитель выгрузки из параметра delimiter: запятая (по умолчанию),
+// точка с запятой или tab
+func csvComma(r *http.Request) (rune, error) {
+	switch r.FormValue("delimiter") {
+	case "", ",":
+		return ',', nil
+	case ";":
+		return ';', nil
+	case "tab", "\t":
+		return '\t', nil
+	}
+	return 0, errBadRequest(i18n.RequestDelimiterInvalid)
+}
+
+// writeTasksCSV выгружает в CSV все задачи, отобранные query.Filter. Задачи читаются
+// страницами, поэтому выгрузка большого списка не держит его целиком в памяти.
+func (h *Handler) writeTasksCSV(w http.ResponseWriter, r *http.Request, query task.ListQuery) {
+	comma, err := csvComma(r)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	query.Limit = task.MaxListLimit
+	query.After = nil
+	page, err := h.service.GetNearestTasks(r.Context(), query)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	name := "tasks-" + time.Now().UTC().Format("20060102T150405Z") + ".csv"
+	header := w.Header()
+	header.Set("Content-Type", "text/csv; charset=utf-8")
+	header.Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name))
+	header.Set("Cache-Control", "no-store")
+
+	// После первой строки статус ответа уже отправлен: ошибка только прерывает выгрузку
+	cw := taskcsv.NewWriter(w, comma)
+	for {
+		for _, t := range page.Tasks {
+			if err := cw.Write(t); err != nil {
+				h.logger.WarnContext(r.Context(), "csv export aborted", "error", err)
+				return
+			}
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		query.After, err = task.DecodeCursor(page.NextCursor)
+		if err == nil {
+			page, err = h.service.GetNearestTasks(r.Context(), query)
+		}
+		if err != nil {
+			h.logger.ErrorContext(r.Context(), "csv export failed", "error", err)
+			return
+		}
+	}
+	if err := cw.Flush(); err != nil {
+		h.logger.WarnContext(r.Context(), "csv export aborted", "error", err)
+	}
+}
+
+// handleImportCSV создает задачи из CSV в теле запроса. Параметры date, title, comment
+// и repeat задают столбцы полей (см. taskcsv.Mapping). Каждая строка создается отдельно,
+// как через POST /api/task: строки с ошибками пропускаются и перечисляются в ответе.
+func (h *Handler) handleImportCSV(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != http.MethodPost {
+		h.writeError(w, r, errMethodNotAllowed)
+		return
+	}
+
+	reader, err := taskcsv.NewReader(r.Body, taskcsv.Mapping{
+		Date:    r.URL.Query().Get("date"),
+		Title:   r.URL.Query().Get("title"),
+		Comment: r.URL.Query().Get("comment"),
+		Repeat:  r.URL.Query().Get("repeat"),
+	})
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	lang := requestLang(r)
+	resp := csvImportResponse{Errors: []csvRowError{}}
+	for {
+		row, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			// Тело не дочитано (например, превышен лимит размера): созданные задачи остаются
+			h.writeError(w, r, err)
+			return
+		}
+
+		if row.Err == nil {
+			row.Err = h.service.CreateTask(r.Context(), &row.Task)
+		}
+		switch {
+		case row.Err == nil:
+			resp.Created++
+		case errors.Is(row.Err, task.ErrValidation):
+			rowErr := csvRowError{Row: row.Line}
+			rowErr.Field, rowErr.Code, rowErr.Message = describeError(row.Err, lang)
+			resp.Errors = append(resp.Errors, rowErr)
+		default:
+			h.writeError(w, r, row.Err)
+			return
+		}
+	}
+
+	h.logger.InfoContext(r.Context(), "csv imported", "created", resp.Created, "failed", len(resp.Errors))
+	writeJSON(r.Context(), w, resp, http.StatusOK)
+}
@@ -0,0 +1,83 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"tasktracker/internal/domain/task"
+	"tasktracker/internal/storage/memory"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTasksCSV(t *testing.T) {
+	repo := memory.NewRepository()
+	future := task.FormatDate(time.Now().AddDate(0, 0, 30))
+	for i := 0; i < task.MaxListLimit+2; i++ {
+		require.NoError(t, repo.Create(context.Background(), &task.Task{Date: future, Title: "Задача"}))
+	}
+	require.NoError(t, repo.Create(context.Background(), &task.Task{Date: future, Title: "Отчет; квартальный", Repeat: "d 7"}))
+	h := newAPI(repo)
+	get := func(target string) *httptest.ResponseRecorder {
+		return serve(h, http.MethodGet, target, "")
+	}
+
+	// Выгружаются все задачи, а не одна страница
+	rec := get("/api/tasks?format=csv")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "text/csv; charset=utf-8", rec.Header().Get("Content-Type"))
+	assert.Contains(t, rec.Header().Get("Content-Disposition"), `.csv"`)
+	lines := strings.Split(strings.TrimSpace(rec.Body.String()), "\n")
+	assert.Equal(t, "id,date,title,comment,repeat", lines[0])
+	assert.Len(t, lines, task.MaxListLimit+4)
+
+	rec = get("/api/tasks?format=csv&delimiter=%3B&search=title:отчет")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "id;date;title;comment;repeat\n"+"503;"+future+`;"Отчет; квартальный";;d 7`+"\n", rec.Body.String())
+
+	assert.Equal(t, http.StatusBadRequest, get("/api/tasks?format=xml").Code)
+	assert.Equal(t, http.StatusBadRequest, get("/api/tasks?format=csv&delimiter=|").Code)
+	assert.Equal(t, http.StatusBadRequest, get("/api/tasks?format=csv&search=is:x").Code)
+}
+
+func TestImportCSVEndpoint(t *testing.T) {
+	repo := memory.NewRepository()
+	h := newAPI(repo)
+	post := func(target, body string) (int, csvImportResponse) {
+		rec := serve(h, http.MethodPost, target, body)
+		var resp csvImportResponse
+		json.Unmarshal(rec.Body.Bytes(), &resp)
+		return rec.Code, resp
+	}
+
+	future := time.Now().AddDate(0, 0, 30)
+	body := "Задача;Срок;Повтор\n" +
+		"Позвонить;" + future.Format("02.01.2006") + ";\n" +
+		";" + future.Format("20060102") + ";\n" +
+		"Полив;" + future.Format("20060102") + ";w 9\n" +
+		"Отчет;31.13.2024;\n" +
+		"Еженедельно;" + future.Format("20060102") + ";w 1\n"
+	status, resp := post("/api/import/csv?title=Задача&date=Срок&repeat=Повтор", body)
+	require.Equal(t, http.StatusOK, status)
+	assert.Equal(t, 2, resp.Created, "строки с ошибками не мешают остальным")
+	require.Len(t, resp.Errors, 3)
+	assert.Equal(t, csvRowError{Row: 3, Field: "title", Code: "task.title.empty", Message: "task title must not be empty"}, resp.Errors[0])
+	assert.Equal(t, 4, resp.Errors[1].Row)
+	assert.Equal(t, "repeat", resp.Errors[1].Field)
+	assert.Equal(t, csvRowError{Row: 5, Field: "date", Code: "csv.date.invalid", Message: `invalid date "31.13.2024", expected YYYYMMDD or DD.MM.YYYY`}, resp.Errors[2])
+
+	tasks, err := repo.GetTasks(context.Background(), &task.ListQuery{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, tasks, 2)
+	assert.Equal(t, task.FormatDate(future), tasks[0].Date)
+
+	status, _ = post("/api/import/csv?date=due", "title,date\na,20240101\n")
+	assert.Equal(t, http.StatusBadRequest, status)
+	status, _ = post("/api/import/csv", "")
+	assert.Equal(t, http.StatusBadRequest, status)
+}
@@ -0,0 +1,134 @@
+package transport
+
+import (
+	"errors"
+	"net/http"
+	"tasktracker/internal/domain/task"
+	"tasktracker/internal/i18n"
+)
+
+// problemContentType — тип ответа с ошибкой по RFC 7807
+const problemContentType = "application/problem+json"
+
+// problem — тело ответа с ошибкой (RFC 7807).
+//
+// code — стабильный код ошибки, по нему клиенты обрабатывают ошибки; detail — текст
+// на языке из Accept-Language. Поле error дублирует detail: фронтенд и старые клиенты
+// читают именно его. Член status не передается (по RFC он необязателен и совпадает
+// с кодом ответа): клиенты разбирают ошибку как объект со строковыми значениями.
+type problem struct {
+	Type      string            `json:"type"`
+	Title     string            `json:"title"`
+	Detail    string            `json:"detail,omitempty"`
+	Instance  string            `json:"instance,omitempty"`
+	Error     string            `json:"error"`
+	Code      i18n.Code         `json:"code"`
+	Params    map[string]any    `json:"params,omitempty"`
+	RequestID string            `json:"request_id,omitempty"`
+	Fields    []task.FieldError `json:"fields,omitempty"`
+	Token     string            `json:"token,omitempty"`    // Проблемный токен в строке фильтра
+	Position  *int              `json:"position,omitempty"` // Позиция токена в строке фильтра
+}
+
+// errBadRequest — ошибка разбора самого запроса (параметров или тела), а не данных задачи
+func errBadRequest(code i18n.Code) error {
+	return &task.Error{Kind: task.ErrValidation, Code: code}
+}
+
+var errMethodNotAllowed = errors.New("метод не поддерживается")
+
+// requestLang выбирает язык сообщений по заголовку Accept-Language
+func requestLang(r *http.Request) i18n.Lang {
+	return i18n.Negotiate(r.Header.Get("Accept-Language"))
+}
+
+// writeProblem отвечает ошибкой status с сообщением code на языке клиента
+func writeProblem(w http.ResponseWriter, r *http.Request, status int, code i18n.Code, params i18n.Params) {
+	lang := requestLang(r)
+	p := newProblem(r, status, i18n.Message(lang, code, params))
+	p.Code = code
+	p.Params = params.Resolve(lang)
+	writeProblemBody(w, r, lang, status, p)
+}
+
+func newProblem(r *http.Request, status int, detail string) problem {
+	return problem{
+		Type:      "about:blank",
+		Title:     http.StatusText(status),
+		Detail:    detail,
+		Instance:  r.URL.Path,
+		Error:     detail,
+		RequestID: RequestIDFromContext(r.Context()),
+	}
+}
+
+func writeProblemBody(w http.ResponseWriter, r *http.Request, lang i18n.Lang, status int, p problem) {
+	header := w.Header()
+	header.Set("Content-Type", problemContentType)
+	header.Set("Content-Language", string(lang))
+	header.Add("Vary", "Accept-Language")
+	writeJSON(r.Context(), w, p, status)
+}
+
+// writeError отвечает на ошибку err, выбирая код по ее виду:
+// task.ErrNotFound — 404, task.ErrValidation — 400, task.ErrConflict — 409,
+// превышение размера тела — 413. Остальные ошибки считаются внутренними:
+// они пишутся в лог, а клиент получает 500 без подробностей.
+//
+// Код ошибки в ответе — самый конкретный из цепочки (repeat.week.day_out_of_range,
+// а не task.repeat.invalid), текст — полный, на языке из Accept-Language.
+func (h *Handler) writeError(w http.ResponseWriter, r *http.Request, err error) {
+	var (
+		status    int
+		maxErr    *http.MaxBytesError
+		domainErr *task.Error
+		filterErr *task.FilterError
+	)
+	switch {
+	case errors.Is(err, errMethodNotAllowed):
+		writeProblem(w, r, http.StatusMethodNotAllowed, i18n.RequestMethodNotAllowed, nil)
+		return
+	case errors.As(err, &maxErr):
+		writeProblem(w, r, http.StatusRequestEntityTooLarge, i18n.RequestTooLarge, nil)
+		return
+	case errors.Is(err, task.ErrNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, task.ErrValidation):
+		status = http.StatusBadRequest
+	case errors.Is(err, task.ErrConflict):
+		status = http.StatusConflict
+	}
+	if status == 0 || !errors.As(err, &domainErr) && !errors.As(err, &filterErr) {
+		h.logger.ErrorContext(r.Context(), "request failed",
+			"error", err,
+			"method", r.Method,
+			"path", r.URL.Path,
+		)
+		writeProblem(w, r, http.StatusInternalServerError, i18n.Internal, nil)
+		return
+	}
+
+	lang := requestLang(r)
+	var p problem
+	switch {
+	case filterErr != nil:
+		p = newProblem(r, status, filterErr.Localize(lang))
+		p.Code = filterErr.Code
+		p.Params = filterErr.Params.Resolve(lang)
+		p.Token = filterErr.Token
+		p.Position = &filterErr.Pos
+	default:
+		inner := domainErr.Innermost()
+		p = newProblem(r, status, domainErr.Localize(lang))
+		p.Code = inner.Code
+		p.Params = inner.Params.Resolve(lang)
+		if domainErr.Field != "" {
+			p.Fields = []task.FieldError{{
+				Field:   domainErr.Field,
+				Code:    inner.Code,
+				Message: p.Detail,
+			}}
+		}
+	}
+	writeProblemBody(w, r, lang, status, p)
+}
@@ -0,0 +1,163 @@
+package transport
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"tasktracker/internal/domain/task"
+	"tasktracker/internal/i18n"
+	"tasktracker/internal/storage/memory"
+)
+
+func TestWriteError(t *testing.T) {
+	var logs bytes.Buffer
+	h := &Handler{logger: slog.New(slog.NewJSONHandler(&logs, nil))}
+
+	write := func(err error, acceptLanguage string) (*httptest.ResponseRecorder, map[string]any) {
+		rec := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodDelete, "/api/task?id=7", nil)
+		req.Header.Set("Accept-Language", acceptLanguage)
+		h.writeError(rec, req, err)
+		assert.Equal(t, "application/problem+json", rec.Header().Get("Content-Type"))
+
+		var body map[string]any
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		assert.Equal(t, body["detail"], body["error"], "Поле error дублирует detail")
+		assert.Equal(t, "/api/task", body["instance"])
+		return rec, body
+	}
+
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		code   i18n.Code
+		detail string
+	}{
+		{"not found", task.NotFound(i18n.TaskNotFound), http.StatusNotFound, i18n.TaskNotFound, "задача не найдена"},
+		{"wrapped not found", fmt.Errorf("удаление: %w", task.NotFound(i18n.TaskNotFound)), http.StatusNotFound, i18n.TaskNotFound, "задача не найдена"},
+		{"validation", task.Invalid("title", i18n.TaskTitleEmpty, nil), http.StatusBadRequest, i18n.TaskTitleEmpty, "заголовок задачи не может быть пустым"},
+		{"bad request", errBadRequest(i18n.RequestInvalidJSON), http.StatusBadRequest, i18n.RequestInvalidJSON, "неверный формат запроса"},
+		{"conflict", task.Conflict(i18n.TaskNotFound, nil), http.StatusConflict, i18n.TaskNotFound, "задача не найдена"},
+		{"method", errMethodNotAllowed, http.StatusMethodNotAllowed, i18n.RequestMethodNotAllowed, "метод не поддерживается"},
+		{"body too large", &http.MaxBytesError{Limit: 10}, http.StatusRequestEntityTooLarge, i18n.RequestTooLarge, "слишком большой запрос"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec, body := write(tt.err, "")
+			assert.Equal(t, tt.status, rec.Code)
+			assert.Equal(t, string(tt.code), body["code"])
+			assert.Equal(t, tt.detail, body["detail"])
+			assert.Equal(t, http.StatusText(tt.status), body["title"])
+			assert.Equal(t, "ru", rec.Header().Get("Content-Language"))
+		})
+	}
+
+	t.Run("fields and innermost code", func(t *testing.T) {
+		_, cause := task.ParseRepeatRule("w 9")
+		require.Error(t, cause)
+		err := task.Invalid("repeat", i18n.TaskRepeatInvalid, i18n.Params{"cause": cause})
+
+		rec, body := write(err, "en-US,en;q=0.9")
+		assert.Equal(t, "en", rec.Header().Get("Content-Language"))
+		assert.Equal(t, "invalid repeat rule: day of the week must be between 1 and 7: 9", body["detail"])
+		assert.Equal(t, "repeat.week.day_out_of_range", body["code"])
+		assert.Equal(t, map[string]any{"day": float64(9)}, body["params"])
+		assert.Equal(t, []any{map[string]any{
+			"field":   "repeat",
+			"code":    "repeat.week.day_out_of_range",
+			"message": "invalid repeat rule: day of the week must be between 1 and 7: 9",
+		}}, body["fields"])
+	})
+
+	t.Run("filter token", func(t *testing.T) {
+		_, err := task.ParseFilter("is:work", time.Now())
+		require.Error(t, err)
+		rec, body := write(err, "en")
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Equal(t, "filter.state_unknown", body["code"])
+		assert.Equal(t, `query error at position 1 ("is:work"): unknown state "work", expected overdue, today or recurring`, body["detail"])
+		assert.Equal(t, "is:work", body["token"])
+		assert.Equal(t, float64(1), body["position"])
+	})
+
+	t.Run("internal error is hidden", func(t *testing.T) {
+		rec, body := write(errors.New("database is locked"), "en")
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+		assert.Equal(t, "internal server error", body["error"])
+		assert.Equal(t, "internal", body["code"])
+		assert.Contains(t, logs.String(), "database is locked")
+	})
+}
+
+// brokenRepository отказывает в каждом чтении и записи, как недоступная БД
+type brokenRepository struct {
+	task.Repository
+}
+
+var errStorage = errors.New("database is locked")
+
+func (brokenRepository) WithTx(_ context.Context, fn func(tx task.Repository) error) error {
+	return fn(brokenRepository{})
+}
+
+func (brokenRepository) GetTasks(context.Context, *task.ListQuery) ([]task.Task, error) {
+	return nil, errStorage
+}
+
+func (brokenRepository) Search(context.Context, *task.SearchQuery) ([]task.SearchResult, error) {
+	return nil, errStorage
+}
+
+func (brokenRepository) GetSavedLists(context.Context, string) ([]task.SavedList, error) {
+	return nil, errStorage
+}
+
+func (brokenRepository) Create(context.Context, *task.Task) error {
+	return errStorage
+}
+
+// Сервис оборачивает ошибки хранилища русским текстом для журнала (см. TestWriteError);
+// клиент видит только код
+func TestStorageErrorsAreNotShown(t *testing.T) {
+	h := newAPI(brokenRepository{memory.NewRepository()})
+	cyrillic := regexp.MustCompile(`\p{Cyrillic}`)
+
+	for _, tt := range []struct{ method, target, body string }{
+		{http.MethodGet, "/api/tasks", ""},
+		{http.MethodGet, "/api/search?q=report", ""},
+		{http.MethodGet, "/api/lists", ""},
+		{http.MethodGet, "/api/export", ""},
+		{http.MethodPost, "/api/import", `{"format":"tasktracker","version":1,"tasks":[{"date":"20240115","title":"Report"}]}`},
+	} {
+		t.Run(tt.method+" "+tt.target, func(t *testing.T) {
+			rec := serve(h, tt.method, tt.target, tt.body)
+
+			assert.Equal(t, http.StatusInternalServerError, rec.Code)
+			var body map[string]any
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body), rec.Body.String())
+			assert.Equal(t, "internal", body["code"])
+			assert.Equal(t, "internal server error", body["detail"])
+			assert.False(t, cyrillic.MatchString(rec.Body.String()), rec.Body.String())
+		})
+	}
+}
+
+func TestDescribeErrorHidesInternalErrors(t *testing.T) {
+	field, code, message := describeError(fmt.Errorf("ошибка создания задачи: %w", errStorage), i18n.EN)
+	assert.Empty(t, field)
+	assert.Equal(t, i18n.Internal, code)
+	assert.Equal(t, "internal server error", message)
+}
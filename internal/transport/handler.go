@@ -1,47 +1,94 @@
 package transport
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"log/slog"
 	"net/http"
 	"strconv"
 	"tasktracker/internal/domain/task"
+	"tasktracker/internal/i18n"
 	"time"
+
+	"go.opentelemetry.io/otel"
 )
 
+var tracer = otel.Tracer("tasktracker/internal/transport")
+
 // Структуры для работы с API
 type createTaskRequest struct {
-	Date    string `json:"date"`
-	Title   string `json:"title"`
-	Comment string `json:"comment"`
-	Repeat  string `json:"repeat"`
+	Date          string `json:"date"`
+	Title         string `json:"title"`
+	Comment       string `json:"comment"`
+	Repeat        string `json:"repeat"`
+	OverduePolicy string `json:"overdue_policy"`
 }
 
 type createTaskResponse struct {
-	ID    int64  `json:"id,omitempty"`
-	Error string `json:"error,omitempty"`
+	ID int64 `json:"id"`
+}
+
+type taskListResponse struct {
+	Tasks      []task.Task `json:"tasks"`
+	NextCursor string      `json:"next_cursor,omitempty"`
 }
 
 // Handler обрабатывает HTTP-запросы
 type Handler struct {
 	service *task.Service
+	static  http.Handler
+	auth    *Auth
+	backup  Backup
+	logger  *slog.Logger
+}
+
+// HandlerOption настраивает необязательные возможности обработчика
+type HandlerOption func(*Handler)
+
+// WithBackup включает эндпоинт резервного копирования /api/admin/backup
+func WithBackup(b Backup) HandlerOption {
+	return func(h *Handler) {
+		h.backup = b
+	}
 }
 
-// NewHandler создает новый экземпляр обработчика
-func NewHandler(service *task.Service) *Handler {
-	return &Handler{
+// NewHandler создает новый экземпляр обработчика, static раздает файлы фронтенда (см. NewStatic)
+func NewHandler(service *task.Service, static http.Handler, auth *Auth, logger *slog.Logger, opts ...HandlerOption) *Handler {
+	h := &Handler{
 		service: service,
+		static:  static,
+		auth:    auth,
+		logger:  logger,
 	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
 }
 
-// RegisterRoutes регистрирует все обработчики маршрутов
-func (h *Handler) RegisterRoutes() {
-	fs := http.FileServer(http.Dir("web"))
-	http.DefaultServeMux = http.NewServeMux()
-	http.Handle("/", fs)
-	http.HandleFunc("/api/nextdate", h.handleNextDate)
-	http.HandleFunc("/api/task", h.handleTask)
-	http.HandleFunc("/api/tasks", h.handleTaskList)
-	http.HandleFunc("/api/task/done", h.handleTaskDone)
+// RegisterRoutes возвращает обработчик всех маршрутов приложения на собственном ServeMux.
+// Middleware (аутентификация, CORS и т. д.) подключает вызывающий код, см. Chain.
+func (h *Handler) RegisterRoutes() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/", h.static)
+	mux.HandleFunc("/api/signin", h.auth.handleSignin)
+	mux.HandleFunc("/api/nextdate", h.handleNextDate)
+	mux.HandleFunc("/api/task", h.handleTask)
+	mux.HandleFunc("/api/tasks", h.handleTaskList)
+	mux.HandleFunc("/api/tasks/overdue", h.handleOverdueTasks)
+	mux.HandleFunc("/api/search", h.handleSearch)
+	mux.HandleFunc("/api/lists", h.handleLists)
+	mux.HandleFunc("/api/lists/{id}/tasks", h.handleListTasks)
+	mux.HandleFunc("/api/task/done", h.handleTaskDone)
+	mux.HandleFunc("/api/task/history", h.handleTaskHistory)
+	mux.HandleFunc("/api/audit", h.handleAudit)
+	mux.HandleFunc("/api/export", h.handleExport)
+	mux.HandleFunc("/api/import", h.handleImport)
+	mux.HandleFunc("/api/import/csv", h.handleImportCSV)
+	mux.HandleFunc("/api/import/{source}", h.handleImportSource)
+	mux.HandleFunc("/api/admin/backup", h.handleBackup)
+	return recordRoute(mux)
 }
 
 // handleNextDate обрабатывает запросы на вычисление следующей даты
@@ -87,80 +134,68 @@ func (h *Handler) handleTask(w http.ResponseWriter, r *http.Request) {
 		// Получение задачи по ID
 		idStr := r.FormValue("id")
 		if idStr == "" {
-			writeJSON(w, map[string]string{
-				"error": "Не указан идентификатор",
-			}, http.StatusBadRequest)
+			h.writeError(w, r, errBadRequest(i18n.RequestIDMissing))
 			return
 		}
 
 		id, err := strconv.ParseInt(idStr, 10, 64)
 		if err != nil {
-			writeJSON(w, map[string]string{
-				"error": "Некорректный идентификатор",
-			}, http.StatusBadRequest)
+			h.writeError(w, r, errBadRequest(i18n.RequestIDInvalid))
 			return
 		}
 
-		task, err := h.service.GetTask(id)
+		task, err := h.service.GetTask(r.Context(), id)
 		if err != nil {
-			writeJSON(w, map[string]string{
-				"error": err.Error(),
-			}, http.StatusNotFound)
+			h.writeError(w, r, err)
 			return
 		}
 
-		writeJSON(w, task, http.StatusOK)
+		writeJSON(r.Context(), w, task, http.StatusOK)
 
 	case http.MethodPost:
 		// Создание новой задачи
 		var req createTaskRequest
-		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			writeJSON(w, createTaskResponse{
-				Error: "неверный формат запроса",
-			}, http.StatusBadRequest)
+		if err := decodeJSON(r, &req); err != nil {
+			h.writeError(w, r, err)
 			return
 		}
 
 		t := &task.Task{
-			Date:    req.Date,
-			Title:   req.Title,
-			Comment: req.Comment,
-			Repeat:  req.Repeat,
+			Date:          req.Date,
+			Title:         req.Title,
+			Comment:       req.Comment,
+			Repeat:        req.Repeat,
+			OverduePolicy: req.OverduePolicy,
 		}
 
-		if err := h.service.CreateTask(t); err != nil {
-			writeJSON(w, createTaskResponse{
-				Error: err.Error(),
-			}, http.StatusBadRequest)
+		if err := h.service.CreateTask(r.Context(), t); err != nil {
+			h.writeError(w, r, err)
 			return
 		}
 
-		writeJSON(w, createTaskResponse{
+		writeJSON(r.Context(), w, createTaskResponse{
 			ID: t.ID,
 		}, http.StatusOK)
 
 	case http.MethodPut:
 		// Обновление существующей задачи
 		var req struct {
-			ID      string `json:"id"`
-			Date    string `json:"date"`
-			Title   string `json:"title"`
-			Comment string `json:"comment"`
-			Repeat  string `json:"repeat"`
+			ID            string  `json:"id"`
+			Date          string  `json:"date"`
+			Title         string  `json:"title"`
+			Comment       string  `json:"comment"`
+			Repeat        string  `json:"repeat"`
+			OverduePolicy *string `json:"overdue_policy"` // nil — оставить сохраненную
 		}
 
-		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			writeJSON(w, map[string]string{
-				"error": "неверный формат запроса",
-			}, http.StatusBadRequest)
+		if err := decodeJSON(r, &req); err != nil {
+			h.writeError(w, r, err)
 			return
 		}
 
 		id, err := strconv.ParseInt(req.ID, 10, 64)
 		if err != nil {
-			writeJSON(w, map[string]string{
-				"error": "некорректный идентификатор",
-			}, http.StatusBadRequest)
+			h.writeError(w, r, errBadRequest(i18n.RequestIDInvalid))
 			return
 		}
 
@@ -171,108 +206,158 @@ func (h *Handler) handleTask(w http.ResponseWriter, r *http.Request) {
 			Comment: req.Comment,
 			Repeat:  req.Repeat,
 		}
+		opts := task.UpdateOptions{KeepOverduePolicy: req.OverduePolicy == nil}
+		if req.OverduePolicy != nil {
+			t.OverduePolicy = *req.OverduePolicy
+		}
 
-		if err := h.service.UpdateTask(t); err != nil {
-			writeJSON(w, map[string]string{
-				"error": err.Error(),
-			}, http.StatusBadRequest)
+		if err := h.service.UpdateTask(r.Context(), t, opts); err != nil {
+			h.writeError(w, r, err)
 			return
 		}
 
-		writeJSON(w, map[string]string{}, http.StatusOK)
+		writeJSON(r.Context(), w, map[string]string{}, http.StatusOK)
 
 	case http.MethodDelete:
 		// Удаление задачи по ID
 		idStr := r.FormValue("id")
 		if idStr == "" {
-			writeJSON(w, map[string]string{
-				"error": "не указан идентификатор",
-			}, http.StatusBadRequest)
+			h.writeError(w, r, errBadRequest(i18n.RequestIDMissing))
 			return
 		}
 
 		id, err := strconv.ParseInt(idStr, 10, 64)
 		if err != nil {
-			writeJSON(w, map[string]string{
-				"error": "некорректный идентификатор",
-			}, http.StatusBadRequest)
+			h.writeError(w, r, errBadRequest(i18n.RequestIDInvalid))
 			return
 		}
 
-		if err := h.service.DeleteTask(id); err != nil {
-			writeJSON(w, map[string]string{
-				"error": err.Error(),
-			}, http.StatusBadRequest)
+		if err := h.service.DeleteTask(r.Context(), id); err != nil {
+			h.writeError(w, r, err)
 			return
 		}
 
-		writeJSON(w, map[string]string{}, http.StatusOK)
+		writeJSON(r.Context(), w, map[string]string{}, http.StatusOK)
 
 	default:
-		writeJSON(w, createTaskResponse{
-			Error: "метод не поддерживается",
-		}, http.StatusMethodNotAllowed)
+		h.writeError(w, r, errMethodNotAllowed)
 	}
 }
 
-// handleTaskList обрабатывает запросы на получение списка задач
+// handleTaskList обрабатывает запросы на получение списка задач; format=csv выгружает
+// все отобранные задачи в CSV (см. writeTasksCSV)
 func (h *Handler) handleTaskList(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
 	if r.Method != http.MethodGet {
-		writeJSON(w, createTaskResponse{
-			Error: "метод не поддерживается",
-		}, http.StatusMethodNotAllowed)
+		h.writeError(w, r, errMethodNotAllowed)
 		return
 	}
 
-	search := r.FormValue("search")
-
-	// Проверяем, является ли поисковый запрос датой
-	dateFilter := ""
-	commentFilter := ""
-	if search != "" {
-		if searchDate, err := time.Parse("02.01.2006", search); err == nil {
-			// Если это дата, используем её для фильтрации по дате
-			dateFilter = searchDate.Format("20060102")
-		} else {
-			// Если не дата, ищем по комментарию
-			commentFilter = search
-		}
+	// Строка поиска разбирается языком запросов (см. task.ParseFilter)
+	filter, err := task.ParseFilter(r.FormValue("search"), time.Now())
+	if err != nil {
+		h.writeError(w, r, err)
+		return
 	}
 
-	tasks, err := h.service.GetNearestTasks(dateFilter, commentFilter)
+	query, err := parsePageParams(r)
 	if err != nil {
-		writeJSON(w, createTaskResponse{
-			Error: err.Error(),
-		}, http.StatusInternalServerError)
+		h.writeError(w, r, err)
 		return
 	}
+	query.Filter = filter
 
-	if tasks == nil {
-		tasks = []task.Task{}
+	switch r.FormValue("format") {
+	case "", "json":
+	case "csv":
+		h.writeTasksCSV(w, r, query)
+		return
+	default:
+		h.writeError(w, r, errBadRequest(i18n.RequestFormatInvalid))
+		return
 	}
 
-	response := map[string]interface{}{
-		"tasks": tasks,
+	page, err := h.service.GetNearestTasks(r.Context(), query)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
 	}
-	writeJSON(w, response, http.StatusOK)
+
+	writeJSON(r.Context(), w, taskListResponse{
+		Tasks:      page.Tasks,
+		NextCursor: page.NextCursor,
+	}, http.StatusOK)
 }
+
+// handleOverdueTasks возвращает задачи с прошедшей датой (например, с политикой keep)
+func (h *Handler) handleOverdueTasks(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != http.MethodGet {
+		h.writeError(w, r, errMethodNotAllowed)
+		return
+	}
+
+	query, err := parsePageParams(r)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	page, err := h.service.GetOverdueTasks(r.Context(), query, time.Now())
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	writeJSON(r.Context(), w, taskListResponse{
+		Tasks:      page.Tasks,
+		NextCursor: page.NextCursor,
+	}, http.StatusOK)
+}
+
+// handleSearch обрабатывает полнотекстовый поиск задач с ранжированием и подсветкой совпадений
+func (h *Handler) handleSearch(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != http.MethodGet {
+		h.writeError(w, r, errMethodNotAllowed)
+		return
+	}
+
+	limit := 0
+	if limitStr := r.FormValue("limit"); limitStr != "" {
+		var err error
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil || limit <= 0 {
+			h.writeError(w, r, errBadRequest(i18n.RequestLimitInvalid))
+			return
+		}
+	}
+
+	results, err := h.service.Search(r.Context(), r.FormValue("q"), limit)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	writeJSON(r.Context(), w, map[string]interface{}{
+		"results": results,
+	}, http.StatusOK)
+}
+
 func (h *Handler) handleTaskDone(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
 	if r.Method != http.MethodPost {
-		writeJSON(w, map[string]string{
-			"error": "метод не поддерживается",
-		}, http.StatusMethodNotAllowed)
+		h.writeError(w, r, errMethodNotAllowed)
 		return
 	}
 
 	idStr := r.FormValue("id")
 	if idStr == "" {
-		writeJSON(w, map[string]string{
-			"error": "не указан идентификатор",
-		}, http.StatusBadRequest)
+		h.writeError(w, r, errBadRequest(i18n.RequestIDMissing))
 		return
 	}
 
@@ -292,24 +377,62 @@ func (h *Handler) handleTaskDone(w http.ResponseWriter, r *http.Request) {
 
 	id, err := strconv.ParseInt(idStr, 10, 64)
 	if err != nil {
-		writeJSON(w, map[string]string{
-			"error": "некорректный идентификатор",
-		}, http.StatusBadRequest)
+		h.writeError(w, r, errBadRequest(i18n.RequestIDInvalid))
 		return
 	}
 
-	if err := h.service.MarkTaskDone(id, baseDate); err != nil {
-		writeJSON(w, map[string]string{
-			"error": err.Error(),
-		}, http.StatusBadRequest)
+	if err := h.service.MarkTaskDone(r.Context(), id, baseDate); err != nil {
+		h.writeError(w, r, err)
 		return
 	}
 
-	writeJSON(w, map[string]string{}, http.StatusOK)
+	writeJSON(r.Context(), w, map[string]string{}, http.StatusOK)
+}
+
+// parsePageParams разбирает параметры постраничного вывода limit и cursor
+func parsePageParams(r *http.Request) (task.ListQuery, error) {
+	var query task.ListQuery
+
+	if limitStr := r.FormValue("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit <= 0 {
+			return query, errBadRequest(i18n.RequestLimitInvalid)
+		}
+		query.Limit = limit
+	}
+
+	if cursorStr := r.FormValue("cursor"); cursorStr != "" {
+		cursor, err := task.DecodeCursor(cursorStr)
+		if err != nil {
+			return query, err
+		}
+		query.After = cursor
+	}
+
+	return query, nil
 }
 
-// writeJSON вспомогательная функция для записи JSON-ответов
-func writeJSON(w http.ResponseWriter, response interface{}, status int) {
+// decodeJSON читает JSON из тела запроса. Превышение лимита BodyLimit возвращается
+// как есть (ответ 413), остальные ошибки разбора — как некорректный запрос.
+func decodeJSON(r *http.Request, v any) error {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		var maxErr *http.MaxBytesError
+		if errors.As(err, &maxErr) {
+			return err
+		}
+		return errBadRequest(i18n.RequestInvalidJSON)
+	}
+	return nil
+}
+
+// writeJSON вспомогательная функция для записи JSON-ответов.
+// Кодирование выделено в отдельный span, чтобы в трассе было видно время сериализации.
+func writeJSON(ctx context.Context, w http.ResponseWriter, response interface{}, status int) {
+	_, span := tracer.Start(ctx, "json.encode")
+	defer span.End()
+
 	w.WriteHeader(status)
-	json.NewEncoder(w).Encode(response)
+	if err := json.NewEncoder(w).Encode(response); err != nil {
+		span.RecordError(err)
+	}
 }
@@ -0,0 +1,33 @@
+package transport
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"tasktracker/internal/domain/task"
+	"tasktracker/internal/logging"
+)
+
+// newAPI возвращает маршруты API над сервисом с хранилищем repo, без аутентификации
+func newAPI(repo task.Repository, opts ...task.Option) http.Handler {
+	return NewHandler(task.NewService(repo, opts...), http.NotFoundHandler(), NewAuth(""), logging.Discard()).RegisterRoutes()
+}
+
+// serve выполняет запрос к h с Accept-Language: en; edit дополняет запрос перед отправкой
+func serve(h http.Handler, method, target, body string, edit ...func(r *http.Request) *http.Request) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("Accept-Language", "en")
+	for _, f := range edit {
+		req = f(req)
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	return rec
+}
+
+// asUser выполняет запрос от имени вошедшего пользователя user
+func asUser(user string) func(r *http.Request) *http.Request {
+	return func(r *http.Request) *http.Request {
+		return r.WithContext(WithUser(r.Context(), user))
+	}
+}
@@ -0,0 +1,133 @@
+package transport
+
+import (
+	"context"
+	"net/http"
+	"runtime/debug"
+	"tasktracker/internal/i18n"
+	"time"
+)
+
+// HealthPaths — служебные эндпоинты для оркестратора. Они не требуют аутентификации
+// и не пишутся в access log, чтобы частые проверки не засоряли журнал.
+var HealthPaths = []string{"/healthz", "/readyz", "/version"}
+
+// readyTimeout ограничивает время одной проверки готовности
+const readyTimeout = 2 * time.Second
+
+// HealthCheck — проверка готовности одной зависимости приложения
+type HealthCheck struct {
+	Name  string
+	Check func(ctx context.Context) error
+}
+
+// Health обслуживает /healthz, /readyz и /version
+type Health struct {
+	started       time.Time
+	schemaVersion func() (int, error)
+	checks        []HealthCheck
+}
+
+// NewHealth создает обработчик служебных эндпоинтов. Время работы отсчитывается от вызова NewHealth.
+func NewHealth(schemaVersion func() (int, error), checks ...HealthCheck) *Health {
+	return &Health{
+		started:       time.Now(),
+		schemaVersion: schemaVersion,
+		checks:        checks,
+	}
+}
+
+// RegisterRoutes регистрирует служебные эндпоинты в mux
+func (h *Health) RegisterRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("/healthz", h.handleHealthz)
+	mux.HandleFunc("/readyz", h.handleReadyz)
+	mux.HandleFunc("/version", h.handleVersion)
+}
+
+type readyResponse struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks"`
+}
+
+type versionResponse struct {
+	Version       string  `json:"version"`
+	Revision      string  `json:"revision,omitempty"`
+	RevisionTime  string  `json:"revision_time,omitempty"`
+	Modified      bool    `json:"modified,omitempty"`
+	GoVersion     string  `json:"go_version"`
+	SchemaVersion int     `json:"schema_version"`
+	Uptime        string  `json:"uptime"`
+	UptimeSeconds float64 `json:"uptime_seconds"`
+}
+
+// handleHealthz отвечает, что процесс жив; зависимости не проверяются
+func (h *Health) handleHealthz(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	writeJSON(r.Context(), w, map[string]string{"status": "ok"}, http.StatusOK)
+}
+
+// handleReadyz выполняет все проверки; при любой неудаче отвечает 503 с текстом ошибок
+func (h *Health) handleReadyz(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	resp := readyResponse{Status: "ok", Checks: make(map[string]string, len(h.checks))}
+	status := http.StatusOK
+	for _, c := range h.checks {
+		ctx, cancel := context.WithTimeout(r.Context(), readyTimeout)
+		err := c.Check(ctx)
+		cancel()
+
+		if err != nil {
+			resp.Status = "unavailable"
+			resp.Checks[c.Name] = err.Error()
+			status = http.StatusServiceUnavailable
+			continue
+		}
+		resp.Checks[c.Name] = "ok"
+	}
+
+	writeJSON(r.Context(), w, resp, status)
+}
+
+// handleVersion возвращает сведения о сборке, версию схемы БД и время работы
+func (h *Health) handleVersion(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	resp := buildVersion()
+	uptime := time.Since(h.started)
+	resp.Uptime = uptime.Round(time.Second).String()
+	resp.UptimeSeconds = uptime.Seconds()
+
+	version, err := h.schemaVersion()
+	if err != nil {
+		writeProblem(w, r, http.StatusInternalServerError, i18n.SchemaVersionUnavailable, i18n.Params{"cause": err})
+		return
+	}
+	resp.SchemaVersion = version
+
+	writeJSON(r.Context(), w, resp, http.StatusOK)
+}
+
+// buildVersion читает версию модуля и сведения о VCS, встроенные компилятором
+func buildVersion() versionResponse {
+	resp := versionResponse{Version: "unknown"}
+
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return resp
+	}
+
+	resp.Version = info.Main.Version
+	resp.GoVersion = info.GoVersion
+	for _, s := range info.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			resp.Revision = s.Value
+		case "vcs.time":
+			resp.RevisionTime = s.Value
+		case "vcs.modified":
+			resp.Modified = s.Value == "true"
+		}
+	}
+	return resp
+}
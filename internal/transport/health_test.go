@@ -0,0 +1,65 @@
+package transport
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHealth(t *testing.T) {
+	var dbErr error
+	health := NewHealth(func() (int, error) { return 3, nil },
+		HealthCheck{Name: "database", Check: func(context.Context) error { return dbErr }},
+	)
+	mux := http.NewServeMux()
+	health.RegisterRoutes(mux)
+
+	get := func(path string) (int, map[string]any) {
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		var body map[string]any
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		return rec.Code, body
+	}
+
+	code, body := get("/healthz")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "ok", body["status"])
+
+	code, body = get("/readyz")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, map[string]any{"database": "ok"}, body["checks"])
+
+	dbErr = errors.New("БД недоступна")
+	code, body = get("/readyz")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, "unavailable", body["status"])
+	assert.Equal(t, map[string]any{"database": "БД недоступна"}, body["checks"])
+
+	code, body = get("/version")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, float64(3), body["schema_version"])
+	assert.NotEmpty(t, body["version"])
+	assert.NotEmpty(t, body["go_version"])
+	assert.Contains(t, body, "uptime")
+}
+
+func TestAccessLogSkipsHealthPaths(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+	handler := AccessLog(logger, HealthPaths...)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/readyz", nil))
+	assert.Empty(t, buf.String())
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/tasks", nil))
+	assert.NotEmpty(t, buf.String())
+}
@@ -0,0 +1,96 @@
+package transport
+
+import (
+	"net/http"
+	"strconv"
+	"tasktracker/internal/domain/task"
+	"tasktracker/internal/i18n"
+	"time"
+)
+
+type createListRequest struct {
+	Name  string `json:"name"`
+	Query string `json:"query"`
+}
+
+// handleLists обрабатывает запросы к спискам задач (получение, создание, удаление сохраненных)
+func (h *Handler) handleLists(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	switch r.Method {
+	case http.MethodGet:
+		lists, err := h.service.GetSmartLists(r.Context(), requestLang(r))
+		if err != nil {
+			h.writeError(w, r, err)
+			return
+		}
+
+		writeJSON(r.Context(), w, map[string]interface{}{
+			"lists": lists,
+		}, http.StatusOK)
+
+	case http.MethodPost:
+		var req createListRequest
+		if err := decodeJSON(r, &req); err != nil {
+			h.writeError(w, r, err)
+			return
+		}
+
+		list := &task.SavedList{
+			Name:  req.Name,
+			Query: req.Query,
+		}
+		if err := h.service.CreateSavedList(r.Context(), list); err != nil {
+			h.writeError(w, r, err)
+			return
+		}
+
+		writeJSON(r.Context(), w, createTaskResponse{
+			ID: list.ID,
+		}, http.StatusOK)
+
+	case http.MethodDelete:
+		id, err := strconv.ParseInt(r.FormValue("id"), 10, 64)
+		if err != nil {
+			h.writeError(w, r, errBadRequest(i18n.RequestIDInvalid))
+			return
+		}
+
+		if err := h.service.DeleteSavedList(r.Context(), id); err != nil {
+			h.writeError(w, r, err)
+			return
+		}
+
+		writeJSON(r.Context(), w, map[string]string{}, http.StatusOK)
+
+	default:
+		h.writeError(w, r, errMethodNotAllowed)
+	}
+}
+
+// handleListTasks возвращает задачи встроенного или сохраненного списка
+func (h *Handler) handleListTasks(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != http.MethodGet {
+		h.writeError(w, r, errMethodNotAllowed)
+		return
+	}
+
+	query, err := parsePageParams(r)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	page, err := h.service.GetListTasks(r.Context(), r.PathValue("id"), query, time.Now())
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	writeJSON(r.Context(), w, taskListResponse{
+		Tasks:      page.Tasks,
+		NextCursor: page.NextCursor,
+	}, http.StatusOK)
+}
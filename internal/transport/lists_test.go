@@ -0,0 +1,45 @@
+package transport
+
+import (
+	"encoding/json"
+	"net/http"
+	"tasktracker/internal/domain/task"
+	"tasktracker/internal/storage/memory"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListsPerUser(t *testing.T) {
+	h := newAPI(memory.NewRepository(), task.WithOwnerContext(UserFromContext))
+	lists := func(user string) []task.SmartList {
+		t.Helper()
+		rec := serve(h, http.MethodGet, "/api/lists", "", asUser(user))
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		var resp struct {
+			Lists []task.SmartList `json:"lists"`
+		}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		return resp.Lists
+	}
+
+	rec := serve(h, http.MethodPost, "/api/lists", `{"name":"Работа","query":"title:отчет"}`, asUser("alice"))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	got := lists("alice")
+	require.Len(t, got, 5)
+	assert.Equal(t, task.SmartList{ID: "today", Name: "Today", BuiltIn: true}, got[0])
+	assert.Equal(t, "Next 7 days", got[2].Name)
+	assert.Equal(t, "Работа", got[4].Name)
+	assert.Len(t, lists("bob"), 4, "чужие списки не видны")
+	assert.Len(t, lists(""), 4)
+
+	target := "/api/lists/" + got[4].ID + "/tasks"
+	assert.Equal(t, http.StatusNotFound, serve(h, http.MethodGet, target, "", asUser("bob")).Code)
+	assert.Equal(t, http.StatusOK, serve(h, http.MethodGet, target, "", asUser("alice")).Code)
+
+	del := "/api/lists?id=" + got[4].ID
+	assert.Equal(t, http.StatusNotFound, serve(h, http.MethodDelete, del, "", asUser("bob")).Code)
+	assert.Equal(t, http.StatusOK, serve(h, http.MethodDelete, del, "", asUser("alice")).Code)
+}
@@ -0,0 +1,344 @@
+package transport
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"tasktracker/internal/i18n"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type contextKey int
+
+const (
+	requestInfoKey contextKey = iota
+	userKey
+)
+
+// RequestIDHeader — заголовок, в котором передается идентификатор запроса
+const RequestIDHeader = "X-Request-ID"
+
+// Middleware оборачивает обработчик дополнительной логикой
+type Middleware func(http.Handler) http.Handler
+
+// Chain применяет middleware к обработчику; первый в списке оказывается внешним
+func Chain(h http.Handler, middleware ...Middleware) http.Handler {
+	for i := len(middleware) - 1; i >= 0; i-- {
+		h = middleware[i](h)
+	}
+	return h
+}
+
+// requestInfo создается RequestID и заполняется по ходу обработки. Внутренние middleware
+// работают с копией запроса (r.WithContext), поэтому пользователь и маршрут, которые
+// они узнают, передаются внешним (журнал, метрики) через этот общий объект.
+type requestInfo struct {
+	id    string
+	user  string
+	route string
+}
+
+func infoFromContext(ctx context.Context) *requestInfo {
+	info, _ := ctx.Value(requestInfoKey).(*requestInfo)
+	return info
+}
+
+// RequestIDFromContext возвращает идентификатор текущего запроса
+func RequestIDFromContext(ctx context.Context) string {
+	if info := infoFromContext(ctx); info != nil {
+		return info.id
+	}
+	return ""
+}
+
+// WithUser сохраняет в контексте имя аутентифицированного пользователя
+func WithUser(ctx context.Context, user string) context.Context {
+	if info := infoFromContext(ctx); info != nil {
+		info.user = user
+	}
+	return context.WithValue(ctx, userKey, user)
+}
+
+// UserFromContext возвращает имя пользователя, если запрос аутентифицирован
+func UserFromContext(ctx context.Context) string {
+	if user, ok := ctx.Value(userKey).(string); ok {
+		return user
+	}
+	if info := infoFromContext(ctx); info != nil {
+		return info.user
+	}
+	return ""
+}
+
+// ActorAnonymous — автор изменений в журнале, когда аутентификация выключена
+const ActorAnonymous = "anonymous"
+
+// AuditContext возвращает автора изменения и идентификатор запроса для журнала изменений
+// (см. task.WithAuditContext)
+func AuditContext(ctx context.Context) (actor, requestID string) {
+	actor = UserFromContext(ctx)
+	if actor == "" {
+		actor = ActorAnonymous
+	}
+	return actor, RequestIDFromContext(ctx)
+}
+
+// Route возвращает шаблон маршрута, которым обработан запрос (например, /api/lists/{id}/tasks).
+// Вызывается после обработки запроса; пустая строка — маршрут не найден.
+func Route(r *http.Request) string {
+	if info := infoFromContext(r.Context()); info != nil && info.route != "" {
+		return info.route
+	}
+	return r.Pattern
+}
+
+// recordRoute запоминает шаблон маршрута, выбранный mux, для внешних middleware
+func recordRoute(mux *http.ServeMux) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// defer — чтобы маршрут был известен и при панике в обработчике
+		defer func() {
+			if info := infoFromContext(r.Context()); info != nil {
+				info.route = r.Pattern
+			}
+		}()
+		mux.ServeHTTP(w, r)
+	})
+}
+
+// RequestID берет идентификатор запроса из заголовка X-Request-ID или генерирует новый,
+// кладет его в контекст и возвращает клиенту в том же заголовке
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(RequestIDHeader)
+		if id == "" || len(id) > 128 {
+			id = newRequestID()
+		}
+		w.Header().Set(RequestIDHeader, id)
+		info := &requestInfo{id: id}
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), requestInfoKey, info)))
+	})
+}
+
+func newRequestID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "unknown"
+	}
+	return hex.EncodeToString(b)
+}
+
+// statusRecorder запоминает код ответа и количество записанных байт
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	n, err := r.ResponseWriter.Write(b)
+	r.bytes += n
+	return n, err
+}
+
+// Unwrap позволяет http.ResponseController добраться до исходного ResponseWriter
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// AccessLog пишет в лог строку о каждом обработанном запросе, кроме запросов к skipPaths.
+// Должен располагаться после RequestID, чтобы в логе был идентификатор запроса.
+func AccessLog(logger *slog.Logger, skipPaths ...string) Middleware {
+	skip := make(map[string]bool, len(skipPaths))
+	for _, p := range skipPaths {
+		skip[p] = true
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if skip[r.URL.Path] {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			start := time.Now()
+			rec := &statusRecorder{ResponseWriter: w}
+
+			next.ServeHTTP(rec, r)
+
+			status := rec.status
+			if status == 0 {
+				status = http.StatusOK
+			}
+
+			level := slog.LevelInfo
+			if status >= http.StatusInternalServerError {
+				level = slog.LevelError
+			}
+			logger.LogAttrs(r.Context(), level, "http request",
+				slog.String("method", r.Method),
+				slog.String("path", r.URL.Path),
+				slog.Int("status", status),
+				slog.Duration("latency", time.Since(start)),
+				slog.Int("bytes", rec.bytes),
+				slog.String("request_id", RequestIDFromContext(r.Context())),
+				slog.String("user", UserFromContext(r.Context())),
+				slog.String("remote_addr", r.RemoteAddr),
+			)
+		})
+	}
+}
+
+// Tracing открывает серверный span на каждый запрос. Родительский контекст берется
+// из заголовка traceparent (W3C Trace Context), если клиент его передал.
+// Должен располагаться после RequestID: имя span'а уточняется шаблоном маршрута (см. Route).
+func Tracing(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		ctx, span := tracer.Start(ctx, r.Method,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(r.Method),
+				semconv.URLPath(r.URL.Path),
+				attribute.String("request_id", RequestIDFromContext(ctx)),
+			))
+		defer span.End()
+
+		rec := &statusRecorder{ResponseWriter: w}
+		r = r.WithContext(ctx)
+		next.ServeHTTP(rec, r)
+
+		if route := Route(r); route != "" {
+			span.SetName(r.Method + " " + route)
+			span.SetAttributes(semconv.HTTPRoute(route))
+		}
+
+		status := rec.status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+		if status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(status))
+		}
+	})
+}
+
+// Recovery перехватывает панику в обработчике: пишет ее в лог со стеком и отвечает 500,
+// вместо того чтобы обрывать соединение. http.ErrAbortHandler пробрасывается дальше,
+// так как это штатный способ прервать ответ.
+func Recovery(logger *slog.Logger) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer func() {
+				v := recover()
+				if v == nil {
+					return
+				}
+				if err, ok := v.(error); ok && errors.Is(err, http.ErrAbortHandler) {
+					panic(v)
+				}
+
+				logger.ErrorContext(r.Context(), "panic in http handler",
+					"panic", v,
+					"method", r.Method,
+					"path", r.URL.Path,
+					"request_id", RequestIDFromContext(r.Context()),
+					"stack", string(debug.Stack()),
+				)
+				writeProblem(w, r, http.StatusInternalServerError, i18n.Internal, nil)
+			}()
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// corsMaxAge — сколько секунд браузер может кэшировать ответ на preflight-запрос
+const corsMaxAge = 600
+
+// CORS разрешает запросы со страниц из allowedOrigins ("*" — с любых).
+// Пустой список отключает CORS: заголовки не добавляются и браузер блокирует чужие запросы.
+// Preflight-запросы (OPTIONS с Access-Control-Request-Method) обрабатываются здесь же.
+func CORS(allowedOrigins []string) Middleware {
+	allowAll := false
+	allowed := make(map[string]bool, len(allowedOrigins))
+	for _, o := range allowedOrigins {
+		if o == "*" {
+			allowAll = true
+		}
+		allowed[strings.TrimSuffix(o, "/")] = true
+	}
+
+	return func(next http.Handler) http.Handler {
+		if len(allowed) == 0 {
+			return next
+		}
+
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+			header := w.Header()
+			header.Add("Vary", "Origin")
+			if origin == "" || !(allowAll || allowed[origin]) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			// Токен передается в cookie, поэтому вместо "*" всегда возвращаем конкретный Origin
+			header.Set("Access-Control-Allow-Origin", origin)
+			header.Set("Access-Control-Allow-Credentials", "true")
+			header.Set("Access-Control-Expose-Headers", RequestIDHeader)
+
+			if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+				header.Add("Vary", "Access-Control-Request-Method")
+				header.Add("Vary", "Access-Control-Request-Headers")
+				header.Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
+				if reqHeaders := r.Header.Get("Access-Control-Request-Headers"); reqHeaders != "" {
+					header.Set("Access-Control-Allow-Headers", reqHeaders)
+				}
+				header.Set("Access-Control-Max-Age", strconv.Itoa(corsMaxAge))
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// BodyLimit ограничивает размер тела запроса. Запрос с заведомо большим Content-Length
+// сразу получает 413; при чтении тела сверх лимита обработчик получит ошибку *http.MaxBytesError.
+func BodyLimit(maxBytes int64) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.ContentLength > maxBytes {
+				writeProblem(w, r, http.StatusRequestEntityTooLarge, i18n.RequestTooLarge, nil)
+				return
+			}
+			r.Body = http.MaxBytesReader(w, r.Body, maxBytes)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
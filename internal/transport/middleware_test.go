@@ -0,0 +1,213 @@
+package transport
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+func TestAccessLog(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+
+	handler := RequestID(AccessLog(logger)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+		w.Write([]byte("hello"))
+	})))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/tasks?search=x", nil)
+	req.Header.Set(RequestIDHeader, "req-1")
+	req = req.WithContext(WithUser(req.Context(), "alice"))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	assert.Equal(t, "req-1", rec.Header().Get(RequestIDHeader))
+
+	var entry map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	assert.Equal(t, "http request", entry["msg"])
+	assert.Equal(t, "GET", entry["method"])
+	assert.Equal(t, "/api/tasks", entry["path"])
+	assert.Equal(t, float64(http.StatusTeapot), entry["status"])
+	assert.Equal(t, float64(5), entry["bytes"])
+	assert.Equal(t, "req-1", entry["request_id"])
+	assert.Equal(t, "alice", entry["user"])
+	assert.Contains(t, entry, "latency")
+}
+
+func TestRequestIDGenerated(t *testing.T) {
+	var seen string
+	handler := RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = RequestIDFromContext(r.Context())
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.NotEmpty(t, seen)
+	assert.Equal(t, seen, rec.Header().Get(RequestIDHeader))
+}
+
+func TestTracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(noop.NewTracerProvider())
+		provider.Shutdown(context.Background())
+	})
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/lists/{id}/tasks", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(r.Context(), w, map[string]string{}, http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/lists/today/tasks", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	RequestID(Tracing(mux)).ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	encode, server := spans[0], spans[1]
+
+	assert.Equal(t, "GET /api/lists/{id}/tasks", server.Name())
+	assert.Equal(t, trace.SpanKindServer, server.SpanKind())
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", server.SpanContext().TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", server.Parent().SpanID().String())
+	assert.Contains(t, server.Attributes(), semconv.HTTPResponseStatusCode(http.StatusOK))
+
+	assert.Equal(t, "json.encode", encode.Name())
+	assert.Equal(t, server.SpanContext().SpanID(), encode.Parent().SpanID())
+}
+
+func TestChain(t *testing.T) {
+	var order []string
+	mark := func(name string) Middleware {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				order = append(order, name)
+				next.ServeHTTP(w, r)
+			})
+		}
+	}
+
+	handler := Chain(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		order = append(order, "handler")
+	}), mark("outer"), mark("inner"))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.Equal(t, []string{"outer", "inner", "handler"}, order)
+}
+
+func TestRouteVisibleToOuterMiddleware(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/lists/{id}/tasks", func(w http.ResponseWriter, r *http.Request) {})
+
+	var route, user string
+	handler := RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Внутренний middleware подменяет запрос, как это делает аутентификация
+		inner := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			recordRoute(mux).ServeHTTP(w, r.WithContext(WithUser(r.Context(), "alice")))
+		})
+		inner.ServeHTTP(w, r)
+		route, user = Route(r), UserFromContext(r.Context())
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/lists/7/tasks", nil))
+
+	assert.Equal(t, "/api/lists/{id}/tasks", route)
+	assert.Equal(t, "alice", user)
+}
+
+func TestRecovery(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+
+	handler := Recovery(logger)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("сбой")
+	}))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/tasks", nil))
+
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Equal(t, "application/problem+json", rec.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{
+		"type": "about:blank",
+		"title": "Internal Server Error",
+		"detail": "внутренняя ошибка сервера",
+		"instance": "/api/tasks",
+		"error": "внутренняя ошибка сервера",
+		"code": "internal"
+	}`, rec.Body.String())
+	assert.Contains(t, buf.String(), "panic in http handler")
+	assert.Contains(t, buf.String(), "сбой")
+
+	abort := Recovery(logger)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic(http.ErrAbortHandler)
+	}))
+	assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+		abort.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	})
+}
+
+func TestCORS(t *testing.T) {
+	called := false
+	handler := CORS([]string{"https://app.example.com"})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+
+	req := httptest.NewRequest(http.MethodOptions, "/api/task", nil)
+	req.Header.Set("Origin", "https://app.example.com")
+	req.Header.Set("Access-Control-Request-Method", http.MethodPut)
+	req.Header.Set("Access-Control-Request-Headers", "content-type")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	assert.False(t, called, "preflight не должен доходить до обработчика")
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+	assert.Equal(t, "https://app.example.com", rec.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "content-type", rec.Header().Get("Access-Control-Allow-Headers"))
+	assert.Contains(t, rec.Header().Get("Access-Control-Allow-Methods"), http.MethodPut)
+
+	req = httptest.NewRequest(http.MethodGet, "/api/tasks", nil)
+	req.Header.Set("Origin", "https://evil.example.com")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	assert.True(t, called)
+	assert.Empty(t, rec.Header().Get("Access-Control-Allow-Origin"))
+}
+
+func TestBodyLimit(t *testing.T) {
+	var readErr error
+	handler := BodyLimit(8)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, readErr = io.ReadAll(r.Body)
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/api/task", strings.NewReader("0123456789")))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+
+	// Без Content-Length лимит срабатывает при чтении тела
+	req := httptest.NewRequest(http.MethodPost, "/api/task", io.NopCloser(strings.NewReader("0123456789")))
+	req.ContentLength = -1
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+	var maxErr *http.MaxBytesError
+	assert.ErrorAs(t, readErr, &maxErr)
+}
@@ -0,0 +1,238 @@
+package transport
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io/fs"
+	"mime"
+	"net/http"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/andybalholm/brotli"
+)
+
+// hashedAsset распознает файлы с хешем содержимого в имени (app.3f2a9c1b.js):
+// их содержимое никогда не меняется, поэтому кэшируются навсегда
+var hashedAsset = regexp.MustCompile(`\.[0-9a-fA-F]{8,}\.[^./]+$`)
+
+const (
+	cacheImmutable   = "public, max-age=31536000, immutable"
+	cacheRevalidate  = "no-cache" // Браузер хранит копию, но каждый раз сверяет ETag
+	minCompressBytes = 256        // Маленькие файлы сжимать невыгодно
+)
+
+// staticFile — файл фронтенда вместе со сжатыми вариантами
+type staticFile struct {
+	contentType string
+	etag        string // Хеш исходного содержимого, у сжатых вариантов добавляется суффикс
+	content     []byte
+	gzip        []byte
+	brotli      []byte
+}
+
+// Static раздает файлы фронтенда из fs.FS (встроенного через go:embed или каталога на диске).
+//
+// Каждый ответ содержит ETag, поэтому повторные запросы с If-None-Match получают 304.
+// Файлы с хешем в имени отдаются с Cache-Control: immutable, остальные — с no-cache.
+// Если клиент принимает br или gzip, отдается сжатый вариант: готовый файл name.br / name.gz
+// из того же fs.FS или сжатый при первом запросе.
+type Static struct {
+	fsys  fs.FS
+	cache bool
+
+	mu    sync.Mutex
+	files map[string]*staticFile
+}
+
+// NewStatic создает обработчик статических файлов. При cache = true файлы читаются
+// и сжимаются один раз (для встроенных файлов); при false — на каждый запрос, чтобы
+// при разработке изменения на диске были видны сразу.
+func NewStatic(fsys fs.FS, cache bool) *Static {
+	return &Static{
+		fsys:  fsys,
+		cache: cache,
+		files: make(map[string]*staticFile),
+	}
+}
+
+func (s *Static) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	name := strings.TrimPrefix(path.Clean("/"+r.URL.Path), "/")
+	if name == "" {
+		name = "index.html"
+	}
+
+	file, err := s.file(name)
+	if errors.Is(err, fs.ErrNotExist) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	header := w.Header()
+	header.Set("Content-Type", file.contentType)
+	header.Add("Vary", "Accept-Encoding")
+	if hashedAsset.MatchString(name) {
+		header.Set("Cache-Control", cacheImmutable)
+	} else {
+		header.Set("Cache-Control", cacheRevalidate)
+	}
+
+	body, etag := file.content, file.etag
+	switch accepted := acceptedEncodings(r.Header.Get("Accept-Encoding")); {
+	case file.brotli != nil && accepted["br"]:
+		header.Set("Content-Encoding", "br")
+		body, etag = file.brotli, strings.TrimSuffix(etag, `"`)+`-br"`
+	case file.gzip != nil && accepted["gzip"]:
+		header.Set("Content-Encoding", "gzip")
+		body, etag = file.gzip, strings.TrimSuffix(etag, `"`)+`-gz"`
+	}
+	header.Set("ETag", etag)
+
+	// ServeContent обрабатывает If-None-Match, Range и HEAD
+	http.ServeContent(w, r, name, time.Time{}, bytes.NewReader(body))
+}
+
+// file возвращает файл из кэша или читает его из fs.FS. Каталоги отдаются через их index.html.
+func (s *Static) file(name string) (*staticFile, error) {
+	if !s.cache {
+		return s.load(name)
+	}
+
+	s.mu.Lock()
+	f, ok := s.files[name]
+	s.mu.Unlock()
+	if ok {
+		return f, nil
+	}
+
+	// Сжатие выполняется без блокировки; одновременные первые запросы просто сожмут файл дважды
+	f, err := s.load(name)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	s.files[name] = f
+	s.mu.Unlock()
+	return f, nil
+}
+
+func (s *Static) load(name string) (*staticFile, error) {
+	info, err := fs.Stat(s.fsys, name)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		name = path.Join(name, "index.html")
+	}
+
+	content, err := fs.ReadFile(s.fsys, name)
+	if err != nil {
+		return nil, err
+	}
+
+	sum := sha256.Sum256(content)
+	f := &staticFile{
+		contentType: mime.TypeByExtension(path.Ext(name)),
+		etag:        `"` + hex.EncodeToString(sum[:16]) + `"`,
+		content:     content,
+	}
+	if f.contentType == "" {
+		f.contentType = http.DetectContentType(content)
+	}
+
+	// Готовые сжатые варианты имеют приоритет; сжатие на лету — только для кэшируемых файлов
+	f.brotli = s.precompressed(name + ".br")
+	f.gzip = s.precompressed(name + ".gz")
+	if s.cache && compressible(f.contentType) && len(content) >= minCompressBytes {
+		if f.brotli == nil {
+			f.brotli = smaller(compressBrotli(content), content)
+		}
+		if f.gzip == nil {
+			f.gzip = smaller(compressGzip(content), content)
+		}
+	}
+
+	return f, nil
+}
+
+func (s *Static) precompressed(name string) []byte {
+	data, err := fs.ReadFile(s.fsys, name)
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+// compressible отбирает текстовые форматы; изображения и архивы уже сжаты
+func compressible(contentType string) bool {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch {
+	case strings.HasPrefix(mediaType, "text/"):
+		return true
+	case mediaType == "application/javascript", mediaType == "application/json",
+		mediaType == "image/svg+xml", mediaType == "image/vnd.microsoft.icon", mediaType == "image/x-icon":
+		return true
+	}
+	return false
+}
+
+func compressGzip(data []byte) []byte {
+	var buf bytes.Buffer
+	zw, _ := gzip.NewWriterLevel(&buf, gzip.BestCompression)
+	zw.Write(data)
+	zw.Close()
+	return buf.Bytes()
+}
+
+func compressBrotli(data []byte) []byte {
+	var buf bytes.Buffer
+	bw := brotli.NewWriterLevel(&buf, brotli.BestCompression)
+	bw.Write(data)
+	bw.Close()
+	return buf.Bytes()
+}
+
+// smaller возвращает сжатый вариант, только если он действительно меньше исходного
+func smaller(compressed, original []byte) []byte {
+	if len(compressed) >= len(original) {
+		return nil
+	}
+	return compressed
+}
+
+// acceptedEncodings разбирает Accept-Encoding; кодировки с q=0 считаются запрещенными
+func acceptedEncodings(header string) map[string]bool {
+	accepted := make(map[string]bool)
+	for _, part := range strings.Split(header, ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if coding == "" {
+			continue
+		}
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if parsed, err := strconv.ParseFloat(v, 64); err == nil {
+				q = parsed
+			}
+		}
+		accepted[strings.ToLower(coding)] = q > 0
+	}
+	return accepted
+}
@@ -0,0 +1,130 @@
+package transport
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/andybalholm/brotli"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatic(t *testing.T) {
+	script := []byte(strings.Repeat("console.log('tasktracker');\n", 50))
+	fsys := fstest.MapFS{
+		"index.html":          {Data: []byte("<html>index</html>")},
+		"js/app.js":           {Data: script},
+		"js/app.0123abcd.js":  {Data: script},
+		"css/style.css":       {Data: []byte(strings.Repeat("body { color: red; }\n", 50))},
+		"css/style.css.gz":    {Data: []byte("готовый gzip")},
+		"favicon.ico":         {Data: []byte{0, 0, 1, 0}},
+		"docs/index.html":     {Data: []byte("<html>docs</html>")},
+		"docs/readme.unknown": {Data: []byte("plain")},
+	}
+	static := NewStatic(fsys, true)
+
+	serve := func(path, acceptEncoding, ifNoneMatch string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if acceptEncoding != "" {
+			req.Header.Set("Accept-Encoding", acceptEncoding)
+		}
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		rec := httptest.NewRecorder()
+		static.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("index", func(t *testing.T) {
+		rec := serve("/", "", "")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "<html>index</html>", rec.Body.String())
+		assert.Equal(t, "<html>docs</html>", serve("/docs/", "", "").Body.String())
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, serve("/missing.js", "", "").Code)
+		assert.Equal(t, http.StatusNotFound, serve("/../go.mod", "", "").Code)
+	})
+
+	t.Run("etag", func(t *testing.T) {
+		rec := serve("/js/app.js", "", "")
+		etag := rec.Header().Get("ETag")
+		require.NotEmpty(t, etag)
+		assert.Equal(t, "no-cache", rec.Header().Get("Cache-Control"))
+		assert.Equal(t, script, rec.Body.Bytes())
+
+		rec = serve("/js/app.js", "", etag)
+		assert.Equal(t, http.StatusNotModified, rec.Code)
+		assert.Empty(t, rec.Body.Bytes())
+	})
+
+	t.Run("immutable", func(t *testing.T) {
+		rec := serve("/js/app.0123abcd.js", "", "")
+		assert.Equal(t, "public, max-age=31536000, immutable", rec.Header().Get("Cache-Control"))
+	})
+
+	t.Run("brotli", func(t *testing.T) {
+		rec := serve("/js/app.js", "gzip, br", "")
+		assert.Equal(t, "br", rec.Header().Get("Content-Encoding"))
+		assert.Contains(t, rec.Header().Values("Vary"), "Accept-Encoding")
+		assert.Less(t, rec.Body.Len(), len(script))
+
+		body, err := io.ReadAll(brotli.NewReader(rec.Body))
+		require.NoError(t, err)
+		assert.Equal(t, script, body)
+		assert.NotEqual(t, serve("/js/app.js", "", "").Header().Get("ETag"), rec.Header().Get("ETag"))
+	})
+
+	t.Run("gzip", func(t *testing.T) {
+		rec := serve("/js/app.js", "gzip, br;q=0", "")
+		assert.Equal(t, "gzip", rec.Header().Get("Content-Encoding"))
+
+		zr, err := gzip.NewReader(bytes.NewReader(rec.Body.Bytes()))
+		require.NoError(t, err)
+		body, err := io.ReadAll(zr)
+		require.NoError(t, err)
+		assert.Equal(t, script, body)
+	})
+
+	t.Run("precompressed", func(t *testing.T) {
+		rec := serve("/css/style.css", "gzip", "")
+		assert.Equal(t, "gzip", rec.Header().Get("Content-Encoding"))
+		assert.Equal(t, "готовый gzip", rec.Body.String())
+		assert.Contains(t, rec.Header().Get("Content-Type"), "text/css")
+	})
+
+	t.Run("small files are not compressed", func(t *testing.T) {
+		rec := serve("/favicon.ico", "gzip, br", "")
+		assert.Empty(t, rec.Header().Get("Content-Encoding"))
+		assert.Equal(t, []byte{0, 0, 1, 0}, rec.Body.Bytes())
+	})
+
+	t.Run("method", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		rec := httptest.NewRecorder()
+		static.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+	})
+}
+
+func TestStaticFromDiskIsNotCached(t *testing.T) {
+	fsys := fstest.MapFS{"index.html": {Data: []byte("v1")}}
+	static := NewStatic(fsys, false)
+
+	rec := httptest.NewRecorder()
+	static.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, "v1", rec.Body.String())
+
+	fsys["index.html"] = &fstest.MapFile{Data: []byte("v2")}
+	rec = httptest.NewRecorder()
+	static.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	assert.Equal(t, "v2", rec.Body.String())
+}
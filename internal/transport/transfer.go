@@ -0,0 +1,204 @@
+package transport
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"tasktracker/internal/domain/task"
+	"tasktracker/internal/i18n"
+	"tasktracker/internal/importer"
+	"time"
+)
+
+// importResponse — итог импорта; ошибки записей переведены на язык клиента
+type importResponse struct {
+	Mode    task.ImportMode   `json:"mode"`
+	DryRun  bool              `json:"dry_run"`
+	Applied bool              `json:"applied"`
+	Tasks   task.ImportCounts `json:"tasks"`
+	Lists   task.ImportCounts `json:"lists"`
+	Errors  []importItemError `json:"errors,omitempty"`
+}
+
+// importItemError — ошибка в одной записи файла импорта
+type importItemError struct {
+	Item     string    `json:"item"`
+	Index    int       `json:"index"`
+	ID       int64     `json:"id,omitempty,string"`
+	SourceID string    `json:"source_id,omitempty"` // Запись в файле другого планировщика
+	Field    string    `json:"field,omitempty"`
+	Code     i18n.Code `json:"code"`
+	Message  string    `json:"message"`
+}
+
+// sourceImportResponse — итог импорта из другого планировщика
+type sourceImportResponse struct {
+	Source string `json:"source"`
+	importResponse
+	Skipped  int             `json:"skipped"` // Выполненные и удаленные записи
+	Warnings []importWarning `json:"warnings"`
+}
+
+// importWarning — то, что не удалось перенести из записи источника
+type importWarning struct {
+	SourceID string    `json:"source_id"`
+	Title    string    `json:"title,omitempty"`
+	Field    string    `json:"field,omitempty"`
+	Code     i18n.Code `json:"code"`
+	Message  string    `json:"message"`
+}
+
+// handleExport выгружает все задачи и сохраненные списки файлом JSON
+func (h *Handler) handleExport(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != http.MethodGet {
+		h.writeError(w, r, errMethodNotAllowed)
+		return
+	}
+
+	now := time.Now()
+	archive, err := h.service.Export(r.Context(), now)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	name := "tasktracker-" + now.UTC().Format("20060102T150405Z") + ".json"
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", name))
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(r.Context(), w, archive, http.StatusOK)
+}
+
+// handleImport загружает выгрузку из тела запроса. Параметры: mode (merge, replace
+// или append) и dry_run=1 — только проверить и подсчитать изменения. Если в записях
+// есть ошибки, ничего не сохраняется и ответ — 422 с итогом (при dry_run — 200).
+func (h *Handler) handleImport(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != http.MethodPost {
+		h.writeError(w, r, errMethodNotAllowed)
+		return
+	}
+
+	mode, err := task.ParseImportMode(r.FormValue("mode"))
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+	dryRun := isDryRun(r)
+
+	var archive task.Archive
+	if err := decodeJSON(r, &archive); err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+
+	summary, err := h.service.Import(r.Context(), &archive, task.ImportOptions{Mode: mode, DryRun: dryRun})
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+	if summary.Applied {
+		h.logger.InfoContext(r.Context(), "data imported",
+			"mode", summary.Mode,
+			"tasks_created", summary.Tasks.Created,
+			"tasks_updated", summary.Tasks.Updated,
+			"tasks_deleted", summary.Tasks.Deleted,
+		)
+	}
+
+	status := http.StatusOK
+	if len(summary.Errors) > 0 && !dryRun {
+		status = http.StatusUnprocessableEntity
+	}
+	writeJSON(r.Context(), w, newImportResponse(summary, requestLang(r)), status)
+}
+
+// handleImportSource добавляет задачи из файла другого планировщика: Todoist, Taskwarrior
+// или Trello (см. importer). Параметр dry_run=1 — только проверить. Коды ответа те же,
+// что у handleImport; то, что не удалось перенести, перечисляется в warnings.
+func (h *Handler) handleImportSource(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	if r.Method != http.MethodPost {
+		h.writeError(w, r, errMethodNotAllowed)
+		return
+	}
+	dryRun := isDryRun(r)
+
+	report, err := importer.Run(r.Context(), h.service, r.PathValue("source"), r.Body, time.Now(), dryRun)
+	if err != nil {
+		h.writeError(w, r, err)
+		return
+	}
+	if report.Summary.Applied {
+		h.logger.InfoContext(r.Context(), "tasks imported",
+			"source", report.Source,
+			"tasks_created", report.Summary.Tasks.Created,
+			"skipped", report.Skipped,
+			"warnings", len(report.Warnings),
+		)
+	}
+
+	lang := requestLang(r)
+	resp := sourceImportResponse{
+		Source:         report.Source,
+		importResponse: newImportResponse(report.Summary, lang),
+		Skipped:        report.Skipped,
+		Warnings:       []importWarning{},
+	}
+	for i := range resp.Errors {
+		if index := resp.Errors[i].Index; index < len(report.Items) {
+			resp.Errors[i].SourceID = report.Items[index]
+		}
+	}
+	for _, warn := range report.Warnings {
+		item := importWarning{SourceID: warn.Item, Title: warn.Title}
+		item.Field, item.Code, item.Message = describeError(warn.Err, lang)
+		resp.Warnings = append(resp.Warnings, item)
+	}
+
+	status := http.StatusOK
+	if len(resp.Errors) > 0 && !dryRun {
+		status = http.StatusUnprocessableEntity
+	}
+	writeJSON(r.Context(), w, resp, status)
+}
+
+func newImportResponse(summary *task.ImportSummary, lang i18n.Lang) importResponse {
+	resp := importResponse{
+		Mode:    summary.Mode,
+		DryRun:  summary.DryRun,
+		Applied: summary.Applied,
+		Tasks:   summary.Tasks,
+		Lists:   summary.Lists,
+	}
+	for _, e := range summary.Errors {
+		item := importItemError{Item: e.Item, Index: e.Index, ID: e.ID}
+		item.Field, item.Code, item.Message = describeError(e.Err, lang)
+		resp.Errors = append(resp.Errors, item)
+	}
+	return resp
+}
+
+// isDryRun сообщает, что запрошен пробный импорт: dry_run=1 или dry_run=true
+func isDryRun(r *http.Request) bool {
+	return r.FormValue("dry_run") == "1" || r.FormValue("dry_run") == "true"
+}
+
+// describeError возвращает поле, самый конкретный код и текст ошибки валидации на языке lang.
+// Текст прочих ошибок (обычно ошибок хранилища на русском) клиенту не показывается.
+func describeError(err error, lang i18n.Lang) (field string, code i18n.Code, message string) {
+	var (
+		domainErr *task.Error
+		filterErr *task.FilterError
+	)
+	switch {
+	case errors.As(err, &filterErr):
+		return "query", filterErr.Code, filterErr.Localize(lang)
+	case errors.As(err, &domainErr):
+		return domainErr.Field, domainErr.Innermost().Code, domainErr.Localize(lang)
+	}
+	return "", i18n.Internal, i18n.Message(lang, i18n.Internal, nil)
+}
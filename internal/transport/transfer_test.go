@@ -0,0 +1,121 @@
+package transport
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"tasktracker/internal/domain/task"
+	"tasktracker/internal/storage/memory"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportImportEndpoints(t *testing.T) {
+	repo := memory.NewRepository()
+	require.NoError(t, repo.Create(context.Background(), &task.Task{Date: "20240110", Title: "Отчет", Repeat: "d 7"}))
+	h := newAPI(repo)
+
+	rec := serve(h, http.MethodGet, "/api/export", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Header().Get("Content-Disposition"), `.json"`)
+	archive := rec.Body.String()
+	assert.Contains(t, archive, `"format":"tasktracker"`)
+	assert.Contains(t, archive, `"id":"1"`)
+
+	var summary importResponse
+	rec = serve(h, http.MethodPost, "/api/import?mode=append&dry_run=1", archive)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &summary))
+	assert.Equal(t, importResponse{Mode: task.ImportAppend, DryRun: true, Tasks: task.ImportCounts{Created: 1}}, summary)
+
+	rec = serve(h, http.MethodPost, "/api/import", archive)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &summary))
+	assert.True(t, summary.Applied)
+	assert.Equal(t, task.ImportCounts{Skipped: 1}, summary.Tasks)
+
+	// Ошибки записей: ничего не сохраняется, ответ — 422 с переведенными сообщениями
+	invalid := `{"format":"tasktracker","version":1,"tasks":[{"id":"5","date":"20240101","title":""}],
+		"lists":[{"name":"Список","query":"is:later"}]}`
+	rec = serve(h, http.MethodPost, "/api/import", invalid)
+	require.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	summary = importResponse{}
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &summary))
+	assert.False(t, summary.Applied)
+	assert.Equal(t, []importItemError{
+		{Item: "task", Index: 0, ID: 5, Field: "title", Code: "task.title.empty", Message: "task title must not be empty"},
+		{Item: "list", Index: 0, Field: "query", Code: "filter.state_unknown", Message: summary.Errors[1].Message},
+	}, summary.Errors)
+	assert.Contains(t, summary.Errors[1].Message, "later")
+
+	rec = serve(h, http.MethodPost, "/api/import?dry_run=1", invalid)
+	assert.Equal(t, http.StatusOK, rec.Code, "пробный импорт сообщает об ошибках без ошибки запроса")
+
+	rec = serve(h, http.MethodPost, "/api/import?mode=upsert", archive)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"import.mode.unknown"`)
+
+	rec = serve(h, http.MethodPost, "/api/import", `{"format":"todoist","version":1}`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"import.format.unsupported"`)
+
+	rec = serve(h, http.MethodPost, "/api/import", `{"tasks":`)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = serve(h, http.MethodPost, "/api/export", "")
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+	rec = serve(h, http.MethodGet, "/api/import", "")
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
+
+func TestImportSourceEndpoint(t *testing.T) {
+	repo := memory.NewRepository()
+	h := newAPI(repo)
+	importSource := func(target, body string) (int, sourceImportResponse) {
+		rec := serve(h, http.MethodPost, target, body)
+		var resp sourceImportResponse
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &resp), rec.Body.String())
+		return rec.Code, resp
+	}
+
+	export := `[
+		{"uuid": "a", "description": "Полив", "status": "pending", "recur": "3d", "due": "20240116T090000Z"},
+		{"uuid": "b", "description": "Раз в 5 месяцев", "status": "pending", "recur": "5mo"},
+		{"uuid": "c", "description": "Готово", "status": "completed"}
+	]`
+	status, resp := importSource("/api/import/taskwarrior?dry_run=1", export)
+	require.Equal(t, http.StatusOK, status)
+	assert.Equal(t, "taskwarrior", resp.Source)
+	assert.False(t, resp.Applied)
+	assert.Equal(t, 2, resp.Tasks.Created)
+	assert.Equal(t, 1, resp.Skipped)
+	assert.Equal(t, []importWarning{{
+		SourceID: "b",
+		Title:    "Раз в 5 месяцев",
+		Field:    "repeat",
+		Code:     "import.repeat.unmapped",
+		Message:  `recurrence "5mo" has no matching repeat rule, the task was imported without repeating`,
+	}}, resp.Warnings)
+
+	status, resp = importSource("/api/import/taskwarrior", export)
+	require.Equal(t, http.StatusOK, status)
+	assert.True(t, resp.Applied)
+	tasks, err := repo.GetTasks(context.Background(), &task.ListQuery{Limit: 10})
+	require.NoError(t, err)
+	assert.Len(t, tasks, 2)
+
+	rec := serve(h, http.MethodPost, "/api/import/asana", "{}")
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"import.source.unknown"`)
+
+	rec = serve(h, http.MethodPost, "/api/import/trello", "not json")
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"import.file.invalid"`)
+
+	// Загрузка CSV по-прежнему обрабатывается своим маршрутом
+	rec = serve(h, http.MethodPost, "/api/import/csv", "title\nИз таблицы\n")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), `"created":1`)
+}
@@ -0,0 +1,59 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type auditEntry struct {
+	ID      string                        `json:"id"`
+	Actor   string                        `json:"actor"`
+	Action  string                        `json:"action"`
+	TaskID  string                        `json:"task_id"`
+	Changes map[string]map[string]*string `json:"changes"`
+}
+
+func getAudit(t *testing.T, apipath string) []auditEntry {
+	t.Helper()
+	body, err := requestJSON(apipath, nil, http.MethodGet)
+	require.NoError(t, err)
+	var resp struct {
+		Entries []auditEntry `json:"entries"`
+	}
+	require.NoError(t, json.Unmarshal(body, &resp), string(body))
+	return resp.Entries
+}
+
+func TestAuditLog(t *testing.T) {
+	date := time.Now().AddDate(0, 0, 10).Format(`20060102`)
+	ret, err := postJSON("api/task", map[string]any{"date": date, "title": "Для журнала"}, http.MethodPost)
+	require.NoError(t, err)
+	id := fmt.Sprint(ret["id"])
+	require.NotEmpty(t, id)
+
+	_, err = postJSON("api/task", map[string]any{"id": id, "date": date, "title": "Для журнала", "comment": "изменена"}, http.MethodPut)
+	require.NoError(t, err)
+	_, err = postJSON("api/task/done?id="+id, nil, http.MethodPost)
+	require.NoError(t, err)
+
+	history := getAudit(t, "api/task/history?id="+id)
+	require.Len(t, history, 3)
+	assert.Equal(t, []string{"complete", "update", "create"},
+		[]string{history[0].Action, history[1].Action, history[2].Action})
+	for _, e := range history {
+		assert.Equal(t, id, e.TaskID)
+		assert.NotEmpty(t, e.Actor)
+	}
+	assert.Equal(t, "изменена", *history[1].Changes["comment"]["after"])
+	assert.Nil(t, history[0].Changes["title"]["after"], "задача без повторения удалена")
+
+	updates := getAudit(t, "api/audit?action=update&task_id="+id)
+	require.Len(t, updates, 1)
+	assert.Equal(t, history[1].ID, updates[0].ID)
+}
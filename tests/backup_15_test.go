@@ -0,0 +1,74 @@
+package tests
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// downloadBackup скачивает копию БД и возвращает ее содержимое и Content-Type
+func downloadBackup(t *testing.T, query string) ([]byte, string) {
+	resp := requestBackup(t, query)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	data, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return data, resp.Header.Get("Content-Type")
+}
+
+func requestBackup(t *testing.T, query string) *http.Response {
+	req, err := http.NewRequest(http.MethodGet, getURL("api/admin/backup"+query), nil)
+	require.NoError(t, err)
+	if len(Token) > 0 {
+		req.AddCookie(&http.Cookie{Name: "token", Value: Token})
+	}
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	return resp
+}
+
+func TestBackup(t *testing.T) {
+	// Без аутентификации сервер не отдает копию БД
+	if len(Token) == 0 {
+		resp := requestBackup(t, "")
+		resp.Body.Close()
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+		return
+	}
+
+	addTask(t, task{
+		date:  time.Now().Format(`20060102`),
+		title: "Попадет в копию",
+	})
+
+	data, contentType := downloadBackup(t, "")
+	assert.Equal(t, "application/vnd.sqlite3", contentType)
+	require.True(t, bytes.HasPrefix(data, []byte("SQLite format 3\x00")), "ожидается файл SQLite")
+
+	path := filepath.Join(t.TempDir(), "backup.db")
+	require.NoError(t, os.WriteFile(path, data, 0o600))
+	db, err := sqlx.Connect("sqlite3", path)
+	require.NoError(t, err)
+	defer db.Close()
+	var n int
+	require.NoError(t, db.Get(&n, `SELECT count(id) FROM scheduler WHERE title = 'Попадет в копию'`))
+	assert.Positive(t, n)
+
+	data, contentType = downloadBackup(t, "?gzip=1")
+	assert.Equal(t, "application/gzip", contentType)
+	zr, err := gzip.NewReader(bytes.NewReader(data))
+	require.NoError(t, err)
+	unpacked, err := io.ReadAll(zr)
+	require.NoError(t, err)
+	assert.True(t, bytes.HasPrefix(unpacked, []byte("SQLite format 3\x00")))
+}
@@ -0,0 +1,41 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCSVImportExport(t *testing.T) {
+	date := time.Now().AddDate(0, 0, 40)
+	body := "Задача;Срок;Заметки\n" +
+		"CSV-импорт первая;" + date.Format("02.01.2006") + ";из таблицы\n" +
+		";" + date.Format("20060102") + ";без заголовка\n" +
+		"CSV-импорт вторая;" + date.Format("20060102") + ";\n"
+
+	params := url.Values{"title": {"Задача"}, "date": {"Срок"}, "comment": {"Заметки"}}
+	status, data := transfer(t, http.MethodPost, "api/import/csv?"+params.Encode(), body)
+	require.Equal(t, http.StatusOK, status, string(data))
+	var resp struct {
+		Created int              `json:"created"`
+		Errors  []map[string]any `json:"errors"`
+	}
+	require.NoError(t, json.Unmarshal(data, &resp))
+	assert.Equal(t, 2, resp.Created)
+	require.Len(t, resp.Errors, 1)
+	assert.EqualValues(t, 3, resp.Errors[0]["row"])
+	assert.Equal(t, "task.title.empty", resp.Errors[0]["code"])
+
+	status, data = transfer(t, http.MethodGet, "api/tasks?format=csv&search="+url.QueryEscape(`title:"CSV-импорт"`), "")
+	require.Equal(t, http.StatusOK, status)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, 3)
+	assert.Equal(t, "id,date,title,comment,repeat", lines[0])
+	assert.Contains(t, lines[1], date.Format("20060102")+",CSV-импорт первая,из таблицы,")
+}
@@ -0,0 +1,79 @@
+package tests
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// requestProblem выполняет запрос и возвращает код ответа, Content-Type и тело ошибки
+func requestProblem(t *testing.T, method, apipath, body string, lang ...string) (int, string, map[string]any) {
+	req, err := http.NewRequest(method, getURL(apipath), strings.NewReader(body))
+	require.NoError(t, err)
+	if len(lang) > 0 {
+		req.Header.Set("Accept-Language", lang[0])
+	}
+	if len(Token) > 0 {
+		req.AddCookie(&http.Cookie{Name: "token", Value: Token})
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	var m map[string]any
+	require.NoError(t, json.Unmarshal(data, &m), string(data))
+	return resp.StatusCode, resp.Header.Get("Content-Type"), m
+}
+
+func TestProblemResponses(t *testing.T) {
+	tbl := []struct {
+		method, path, body string
+		status             int
+	}{
+		{http.MethodDelete, "api/task?id=999999999", "", http.StatusNotFound},
+		{http.MethodGet, "api/task?id=999999999", "", http.StatusNotFound},
+		{http.MethodPost, "api/task/done?id=999999999", "", http.StatusNotFound},
+		{http.MethodGet, "api/lists/999999999/tasks", "", http.StatusNotFound},
+		{http.MethodPost, "api/task", `{"date":"20240101","title":""}`, http.StatusBadRequest},
+		{http.MethodPost, "api/task", `{`, http.StatusBadRequest},
+		{http.MethodGet, "api/tasks?search=is:work", "", http.StatusBadRequest},
+		{http.MethodPatch, "api/tasks", "", http.StatusMethodNotAllowed},
+	}
+	for _, v := range tbl {
+		status, contentType, m := requestProblem(t, v.method, v.path, v.body)
+		assert.Equal(t, v.status, status, "%s %s", v.method, v.path)
+		assert.Equal(t, "application/problem+json", contentType, "%s %s", v.method, v.path)
+		assert.NotEmpty(t, m["error"], "Ожидается поле error для %s %s", v.method, v.path)
+		assert.Equal(t, m["error"], m["detail"])
+		assert.Equal(t, "/"+strings.Split(v.path, "?")[0], m["instance"])
+	}
+
+	_, _, m := requestProblem(t, http.MethodPost, "api/task", `{"date":"20240101","title":"Задача","repeat":"ooops"}`)
+	assert.Equal(t, "repeat.unsupported_type", m["code"])
+	assert.Equal(t, []any{map[string]any{
+		"field":   "repeat",
+		"code":    "repeat.unsupported_type",
+		"message": m["error"],
+	}}, m["fields"])
+}
+
+func TestProblemLanguage(t *testing.T) {
+	body := `{"date":"20240101","title":"Задача","repeat":"w 9"}`
+
+	_, _, m := requestProblem(t, http.MethodPost, "api/task", body)
+	assert.Equal(t, "repeat.week.day_out_of_range", m["code"])
+	assert.Equal(t, "некорректное правило повторения: день недели должен быть от 1 до 7: 9", m["error"])
+
+	_, _, m = requestProblem(t, http.MethodPost, "api/task", body, "en-US,en;q=0.9,ru;q=0.8")
+	assert.Equal(t, "repeat.week.day_out_of_range", m["code"])
+	assert.Equal(t, "invalid repeat rule: day of the week must be between 1 and 7: 9", m["error"])
+	assert.Equal(t, map[string]any{"day": float64(9)}, m["params"])
+}
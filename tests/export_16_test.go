@@ -0,0 +1,91 @@
+package tests
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// transfer выполняет запрос к /api/export или /api/import и возвращает код ответа и тело
+func transfer(t *testing.T, method, apipath, body string) (int, []byte) {
+	req, err := http.NewRequest(method, getURL(apipath), strings.NewReader(body))
+	require.NoError(t, err)
+	if len(Token) > 0 {
+		req.AddCookie(&http.Cookie{Name: "token", Value: Token})
+	}
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return resp.StatusCode, data
+}
+
+type importSummary struct {
+	Applied bool                         `json:"applied"`
+	Tasks   map[string]int               `json:"tasks"`
+	Errors  []map[string]json.RawMessage `json:"errors"`
+}
+
+func importArchive(t *testing.T, query, body string) (int, importSummary) {
+	status, data := transfer(t, http.MethodPost, "api/import"+query, body)
+	var summary importSummary
+	require.NoError(t, json.Unmarshal(data, &summary), string(data))
+	return status, summary
+}
+
+func TestExportImport(t *testing.T) {
+	id := addTask(t, task{
+		date:  time.Now().Format(`20060102`),
+		title: "Попадет в выгрузку",
+	})
+
+	status, archive := transfer(t, http.MethodGet, "api/export", "")
+	require.Equal(t, http.StatusOK, status)
+	var parsed struct {
+		Format string              `json:"format"`
+		Tasks  []map[string]string `json:"tasks"`
+	}
+	require.NoError(t, json.Unmarshal(archive, &parsed))
+	assert.Equal(t, "tasktracker", parsed.Format)
+	var found bool
+	for _, tk := range parsed.Tasks {
+		found = found || tk["id"] == id
+	}
+	assert.True(t, found, "задача %s должна быть в выгрузке", id)
+
+	// Выгрузка совпадает с БД: повторная загрузка ничего не меняет
+	status, summary := importArchive(t, "", string(archive))
+	require.Equal(t, http.StatusOK, status)
+	assert.True(t, summary.Applied)
+	assert.Equal(t, len(parsed.Tasks), summary.Tasks["skipped"])
+	assert.Zero(t, summary.Tasks["created"]+summary.Tasks["updated"])
+
+	// Пробная замена всех данных показывает изменения, но не выполняет их
+	status, summary = importArchive(t, "?mode=replace&dry_run=1", `{"format":"tasktracker","version":1,"tasks":[]}`)
+	require.Equal(t, http.StatusOK, status)
+	assert.False(t, summary.Applied)
+	assert.Equal(t, len(parsed.Tasks), summary.Tasks["deleted"])
+	getTaskMap(t, id)
+
+	status, summary = importArchive(t, "?mode=append",
+		`{"format":"tasktracker","version":1,"tasks":[{"id":"`+id+`","date":"20240101","title":"Копия из файла","repeat":"d 5"}]}`)
+	require.Equal(t, http.StatusOK, status)
+	assert.Equal(t, 1, summary.Tasks["created"])
+	status, archive = transfer(t, http.MethodGet, "api/export", "")
+	require.Equal(t, http.StatusOK, status)
+	assert.Contains(t, string(archive), "Копия из файла")
+
+	status, summary = importArchive(t, "", `{"format":"tasktracker","version":1,"tasks":[{"date":"20240101","title":""}]}`)
+	assert.Equal(t, http.StatusUnprocessableEntity, status)
+	assert.False(t, summary.Applied)
+	require.Len(t, summary.Errors, 1)
+	assert.JSONEq(t, `"task.title.empty"`, string(summary.Errors[0]["code"]))
+}
@@ -0,0 +1,58 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTasksFilter(t *testing.T) {
+	now := time.Now()
+	marker := fmt.Sprintf("ф%d", now.UnixNano())
+	today := now.Format(`20060102`)
+	tomorrow := now.AddDate(0, 0, 1).Format(`20060102`)
+	later := now.AddDate(0, 0, 10).Format(`20060102`)
+
+	invoice := addTask(t, task{date: today, title: marker + " счёт invoice", comment: "через bank 100% https://bank.example.com/pay"})
+	weekly := addTask(t, task{date: tomorrow, title: marker + " планёрка", repeat: "w 1,3"})
+	daily := addTask(t, task{date: later, title: marker + " зарядка", comment: "invoice_draft", repeat: "d 2"})
+
+	search := func(q string) []string {
+		page := getTaskPage(t, url.Values{"search": {marker + " " + q}})
+		ids := []string{}
+		for _, task := range page.Tasks {
+			ids = append(ids, task["id"])
+		}
+		return ids
+	}
+
+	assert.Equal(t, []string{invoice, weekly, daily}, search(""))
+	assert.Equal(t, []string{invoice}, search("title:invoice"))
+	assert.Equal(t, []string{invoice, daily}, search("invoice"))
+	assert.Equal(t, []string{invoice}, search(`comment:"bank 100%"`))
+	assert.Equal(t, []string{}, search(`comment:"bank 1_0"`))
+	assert.Equal(t, []string{weekly, daily}, search("repeat:any"))
+	assert.Equal(t, []string{invoice}, search("repeat:none"))
+	assert.Equal(t, []string{weekly}, search("repeat:w"))
+	assert.Equal(t, []string{weekly, daily}, search("date>"+today))
+	assert.Equal(t, []string{invoice, weekly}, search("date>="+today+" date<"+later))
+	assert.Equal(t, []string{weekly}, search("date:"+now.AddDate(0, 0, 1).Format(`02.01.2006`)))
+	assert.Equal(t, []string{invoice}, search("is:today"))
+	assert.Equal(t, []string{weekly, daily}, search("-repeat:none"))
+	assert.Equal(t, []string{invoice}, search("https://bank.example.com"))
+	assert.Equal(t, []string{}, search("a:b"))
+
+	for _, bad := range []string{"is:work", "date>=2025", `comment:"bank`, "repeat:hourly", "is:late", "title>x"} {
+		body, err := requestJSON("api/tasks?"+url.Values{"search": {bad}}.Encode(), nil, http.MethodGet)
+		assert.NoError(t, err)
+		var m map[string]any
+		assert.NoError(t, json.Unmarshal(body, &m))
+		assert.NotEmpty(t, m["error"], "Ожидается ошибка для %q", bad)
+		assert.NotEmpty(t, m["token"], "Ожидается проблемный токен для %q", bad)
+	}
+}
@@ -0,0 +1,44 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImportFromTodoist(t *testing.T) {
+	due := time.Now().AddDate(0, 0, 30).Format("2006-01-02")
+	body := `{"items": [
+		{"id": "1", "content": "Из Todoist еженедельно", "due": {"date": "` + due + `", "string": "every 2 weeks", "is_recurring": true}},
+		{"id": "2", "content": "Из Todoist по пятницам третьим", "due": {"date": "` + due + `", "string": "every 3rd friday", "is_recurring": true}},
+		{"id": "3", "content": "Выполнена", "checked": true}
+	]}`
+
+	status, data := transfer(t, http.MethodPost, "api/import/todoist", body)
+	require.Equal(t, http.StatusOK, status, string(data))
+	var resp struct {
+		Applied  bool             `json:"applied"`
+		Tasks    map[string]int   `json:"tasks"`
+		Skipped  int              `json:"skipped"`
+		Warnings []map[string]any `json:"warnings"`
+	}
+	require.NoError(t, json.Unmarshal(data, &resp))
+	assert.True(t, resp.Applied)
+	assert.Equal(t, 2, resp.Tasks["created"])
+	assert.Equal(t, 1, resp.Skipped)
+	require.Len(t, resp.Warnings, 1)
+	assert.Equal(t, "2", resp.Warnings[0]["source_id"])
+	assert.Equal(t, "import.repeat.unmapped", resp.Warnings[0]["code"])
+
+	status, data = transfer(t, http.MethodGet, "api/tasks?search="+url.QueryEscape(`title:"Из Todoist"`), "")
+	require.Equal(t, http.StatusOK, status)
+	assert.Contains(t, string(data), `"repeat":"d 14"`)
+
+	status, _ = transfer(t, http.MethodPost, "api/import/asana", "{}")
+	assert.Equal(t, http.StatusBadRequest, status)
+}
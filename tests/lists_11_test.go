@@ -0,0 +1,89 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func listTaskIDs(t *testing.T, listID string) ([]string, map[string]any) {
+	body, err := requestJSON("api/lists/"+listID+"/tasks?limit=500", nil, http.MethodGet)
+	assert.NoError(t, err)
+
+	var m map[string]any
+	assert.NoError(t, json.Unmarshal(body, &m))
+
+	ids := []string{}
+	tasks, _ := m["tasks"].([]any)
+	for _, v := range tasks {
+		ids = append(ids, fmt.Sprint(v.(map[string]any)["id"]))
+	}
+	return ids, m
+}
+
+func TestLists(t *testing.T) {
+	now := time.Now()
+	marker := fmt.Sprintf("с%d", now.UnixNano())
+
+	today := addTask(t, task{date: now.Format(`20060102`), title: marker + " сегодня"})
+	week := addTask(t, task{date: now.AddDate(0, 0, 3).Format(`20060102`), title: marker + " на неделе", repeat: "d 5"})
+	later := addTask(t, task{date: now.AddDate(0, 0, 30).Format(`20060102`), title: marker + " потом"})
+
+	body, err := requestJSON("api/lists", nil, http.MethodGet)
+	assert.NoError(t, err)
+	var m struct {
+		Lists []map[string]any `json:"lists"`
+	}
+	assert.NoError(t, json.Unmarshal(body, &m))
+	builtin := map[string]bool{}
+	for _, l := range m.Lists {
+		if l["builtin"] == true {
+			builtin[fmt.Sprint(l["id"])] = true
+		}
+	}
+	assert.Equal(t, map[string]bool{"today": true, "overdue": true, "upcoming": true, "norepeat": true}, builtin)
+
+	ids, _ := listTaskIDs(t, "today")
+	assert.Contains(t, ids, today)
+	assert.NotContains(t, ids, week)
+
+	ids, _ = listTaskIDs(t, "upcoming")
+	assert.Contains(t, ids, today)
+	assert.Contains(t, ids, week)
+	assert.NotContains(t, ids, later)
+
+	ids, _ = listTaskIDs(t, "norepeat")
+	assert.Contains(t, ids, later)
+	assert.NotContains(t, ids, week)
+
+	ret, err := postJSON("api/lists", map[string]any{"name": "Неделя", "query": marker + " repeat:any"}, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Empty(t, ret["error"])
+	listID := fmt.Sprint(ret["id"])
+
+	ids, _ = listTaskIDs(t, listID)
+	assert.Equal(t, []string{week}, ids)
+
+	for _, bad := range []map[string]any{
+		{"name": "", "query": "repeat:any"},
+		{"name": "Пустой", "query": ""},
+		{"name": "Ошибка", "query": "is:work"},
+	} {
+		ret, err := postJSON("api/lists", bad, http.MethodPost)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, ret["error"], "Ожидается ошибка для %v", bad)
+	}
+
+	ret, err = postJSON("api/lists?id="+listID, nil, http.MethodDelete)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+
+	_, m2 := listTaskIDs(t, listID)
+	assert.NotEmpty(t, m2["error"])
+	_, m2 = listTaskIDs(t, "unknown")
+	assert.NotEmpty(t, m2["error"])
+}
@@ -0,0 +1,27 @@
+package tests
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetrics(t *testing.T) {
+	id := addTask(t, task{
+		date:  time.Now().Format(`20060102`),
+		title: "Метрики",
+	})
+	_, err := postJSON("api/task/done?id="+id, nil, http.MethodPost)
+	assert.NoError(t, err)
+
+	body, err := getBody("metrics")
+	assert.NoError(t, err)
+	metrics := string(body)
+
+	assert.Contains(t, metrics, `tasktracker_http_requests_total{method="POST",route="/api/task",status="200"}`)
+	assert.Contains(t, metrics, `tasktracker_db_query_duration_seconds_count{method="Create",result="ok"}`)
+	assert.Contains(t, metrics, `tasktracker_tasks_completed_total{kind="once"}`)
+	assert.Contains(t, metrics, `tasktracker_tasks{state="upcoming"}`)
+}
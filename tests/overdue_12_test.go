@@ -0,0 +1,113 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func getTaskMap(t *testing.T, id string) map[string]any {
+	body, err := requestJSON("api/task?id="+id, nil, http.MethodGet)
+	assert.NoError(t, err)
+	var m map[string]any
+	assert.NoError(t, json.Unmarshal(body, &m))
+	return m
+}
+
+func TestOverdue(t *testing.T) {
+	now := time.Now()
+	today := now.Format(`20060102`)
+	past := now.AddDate(0, 0, -3).Format(`20060102`)
+
+	ret, err := postJSON("api/task", map[string]any{
+		"date":           past,
+		"title":          "Сдать отчет",
+		"overdue_policy": "keep",
+	}, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Empty(t, ret["error"])
+	keepID := fmt.Sprint(ret["id"])
+
+	m := getTaskMap(t, keepID)
+	assert.Equal(t, past, m["date"])
+	assert.Equal(t, "keep", m["overdue_policy"])
+	assert.Equal(t, "true", m["overdue"])
+	assert.Equal(t, "3", m["days_overdue"])
+
+	// Список задач остается объектами из строк и с просроченной задачей
+	var listed map[string]string
+	for _, task := range getTasks(t, "") {
+		if task["id"] == keepID {
+			listed = task
+		}
+	}
+	assert.Equal(t, "true", listed["overdue"])
+	assert.Equal(t, "3", listed["days_overdue"])
+
+	body, err := requestJSON("api/tasks/overdue", nil, http.MethodGet)
+	assert.NoError(t, err)
+	var page struct {
+		Tasks []map[string]any `json:"tasks"`
+	}
+	assert.NoError(t, json.Unmarshal(body, &page))
+	var found bool
+	for _, task := range page.Tasks {
+		found = found || task["id"] == keepID
+	}
+	assert.True(t, found, "Просроченная задача должна быть в /api/tasks/overdue")
+
+	// Изменение без overdue_policy (как из веб-интерфейса) сохраняет политику и дату
+	ret, err = postJSON("api/task", map[string]any{
+		"id":    keepID,
+		"date":  past,
+		"title": "Сдать годовой отчет",
+	}, http.MethodPut)
+	assert.NoError(t, err)
+	assert.Empty(t, ret["error"])
+	m = getTaskMap(t, keepID)
+	assert.Equal(t, "Сдать годовой отчет", m["title"])
+	assert.Equal(t, past, m["date"])
+	assert.Equal(t, "keep", m["overdue_policy"])
+
+	ret, err = postJSON("api/task", map[string]any{
+		"date":           past,
+		"title":          "Полить цветы",
+		"repeat":         "d 7",
+		"overdue_policy": "today",
+	}, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Empty(t, ret["error"])
+	todayID := fmt.Sprint(ret["id"])
+	assert.Equal(t, today, getTaskMap(t, todayID)["date"])
+
+	ret, err = postJSON("api/task", map[string]any{
+		"date":           past,
+		"title":          "Вынести мусор",
+		"repeat":         "d 2",
+		"overdue_policy": "next",
+	}, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Empty(t, ret["error"])
+	nextID := fmt.Sprint(ret["id"])
+	m = getTaskMap(t, nextID)
+	assert.Equal(t, now.AddDate(0, 0, 1).Format(`20060102`), m["date"])
+	assert.Nil(t, m["overdue"])
+
+	for _, bad := range []map[string]any{
+		{"date": past, "title": "Без правила", "overdue_policy": "next"},
+		{"date": past, "title": "Неизвестная", "overdue_policy": "later"},
+	} {
+		ret, err := postJSON("api/task", bad, http.MethodPost)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, ret["error"], "Ожидается ошибка для %v", bad)
+	}
+
+	for _, id := range []string{keepID, todayID, nextID} {
+		_, err := postJSON("api/task?id="+id, nil, http.MethodDelete)
+		assert.NoError(t, err)
+	}
+}
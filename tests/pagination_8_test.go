@@ -0,0 +1,67 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type taskPage struct {
+	Tasks      []map[string]string `json:"tasks"`
+	NextCursor string              `json:"next_cursor"`
+}
+
+func getTaskPage(t *testing.T, params url.Values) taskPage {
+	body, err := requestJSON("api/tasks?"+params.Encode(), nil, http.MethodGet)
+	assert.NoError(t, err)
+
+	var page taskPage
+	err = json.Unmarshal(body, &page)
+	assert.NoError(t, err)
+	return page
+}
+
+func TestTasksPagination(t *testing.T) {
+	now := time.Now()
+	title := fmt.Sprintf("Пагинация %d", now.UnixNano())
+
+	var ids []string
+	for i := 0; i < 5; i++ {
+		ids = append(ids, addTask(t, task{
+			date:  now.AddDate(0, 0, i/2).Format(`20060102`),
+			title: title,
+		}))
+	}
+
+	var got []string
+	params := url.Values{"search": {title}, "limit": {"2"}}
+	for pages := 0; pages < 10; pages++ {
+		page := getTaskPage(t, params)
+		assert.LessOrEqual(t, len(page.Tasks), 2)
+		for _, task := range page.Tasks {
+			got = append(got, task["id"])
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		params.Set("cursor", page.NextCursor)
+	}
+	assert.Equal(t, ids, got)
+
+	for _, bad := range []url.Values{
+		{"limit": {"abc"}},
+		{"limit": {"-1"}},
+		{"cursor": {"не-курсор"}},
+	} {
+		body, err := requestJSON("api/tasks?"+bad.Encode(), nil, http.MethodGet)
+		assert.NoError(t, err)
+		var m map[string]any
+		assert.NoError(t, json.Unmarshal(body, &m))
+		assert.NotEmpty(t, m["error"], "Ожидается ошибка для %v", bad)
+	}
+}
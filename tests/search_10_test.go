@@ -0,0 +1,87 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type searchResult struct {
+	ID               string  `json:"id"`
+	Title            string  `json:"title"`
+	TitleHighlight   string  `json:"title_highlight"`
+	CommentHighlight string  `json:"comment_highlight"`
+	Rank             float64 `json:"rank"`
+}
+
+func search(t *testing.T, q string) ([]searchResult, string) {
+	body, err := requestJSON("api/search?"+url.Values{"q": {q}}.Encode(), nil, http.MethodGet)
+	assert.NoError(t, err)
+
+	var m struct {
+		Results []searchResult `json:"results"`
+		Error   string         `json:"error"`
+	}
+	assert.NoError(t, json.Unmarshal(body, &m))
+	return m.Results, m.Error
+}
+
+func TestSearch(t *testing.T) {
+	now := time.Now()
+	marker := fmt.Sprintf("п%d", now.UnixNano())
+
+	inTitle := addTask(t, task{
+		date:  now.AddDate(0, 0, 2).Format(`20060102`),
+		title: marker + " Счёт за Электричество",
+	})
+	inComment := addTask(t, task{
+		date:    now.Format(`20060102`),
+		title:   marker + " Оплата",
+		comment: "квитанция за электричество и воду",
+	})
+
+	ids := func(results []searchResult) []string {
+		ret := []string{}
+		for _, r := range results {
+			ret = append(ret, r.ID)
+		}
+		return ret
+	}
+
+	results, errMsg := search(t, marker+" электричество")
+	assert.Empty(t, errMsg)
+	assert.ElementsMatch(t, []string{inTitle, inComment}, ids(results))
+	for _, r := range results {
+		if r.ID == inTitle {
+			assert.Contains(t, r.TitleHighlight, "<mark>Электричество</mark>")
+		} else {
+			assert.Contains(t, r.CommentHighlight, "<mark>электричество</mark>")
+		}
+		if r.Rank != 0 {
+			// FTS5 доступен: совпадение в заголовке релевантнее
+			assert.Equal(t, inTitle, results[0].ID)
+		}
+	}
+
+	results, _ = search(t, marker+" электр*")
+	assert.ElementsMatch(t, []string{inTitle, inComment}, ids(results))
+
+	results, _ = search(t, marker+` "за электричество"`)
+	assert.ElementsMatch(t, []string{inTitle, inComment}, ids(results))
+
+	results, _ = search(t, marker+` "электричество за"`)
+	assert.Empty(t, results)
+
+	page := getTaskPage(t, url.Values{"search": {marker + " ЭЛЕКТРИЧЕСТВО"}})
+	assert.Len(t, page.Tasks, 2)
+
+	for _, bad := range []string{"", `"незакрытая`, "%%"} {
+		_, errMsg := search(t, bad)
+		assert.NotEmpty(t, errMsg, "Ожидается ошибка для %q", bad)
+	}
+}
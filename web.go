@@ -0,0 +1,10 @@
+// Package tasktracker содержит ресурсы, встраиваемые в исполняемый файл.
+package tasktracker
+
+import "embed"
+
+// Web — файлы фронтенда из каталога web. Встраиваются при сборке, поэтому
+// бинарный файл не зависит от рабочего каталога, из которого его запустили.
+//
+//go:embed web
+var Web embed.FS